@@ -1,11 +1,15 @@
 package command
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
 )
 
-func NewCmdBanner(api sdapi.SDAPI) *cobra.Command {
+func NewCmdBanner(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "banner",
 		Short:   "handle screwdriver banners",
@@ -15,8 +19,24 @@ func NewCmdBanner(api sdapi.SDAPI) *cobra.Command {
 		},
 	}
 
-	cmd.AddCommand(NewCmdBannerGet(api))
+	cmd.AddCommand(NewCmdBannerGet(config, api))
+	cmd.AddCommand(NewCmdBannerCreate(config, api))
 	cmd.AddCommand(NewCmdBannerUpdate(api))
+	cmd.AddCommand(NewCmdBannerDelete(config, api))
+	cmd.AddCommand(NewCmdBannerActivate(api, true))
+	cmd.AddCommand(NewCmdBannerActivate(api, false))
+	cmd.AddCommand(NewCmdBannerGC(config, api))
+	cmd.AddCommand(NewCmdBannerTemplate(config))
+	cmd.AddCommand(NewCmdBannerAnnounce(config, api))
+	cmd.AddCommand(NewCmdBannerSync(config, api))
 
 	return cmd
 }
+
+func parseBannerID(id string) (int, error) {
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert %s to int: %v", id, err)
+	}
+	return i, nil
+}
@@ -0,0 +1,53 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type BannerActivateOption struct {
+	API      sdapi.Interface
+	IsActive bool
+}
+
+// NewCmdBannerActivate creates "activate" or "deactivate" command by isActive
+func NewCmdBannerActivate(api sdapi.Interface, isActive bool) *cobra.Command {
+	o := &BannerActivateOption{
+		API:      api,
+		IsActive: isActive,
+	}
+
+	use, short := "activate <id...>", "activate banners"
+	if !isActive {
+		use, short = "deactivate <id...>", "deactivate banners"
+	}
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *BannerActivateOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+	for _, a := range args {
+		id, err := parseBannerID(a)
+		if err != nil {
+			return err
+		}
+		banner, err := o.API.UpdateBanner(cmd.Context(), id, sdapi.BannerRequest{IsActive: &o.IsActive})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "banner ID %v is active: %v\n", banner.ID, banner.IsActive)
+	}
+	return nil
+}
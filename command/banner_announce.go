@@ -0,0 +1,164 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type BannerAnnounceOption struct {
+	Config   sdctl_context.SdctlConfig
+	API      sdapi.Interface
+	Template string
+	Start    string
+	End      string
+	Type     string
+	Vars     []string
+	// ScheduleFile is the shared file of windows, otherwise they are kept in the config of the announcer
+	ScheduleFile string
+}
+
+type bannerTemplateData struct {
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	Vars     map[string]string
+}
+
+func NewCmdBannerAnnounce(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &BannerAnnounceOption{
+		Config: config,
+		API:    api,
+	}
+	cmd := &cobra.Command{
+		Use:   "announce",
+		Short: "create an inactive banner from a template, activated in the window by 'banner sync'",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Template, "template", "", "", "name of a banner template")
+	_ = cmd.MarkFlagRequired("template")
+	cmd.Flags().StringVarP(&o.Start, "start", "", "", "start of the window (RFC3339 or \"2006-01-02 15:04\" in local time)")
+	_ = cmd.MarkFlagRequired("start")
+	cmd.Flags().StringVarP(&o.End, "end", "", "", "end of the window (RFC3339 or \"2006-01-02 15:04\" in local time)")
+	_ = cmd.MarkFlagRequired("end")
+	cmd.Flags().StringVarP(&o.Type, "type", "t", "warn", "banner type (info, warn)")
+	cmd.Flags().StringArrayVarP(&o.Vars, "var", "", nil, "value for the template as KEY=VALUE")
+	cmd.Flags().StringVarP(&o.ScheduleFile, "schedule-file", "", "", "record the window in this file for 'banner sync --schedule-file' run elsewhere, instead of the local config")
+
+	return cmd
+}
+
+func (o *BannerAnnounceOption) Run(cmd *cobra.Command, args []string) error {
+	text, ok := o.Config.BannerTemplates[o.Template]
+	if !ok {
+		return fmt.Errorf("banner template %s is not found", o.Template)
+	}
+	start, err := util.ParseTime(o.Start)
+	if err != nil {
+		return err
+	}
+	end, err := util.ParseTime(o.End)
+	if err != nil {
+		return err
+	}
+	if !end.After(start) {
+		return errors.New("--end should be after --start")
+	}
+
+	data := bannerTemplateData{
+		Start:    start,
+		End:      end,
+		Duration: end.Sub(start),
+		Vars:     make(map[string]string),
+	}
+	for _, v := range o.Vars {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("--var should be KEY=VALUE: %s", v)
+		}
+		data.Vars[kv[0]] = kv[1]
+	}
+	message, err := renderBannerTemplate(text, data)
+	if err != nil {
+		return err
+	}
+
+	apiURL := o.Config.SdctlContexts[o.Config.CurrentContext].APIURL
+	if o.ScheduleFile != "" {
+		// fail before creating the banner when the file is for another API
+		f, err := sdctl_context.ReadBannerSchedules(o.ScheduleFile)
+		if err != nil {
+			return err
+		}
+		if err := f.CheckAPI(apiURL); err != nil {
+			return err
+		}
+	}
+
+	isActive := false
+	banner, err := o.API.CreateBanner(cmd.Context(), sdapi.BannerRequest{Message: message, Type: o.Type, IsActive: &isActive})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Successfully created an inactive banner ID %v: %v\n", banner.ID, banner.Message)
+
+	schedule := sdctl_context.BannerSchedule{Start: start, End: end}
+	window := fmt.Sprintf("from %v to %v", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	if o.ScheduleFile != "" {
+		err := sdctl_context.UpdateBannerSchedules(o.ScheduleFile, func(f *sdctl_context.BannerScheduleFile) error {
+			if err := f.CheckAPI(apiURL); err != nil {
+				return err
+			}
+			f.API = apiURL
+			if f.Schedules == nil {
+				f.Schedules = make(map[int]sdctl_context.BannerSchedule)
+			}
+			f.Schedules[banner.ID] = schedule
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "run 'sdctl banner sync --schedule-file %s' periodically where the file is, such as in a periodic Screwdriver.cd job, to activate it %s\n", o.ScheduleFile, window)
+		return nil
+	}
+
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	o.Config.SetBannerSchedule(banner.ID, schedule)
+	if err := o.Config.Update(configPATH); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "run 'sdctl banner sync' periodically on this machine to activate it %s\n", window)
+	return nil
+}
+
+func parseBannerTemplate(text string) (*template.Template, error) {
+	return template.New("banner").Option("missingkey=error").Parse(text)
+}
+
+func renderBannerTemplate(text string, data bannerTemplateData) (string, error) {
+	t, err := parseBannerTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
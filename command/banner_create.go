@@ -0,0 +1,90 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type BannerCreateOption struct {
+	Config    sdctl_context.SdctlConfig
+	API       sdapi.Interface
+	Message   string
+	Type      string
+	Inactive  bool
+	Scope     string
+	ScopeID   int
+	ExpiresIn time.Duration
+}
+
+func NewCmdBannerCreate(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &BannerCreateOption{
+		Config: config,
+		API:    api,
+	}
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "create a banner",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Message, "msg", "m", "", "banner message body")
+	_ = cmd.MarkFlagRequired("msg")
+	cmd.Flags().StringVarP(&o.Type, "type", "t", "info", "banner type (info, warn)")
+	cmd.Flags().BoolVarP(&o.Inactive, "inactive", "", false, "create the banner as inactive")
+	cmd.Flags().StringVarP(&o.Scope, "scope", "s", sdapi.BannerScopeGlobal, "banner scope (GLOBAL, PIPELINE, BUILD)")
+	cmd.Flags().IntVarP(&o.ScopeID, "scope-id", "", 0, "ID of the pipeline or the build for a scoped banner")
+	cmd.Flags().DurationVarP(&o.ExpiresIn, "expires-in", "e", 0, "expire the banner after the duration such as 2h. expired banners are deleted by 'banner gc'")
+
+	return cmd
+}
+
+func (o *BannerCreateOption) Run(cmd *cobra.Command, args []string) error {
+	scope := strings.ToUpper(o.Scope)
+	if scope != sdapi.BannerScopeGlobal && o.ScopeID == 0 {
+		return fmt.Errorf("--scope-id is required for %s scope", scope)
+	}
+	if o.ExpiresIn < 0 {
+		return errors.New("--expires-in should be positive")
+	}
+
+	isActive := !o.Inactive
+	req := sdapi.BannerRequest{
+		Message:  o.Message,
+		Type:     o.Type,
+		IsActive: &isActive,
+	}
+	if scope != sdapi.BannerScopeGlobal {
+		req.Scope = scope
+		req.ScopeID = o.ScopeID
+	}
+
+	banner, err := o.API.CreateBanner(cmd.Context(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Successfully created a banner ID %v\n", banner.ID)
+
+	if o.ExpiresIn > 0 {
+		configPATH, err := util.ConfigPATH()
+		if err != nil {
+			return err
+		}
+		expiresAt := time.Now().Add(o.ExpiresIn)
+		o.Config.SetBannerExpiration(banner.ID, expiresAt)
+		if err := o.Config.Update(configPATH); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "banner ID %v expires at %v\n", banner.ID, expiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type BannerDeleteOption struct {
+	Config sdctl_context.SdctlConfig
+	API    sdapi.Interface
+}
+
+func NewCmdBannerDelete(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &BannerDeleteOption{
+		Config: config,
+		API:    api,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "delete <id...>",
+		Short:   "delete banners",
+		Aliases: []string{"rm"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *BannerDeleteOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+	ids := make([]int, len(args))
+	for i, a := range args {
+		id, err := parseBannerID(a)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	// the config is written only when expirations of deleted banners are forgotten, even if a later deletion fails
+	changed := false
+	var err error
+	for _, id := range ids {
+		if err = o.API.DeleteBanner(cmd.Context(), id); err != nil {
+			break
+		}
+		fmt.Fprintf(os.Stdout, "Successfully deleted a banner ID %v\n", id)
+		if o.Config.RemoveBannerExpiration(id) {
+			changed = true
+		}
+	}
+	if !changed {
+		return err
+	}
+	configPATH, perr := util.ConfigPATH()
+	if perr != nil {
+		return perr
+	}
+	if uerr := o.Config.Update(configPATH); uerr != nil {
+		return uerr
+	}
+	return err
+}
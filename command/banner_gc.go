@@ -0,0 +1,68 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type BannerGCOption struct {
+	Config sdctl_context.SdctlConfig
+	API    sdapi.Interface
+	DryRun bool
+}
+
+func NewCmdBannerGC(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &BannerGCOption{
+		Config: config,
+		API:    api,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "delete banners created with --expires-in that have expired",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().BoolVarP(&o.DryRun, "dry-run", "", false, "only show expired banners")
+
+	return cmd
+}
+
+func (o *BannerGCOption) Run(cmd *cobra.Command, args []string) error {
+	expired := o.Config.ExpiredBanners(time.Now())
+	if len(expired) == 0 {
+		fmt.Fprintln(os.Stdout, "no expired banners")
+		return nil
+	}
+	if o.DryRun {
+		for _, id := range expired {
+			fmt.Fprintf(os.Stdout, "banner ID %v has expired\n", id)
+		}
+		return nil
+	}
+
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	for _, id := range expired {
+		// banners deleted by someone else are just forgotten
+		if err := o.API.DeleteBanner(cmd.Context(), id); err != nil && !errors.Is(err, sdapi.ErrNotFound) {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Successfully deleted an expired banner ID %v\n", id)
+		o.Config.RemoveBannerExpiration(id)
+		if err := o.Config.Update(configPATH); err != nil {
+			return err
+		}
+	}
+	return nil
+}
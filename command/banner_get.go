@@ -3,18 +3,24 @@ package command
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
 )
 
 type BannerGetOption struct {
-	API sdapi.SDAPI
+	Config sdctl_context.SdctlConfig
+	API    sdapi.Interface
+	List   listFlags
+	Output outputFlag
 }
 
-func NewCmdBannerGet(api sdapi.SDAPI) *cobra.Command {
+func NewCmdBannerGet(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
 	o := &BannerGetOption{
-		API: api,
+		Config: config,
+		API:    api,
 	}
 	cmd := &cobra.Command{
 		Use:   "get",
@@ -23,6 +29,9 @@ func NewCmdBannerGet(api sdapi.SDAPI) *cobra.Command {
 			return o.Run(cmd, args)
 		},
 	}
+	addListFlags(cmd, &o.List)
+	addOutputFlag(cmd, &o.Output, config)
+
 	return cmd
 }
 
@@ -31,22 +40,42 @@ func (o *BannerGetOption) Run(cmd *cobra.Command, args []string) error {
 		return cmd.Help()
 	}
 
-	banners, err := o.API.GetBanners()
+	opts, err := o.List.options()
+	if err != nil {
+		return err
+	}
+	if err := o.Output.validate(); err != nil {
+		return err
+	}
+	banners, err := o.API.ListBanners(cmd.Context(), opts)
 	if err != nil {
 		return err
 	}
+	if o.Output.json() {
+		return printJSON(banners)
+	}
 	o.print(banners)
 
 	return nil
 }
 
 func (o *BannerGetOption) print(banners []sdapi.BannerResponse) {
-	o.printColumn("ID", "IsActive", "Message")
+	expirations := o.Config.SdctlContexts[o.Config.CurrentContext].BannerExpirations
+
+	o.printColumn("ID", "IsActive", "Scope", "Expires", "Message")
 	for _, b := range banners {
-		o.printColumn(b.ID, b.IsActive, b.Message)
+		scope := b.Scope
+		if b.ScopeID != 0 {
+			scope = fmt.Sprintf("%s:%d", b.Scope, b.ScopeID)
+		}
+		expires := "-"
+		if e, ok := expirations[b.ID]; ok {
+			expires = e.Local().Format(time.RFC3339)
+		}
+		o.printColumn(b.ID, b.IsActive, scope, expires, b.Message)
 	}
 }
 
-func (o *BannerGetOption) printColumn(id, isActive, msg interface{}) {
-	fmt.Fprintf(os.Stdout, "%-8v%-12v%v\n", id, isActive, msg)
+func (o *BannerGetOption) printColumn(id, isActive, scope, expires, msg interface{}) {
+	fmt.Fprintf(os.Stdout, "%-8v%-12v%-20v%-28v%v\n", id, isActive, scope, expires, msg)
 }
@@ -0,0 +1,159 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type BannerSyncOption struct {
+	Config       sdctl_context.SdctlConfig
+	API          sdapi.Interface
+	DryRun       bool
+	ScheduleFile string
+}
+
+func NewCmdBannerSync(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &BannerSyncOption{
+		Config: config,
+		API:    api,
+	}
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "activate or deactivate announced banners by their windows",
+		Long: `activate or deactivate banners created by 'banner announce' by their windows. this is meant to run periodically.
+windows are read from the local config, or from --schedule-file written by 'banner announce --schedule-file',
+e.g. committed to a repository whose periodic Screwdriver.cd job runs sync.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().BoolVarP(&o.DryRun, "dry-run", "", false, "only show changes")
+	cmd.Flags().StringVarP(&o.ScheduleFile, "schedule-file", "", "", "file of windows written by 'banner announce --schedule-file', instead of the local config")
+
+	return cmd
+}
+
+func (o *BannerSyncOption) Run(cmd *cobra.Command, args []string) error {
+	if o.ScheduleFile != "" {
+		return o.runScheduleFile(cmd)
+	}
+
+	done, err := o.sync(cmd, o.Config.SdctlContexts[o.Config.CurrentContext].BannerSchedules)
+	if len(done) == 0 || o.DryRun {
+		return err
+	}
+	for _, id := range done {
+		o.Config.RemoveBannerSchedule(id)
+	}
+	configPATH, perr := util.ConfigPATH()
+	if perr != nil {
+		return combineSyncErrors(err, perr, "the config")
+	}
+	return combineSyncErrors(err, o.Config.Update(configPATH), configPATH)
+}
+
+// runScheduleFile syncs banners by the windows in the schedule file, and forgets the windows which are done
+func (o *BannerSyncOption) runScheduleFile(cmd *cobra.Command) error {
+	apiURL := o.Config.SdctlContexts[o.Config.CurrentContext].APIURL
+	if o.DryRun {
+		f, err := sdctl_context.ReadBannerSchedules(o.ScheduleFile)
+		if err != nil {
+			return err
+		}
+		if err := f.CheckAPI(apiURL); err != nil {
+			return err
+		}
+		_, err = o.sync(cmd, f.Schedules)
+		return err
+	}
+	// windows done before a failed update are forgotten too, so the error of sync is returned after writing the file
+	var syncErr error
+	err := sdctl_context.UpdateBannerSchedules(o.ScheduleFile, func(f *sdctl_context.BannerScheduleFile) error {
+		if err := f.CheckAPI(apiURL); err != nil {
+			return err
+		}
+		var done []int
+		done, syncErr = o.sync(cmd, f.Schedules)
+		for _, id := range done {
+			delete(f.Schedules, id)
+		}
+		return nil
+	})
+	return combineSyncErrors(syncErr, err, o.ScheduleFile)
+}
+
+// combineSyncErrors returns the error of sync together with the error of saving banners which are done to path
+func combineSyncErrors(syncErr, saveErr error, path string) error {
+	switch {
+	case saveErr == nil:
+		return syncErr
+	case syncErr == nil:
+		return saveErr
+	}
+	return fmt.Errorf("%v, and failed to save done banners to %s: %v", syncErr, path, saveErr)
+}
+
+// sync activates or deactivates banners by schedules, and returns IDs of banners whose windows are done.
+// a failed update does not stop the others, so that every done window is returned with the error.
+func (o *BannerSyncOption) sync(cmd *cobra.Command, schedules map[int]sdctl_context.BannerSchedule) ([]int, error) {
+	if len(schedules) == 0 {
+		fmt.Fprintln(os.Stdout, "no announced banners")
+		return nil, nil
+	}
+
+	banners, err := o.API.GetBanners(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[int]sdapi.BannerResponse)
+	for _, b := range banners {
+		current[b.ID] = b
+	}
+
+	var ids []int
+	for id := range schedules {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	now := time.Now()
+	var done []int
+	var failures []string
+	for _, id := range ids {
+		schedule := schedules[id]
+		b, ok := current[id]
+		if !ok {
+			fmt.Fprintf(os.Stdout, "banner ID %v no longer exists\n", id)
+			done = append(done, id)
+			continue
+		}
+
+		active := schedule.ActiveAt(now)
+		if b.IsActive != active {
+			fmt.Fprintf(os.Stdout, "banner ID %v is active: %v -> %v\n", id, b.IsActive, active)
+			if !o.DryRun {
+				if _, err := o.API.UpdateBanner(cmd.Context(), id, sdapi.BannerRequest{IsActive: &active}); err != nil {
+					// the banner is synced again next time
+					failures = append(failures, fmt.Sprintf("banner ID %v: %v", id, err))
+					continue
+				}
+			}
+		}
+		// the window has passed, so nothing is left to sync
+		if !now.Before(schedule.End) {
+			done = append(done, id)
+		}
+	}
+	if len(failures) != 0 {
+		return done, fmt.Errorf("failed to update %d banners: %s", len(failures), strings.Join(failures, "; "))
+	}
+	return done, nil
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type BannerTemplateOption struct {
+	Config sdctl_context.SdctlConfig
+}
+
+func NewCmdBannerTemplate(config sdctl_context.SdctlConfig) *cobra.Command {
+	o := &BannerTemplateOption{
+		Config: config,
+	}
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "handle message templates for 'banner announce'",
+		Long: `handle message templates for 'banner announce'.
+templates are Go text/template. .Start and .End are time.Time, .Duration is time.Duration and .Vars has values of --var. e.g.
+  Screwdriver will be down for maintenance from {{.Start.Format "2006-01-02 15:04 MST"}} to {{.End.Format "15:04 MST"}}`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "show banner templates",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return o.List(cmd, args)
+			},
+		},
+		&cobra.Command{
+			Use:   "set <name> <template>",
+			Short: "store a banner template",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return o.Set(cmd, args)
+			},
+		},
+		&cobra.Command{
+			Use:   "delete <name>",
+			Short: "delete a banner template",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return o.Delete(cmd, args)
+			},
+		})
+	return cmd
+}
+
+func (o *BannerTemplateOption) List(cmd *cobra.Command, args []string) error {
+	var names []string
+	for name := range o.Config.BannerTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stdout, "%-20v%v\n", name, o.Config.BannerTemplates[name])
+	}
+	return nil
+}
+
+func (o *BannerTemplateOption) Set(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+	// check syntax before storing
+	if _, err := parseBannerTemplate(args[1]); err != nil {
+		return err
+	}
+	return o.update(args[0], args[1])
+}
+
+func (o *BannerTemplateOption) Delete(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+	if _, ok := o.Config.BannerTemplates[args[0]]; !ok {
+		return fmt.Errorf("banner template %s is not found", args[0])
+	}
+	return o.update(args[0], "")
+}
+
+func (o *BannerTemplateOption) update(name, text string) error {
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	o.Config.SetBannerTemplate(name, text)
+	if err := o.Config.Update(configPATH); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "banner template '%v' is updated\n", name)
+	return nil
+}
@@ -1,54 +1,51 @@
 package command
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
 )
 
 type BannerUpdateOption struct {
-	API sdapi.SDAPI
+	API     sdapi.Interface
+	Message string
+	Type    string
 }
 
-var (
-	id         string
-	msg        string
-	bannerType string
-	isActive   string
-	delete     bool
-)
-
-func NewCmdBannerUpdate(api sdapi.SDAPI) *cobra.Command {
+func NewCmdBannerUpdate(api sdapi.Interface) *cobra.Command {
 	o := &BannerUpdateOption{
 		API: api,
 	}
 
 	cmd := &cobra.Command{
-		Use:   "set",
-		Short: "update a banner",
-		Long:  "update a banner with POST, PUT, DELETE method. specify banner ID when using PUT or DELETE method",
+		Use:   "update <id>",
+		Short: "update message or type of a banner",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.Run(cmd, args)
 		},
 	}
 
-	cmd.Flags().StringVarP(&id, "id", "i", "", "specify banner ID when update or delete")
-	cmd.Flags().StringVarP(&msg, "msg", "m", "", "banner message body")
-	cmd.Flags().StringVarP(&bannerType, "type", "t", "info", "banner type (info, warn)")
-	cmd.Flags().StringVarP(&isActive, "active", "a", "true", "banner status flag (true, false)")
-	cmd.Flags().BoolVarP(&delete, "delete", "d", false, "flag for delete banner (required with id)")
+	cmd.Flags().StringVarP(&o.Message, "msg", "m", "", "banner message body")
+	cmd.Flags().StringVarP(&o.Type, "type", "t", "", "banner type (info, warn)")
 
 	return cmd
 }
 
 func (o *BannerUpdateOption) Run(cmd *cobra.Command, args []string) error {
-	if msg == "" && id == "" {
+	if len(args) != 1 || (o.Message == "" && o.Type == "") {
 		return cmd.Help()
 	}
-
-	_, err := o.API.UpdateBanner(id, msg, bannerType, isActive, delete, false)
+	id, err := parseBannerID(args[0])
 	if err != nil {
 		return err
 	}
 
+	banner, err := o.API.UpdateBanner(cmd.Context(), id, sdapi.BannerRequest{Message: o.Message, Type: o.Type})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Successfully updated a banner ID %v\n", banner.ID)
 	return nil
 }
@@ -1,21 +1,28 @@
 package command
 
 import (
+	"errors"
+
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
 )
 
 type BuildOption struct {
-	API sdapi.SDAPI
+	API      sdapi.Interface
+	Defaults sdctl_context.DefaultsConfig
 }
 
-func NewCmdBuild(api sdapi.SDAPI) *cobra.Command {
+func NewCmdBuild(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
 	o := &BuildOption{
-		API: api,
+		API:      api,
+		Defaults: currentDefaults(config),
 	}
 	cmd := &cobra.Command{
-		Use:     "build <pipelieid> <start_from>",
-		Short:   "start a job.",
+		Use:   "build [<pipelieid> [<start_from>]]",
+		Short: "start a job.",
+		Long: `start a job.
+omitted pipeline and start_from default to the defaults of the context, set by 'sdctl set defaults'`,
 		Aliases: []string{"b"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.Run(cmd, args)
@@ -25,12 +32,28 @@ func NewCmdBuild(api sdapi.SDAPI) *cobra.Command {
 }
 
 func (o *BuildOption) Run(cmd *cobra.Command, args []string) error {
-	if len(args) != 2 {
+	if len(args) > 2 {
 		return cmd.Help()
 	}
-	pipelineID := args[0]
-	startFrom := args[1]
-	if err := o.API.PostEvent(pipelineID, startFrom, false); err != nil {
+	pipeline, startFrom := o.Defaults.Pipeline, o.Defaults.StartFrom
+	if len(args) > 0 {
+		pipeline = args[0]
+	}
+	if len(args) > 1 {
+		startFrom = args[1]
+	}
+	if pipeline == "" || startFrom == "" {
+		if len(args) == 0 && pipeline == "" && startFrom == "" {
+			return cmd.Help()
+		}
+		return errors.New("pipeline and start_from are required without their defaults, set them by 'sdctl set defaults'")
+	}
+
+	pipelineID, err := o.API.ResolvePipelineID(cmd.Context(), pipeline)
+	if err != nil {
+		return err
+	}
+	if _, err := o.API.PostEvent(cmd.Context(), pipelineID, startFrom); err != nil {
 		return err
 	}
 	return nil
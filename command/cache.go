@@ -0,0 +1,20 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdCache() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "handle cached API responses and completion candidates",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		NewCmdCacheClear(),
+	)
+	return cmd
+}
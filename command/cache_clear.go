@@ -0,0 +1,42 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/completion"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type CacheClearOption struct{}
+
+func NewCmdCacheClear() *cobra.Command {
+	o := &CacheClearOption{}
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "remove cached API responses of all contexts and completion candidates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *CacheClearOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmd.Help()
+	}
+	if err := sdapi.ClearCache(); err != nil {
+		return err
+	}
+	dir, err := completion.DefaultDir()
+	if err != nil {
+		return err
+	}
+	if err := completion.NewCache(dir).Clear(); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, "cache is cleared")
+	return nil
+}
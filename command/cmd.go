@@ -1,7 +1,11 @@
 package command
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
@@ -15,7 +19,11 @@ var (
 	builtBy = "unknown"
 )
 
-func NewCmd(config sdctl_context.SdctlConfig, api sdapi.SDAPI) *cobra.Command {
+func NewCmd(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	var timeout time.Duration
+	var record, replay, traceFile string
+	var verbose, debug, noCache bool
+	var trace *os.File
 	cmd := &cobra.Command{
 		Use:     "sdctl",
 		Short:   "Screwdriver.cd API wrapper",
@@ -24,19 +32,178 @@ func NewCmd(config sdctl_context.SdctlConfig, api sdapi.SDAPI) *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			cmd.Help()
 		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// fakes of the API have no timeout
+			if t, ok := api.(interface{ SetTimeout(time.Duration) }); ok {
+				t.SetTimeout(timeout)
+			}
+			if err := wrapTransport(api, record, replay); err != nil {
+				return err
+			}
+			var err error
+			if trace, err = traceTransport(api, verbose, debug, traceFile); err != nil {
+				return err
+			}
+			// recorded and replayed requests are never served from the cache
+			if noCache || record != "" || replay != "" {
+				return nil
+			}
+			return cacheTransport(api, config)
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			if trace == nil {
+				return nil
+			}
+			if err := trace.Sync(); err != nil {
+				trace.Close()
+				return fmt.Errorf("failed to write the trace file: %w", err)
+			}
+			return trace.Close()
+		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
+	cmd.PersistentFlags().DurationVarP(&timeout, "timeout", "", sdapi.DefaultTimeout, "timeout of each API request. 0 means no timeout")
+	cmd.PersistentFlags().StringVarP(&record, "record", "", "", "save API requests and responses into a directory with credentials redacted, for debugging")
+	cmd.PersistentFlags().StringVarP(&replay, "replay", "", "", "respond to API requests with responses saved by --record in a directory instead of calling the API")
+	// -v is left to --version and 'secret set --value' as before
+	cmd.PersistentFlags().BoolVarP(&verbose, "verbose", "V", false, "log method, URL, status and latency of API requests to stderr")
+	cmd.PersistentFlags().BoolVarP(&debug, "debug", "", false, "log API requests to stderr with their bodies")
+	cmd.PersistentFlags().StringVarP(&traceFile, "trace-file", "", "", "append API requests to a file as JSON lines, with their bodies on --debug")
+	cmd.PersistentFlags().BoolVarP(&noCache, "no-cache", "", false, "do not use or update cached responses of pipelines, jobs and finished builds")
 
 	cmd.AddCommand(
-		NewCmdBanner(api),
-		NewCmdBuild(api),
+		NewCmdBanner(config, api),
+		NewCmdBuild(config, api),
+		NewCmdCache(),
 		NewCmdClear(config),
+		NewCmdCollection(config, api),
+		NewCmdCompletion(),
+		NewCmdConfig(),
 		NewCmdContext(config, api),
+		NewCmdDev(),
 		NewCmdGet(config, api),
+		NewCmdLint(),
+		NewCmdOpen(config, api),
+		NewCmdRun(config, api),
 		NewCmdSet(config, api),
+		NewCmdToken(config, api),
+		NewCmdTop(api),
 		NewCmdValidate(api),
 		NewCmdValidateTemplate(api),
-		NewCmdSecret(api))
+		NewCmdSecret(config, api))
+	registerCompletions(cmd, config, api)
 	return cmd
 }
+
+// ExpandAlias expands the alias in args[0] with aliases in config, leaving commands of root as they are
+func ExpandAlias(root *cobra.Command, config sdctl_context.SdctlConfig, args []string) ([]string, error) {
+	isCmd := func(name string) bool {
+		return isCommand(root, name)
+	}
+	// shell completion completes arguments of aliases
+	if len(args) > 0 && (args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd) {
+		expanded, err := config.ExpandAlias(args[1:], isCmd)
+		if err != nil {
+			return nil, err
+		}
+		return append([]string{args[0]}, expanded...), nil
+	}
+	return config.ExpandAlias(args, isCmd)
+}
+
+// isCommand returns whether name is a subcommand of root or its alias
+func isCommand(root *cobra.Command, name string) bool {
+	// help and completion are added on execution
+	if name == "help" || name == "completion" {
+		return true
+	}
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// transportWrapper is an API whose transport can be wrapped, which fakes of the API are not
+type transportWrapper interface {
+	WrapTransport(func(http.RoundTripper) http.RoundTripper)
+}
+
+// wrapTransport records or replays API requests through the transport of api
+func wrapTransport(api sdapi.Interface, record, replay string) error {
+	if record == "" && replay == "" {
+		return nil
+	}
+	if record != "" && replay != "" {
+		return errors.New("--record and --replay cannot be used together")
+	}
+	w, ok := api.(transportWrapper)
+	if !ok {
+		return errors.New("the API cannot be recorded or replayed")
+	}
+
+	var rt http.RoundTripper
+	var err error
+	w.WrapTransport(func(base http.RoundTripper) http.RoundTripper {
+		if replay != "" {
+			rt, err = sdapi.NewReplayer(replay)
+		} else {
+			rt, err = sdapi.NewRecorder(base, record)
+		}
+		if err != nil {
+			return base
+		}
+		return rt
+	})
+	return err
+}
+
+// traceTransport logs API requests to stderr on verbose or debug, and appends them to traceFile as JSON lines.
+// it returns the opened traceFile, which the caller closes after the command.
+func traceTransport(api sdapi.Interface, verbose, debug bool, traceFile string) (*os.File, error) {
+	var handlers []sdapi.TraceHandler
+	if verbose || debug {
+		handlers = append(handlers, sdapi.LogfmtTraceHandler(os.Stderr))
+	}
+	if len(handlers) == 0 && traceFile == "" {
+		return nil, nil
+	}
+	w, ok := api.(transportWrapper)
+	if !ok {
+		return nil, errors.New("the API cannot be traced")
+	}
+	var f *os.File
+	if traceFile != "" {
+		var err error
+		f, err = os.OpenFile(traceFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, sdapi.JSONTraceHandler(f))
+	}
+	w.WrapTransport(func(base http.RoundTripper) http.RoundTripper {
+		return sdapi.NewTraceTransport(base, debug, sdapi.MultiTraceHandler(handlers...))
+	})
+	return f, nil
+}
+
+// cacheTransport caches responses of rarely changing resources in the directory of the current context.
+// it wraps the transport after tracing, so that only requests sent to the API are traced.
+func cacheTransport(api sdapi.Interface, config sdctl_context.SdctlConfig) error {
+	w, ok := api.(transportWrapper)
+	if !ok {
+		return nil
+	}
+	sdctx := config.SdctlContexts[config.CurrentContext]
+	dir, err := sdapi.CacheDir(config.CurrentContext, sdctx.APIURL, sdctx.APIToken())
+	if err != nil {
+		// requests work without the cache
+		return nil
+	}
+	w.WrapTransport(func(base http.RoundTripper) http.RoundTripper {
+		return sdapi.NewCacheTransport(base, dir, sdapi.DefaultCacheTTLs)
+	})
+	return nil
+}
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+func NewCmdCollection(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "collection",
+		Short:   "handle screwdriver collections",
+		Aliases: []string{"col"},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewCmdCollectionList(config, api))
+	cmd.AddCommand(NewCmdCollectionGet(api))
+	cmd.AddCommand(NewCmdCollectionCreate(api))
+	cmd.AddCommand(NewCmdCollectionDelete(api))
+	cmd.AddCommand(NewCmdCollectionAdd(api, true))
+	cmd.AddCommand(NewCmdCollectionAdd(api, false))
+
+	return cmd
+}
+
+// resolvePipelineIDs resolves pipeline IDs or repository names to pipeline IDs
+func resolvePipelineIDs(ctx context.Context, api sdapi.Interface, pipelines []string) ([]int, error) {
+	ids := make([]int, len(pipelines))
+	for i, p := range pipelines {
+		id, err := api.ResolvePipelineID(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
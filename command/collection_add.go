@@ -0,0 +1,87 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type CollectionAddOption struct {
+	API sdapi.Interface
+	Add bool
+}
+
+// NewCmdCollectionAdd returns collection add command, or collection remove command when add is false
+func NewCmdCollectionAdd(api sdapi.Interface, add bool) *cobra.Command {
+	o := &CollectionAddOption{
+		API: api,
+		Add: add,
+	}
+	use, short := "add", "add pipelines to a collection, pipelines are IDs or repository names such as org/repo"
+	if !add {
+		use, short = "remove", "remove pipelines from a collection, pipelines are IDs or repository names such as org/repo"
+	}
+	cmd := &cobra.Command{
+		Use:   use + " <collection> <pipeline...>",
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *CollectionAddOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return cmd.Help()
+	}
+
+	collection, err := o.API.FindCollection(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	ids, err := resolvePipelineIDs(cmd.Context(), o.API, args[1:])
+	if err != nil {
+		return err
+	}
+
+	targets := make(map[int]bool)
+	for _, id := range ids {
+		targets[id] = true
+	}
+	var pipelineIDs []int
+	for _, id := range collection.PipelineIDs {
+		if targets[id] {
+			if o.Add {
+				// already in the collection
+				delete(targets, id)
+			} else {
+				continue
+			}
+		}
+		pipelineIDs = append(pipelineIDs, id)
+	}
+	if o.Add {
+		for _, id := range ids {
+			if targets[id] {
+				pipelineIDs = append(pipelineIDs, id)
+				delete(targets, id)
+			}
+		}
+	}
+	if pipelineIDs == nil {
+		pipelineIDs = []int{}
+	}
+
+	if _, err := o.API.UpdateCollection(cmd.Context(), collection.ID, sdapi.CollectionRequest{
+		Name:        collection.Name,
+		Description: collection.Description,
+		PipelineIDs: pipelineIDs,
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Successfully updated a collection ID %v: %d pipelines\n", collection.ID, len(pipelineIDs))
+	return nil
+}
@@ -0,0 +1,51 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type CollectionCreateOption struct {
+	API         sdapi.Interface
+	Description string
+}
+
+func NewCmdCollectionCreate(api sdapi.Interface) *cobra.Command {
+	o := &CollectionCreateOption{
+		API: api,
+	}
+	cmd := &cobra.Command{
+		Use:   "create <name> [pipeline...]",
+		Short: "create a collection, pipelines are IDs or repository names such as org/repo",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Description, "description", "d", "", "collection description")
+
+	return cmd
+}
+
+func (o *CollectionCreateOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+
+	ids, err := resolvePipelineIDs(cmd.Context(), o.API, args[1:])
+	if err != nil {
+		return err
+	}
+	collection, err := o.API.CreateCollection(cmd.Context(), sdapi.CollectionRequest{
+		Name:        args[0],
+		Description: o.Description,
+		PipelineIDs: ids,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Successfully created a collection ID %v\n", collection.ID)
+	return nil
+}
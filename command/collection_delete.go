@@ -0,0 +1,46 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type CollectionDeleteOption struct {
+	API sdapi.Interface
+}
+
+func NewCmdCollectionDelete(api sdapi.Interface) *cobra.Command {
+	o := &CollectionDeleteOption{
+		API: api,
+	}
+	cmd := &cobra.Command{
+		Use:     "delete <name or id...>",
+		Short:   "delete collections",
+		Aliases: []string{"rm"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *CollectionDeleteOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+
+	for _, a := range args {
+		collection, err := o.API.FindCollection(cmd.Context(), a)
+		if err != nil {
+			return err
+		}
+		if err := o.API.DeleteCollection(cmd.Context(), collection.ID); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Successfully deleted a collection ID %v\n", collection.ID)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type CollectionGetOption struct {
+	API         sdapi.Interface
+	Concurrency int
+}
+
+type pipelineStatus struct {
+	pipeline sdapi.Pipeline
+	event    *sdapi.Event
+	status   string
+	err      error
+}
+
+func NewCmdCollectionGet(api sdapi.Interface) *cobra.Command {
+	o := &CollectionGetOption{
+		API: api,
+	}
+	cmd := &cobra.Command{
+		Use:   "get <name or id>",
+		Short: "show pipelines of a collection with the status of their last event",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().IntVarP(&o.Concurrency, "concurrency", "c", 4, "number of pipelines fetched in parallel")
+
+	return cmd
+}
+
+func (o *CollectionGetOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+	if o.Concurrency < 1 {
+		return fmt.Errorf("--concurrency should be positive: %d", o.Concurrency)
+	}
+
+	collection, err := o.API.FindCollection(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "%v (ID %v) %v\n\n", collection.Name, collection.ID, collection.Description)
+
+	statuses := o.fetch(cmd.Context(), collection.Pipelines)
+	o.printColumn("ID", "Pipeline", "Status", "SHA", "Message")
+	for _, s := range statuses {
+		switch {
+		case s.err != nil:
+			o.printColumn(s.pipeline.ID, s.pipeline.Name, sdapi.StatusUnknown, "-", s.err)
+		case s.event == nil:
+			o.printColumn(s.pipeline.ID, s.pipeline.Name, "-", "-", "no events")
+		default:
+			sha := s.event.SHA
+			if len(sha) > 7 {
+				sha = sha[:7]
+			}
+			msg := strings.SplitN(s.event.CauseMessage, "\n", 2)[0]
+			o.printColumn(s.pipeline.ID, s.pipeline.Name, s.status, sha, msg)
+		}
+	}
+	return nil
+}
+
+// fetch gets the last event of pipelines in parallel, keeping the order of pipelines
+func (o *CollectionGetOption) fetch(ctx context.Context, pipelines []sdapi.Pipeline) []pipelineStatus {
+	statuses := make([]pipelineStatus, len(pipelines))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				statuses[i] = lastEventStatus(ctx, o.API, pipelines[i])
+			}
+		}()
+	}
+	for i := range pipelines {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return statuses
+}
+
+func lastEventStatus(ctx context.Context, api sdapi.Interface, pipeline sdapi.Pipeline) pipelineStatus {
+	s := pipelineStatus{pipeline: pipeline}
+	events, err := api.GetPipelineEvents(ctx, pipeline.ID, 1)
+	if err != nil {
+		s.err = err
+		return s
+	}
+	if len(events) == 0 {
+		return s
+	}
+	s.event = &events[0]
+	builds, err := api.GetEventBuilds(ctx, s.event.ID)
+	if err != nil {
+		s.err = err
+		return s
+	}
+	s.status = sdapi.EventStatus(builds)
+	return s
+}
+
+func (o *CollectionGetOption) printColumn(id, name, status, sha, msg interface{}) {
+	fmt.Fprintf(os.Stdout, "%-8v%-40v%-12v%-10v%v\n", id, name, status, sha, msg)
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+type CollectionListOption struct {
+	API    sdapi.Interface
+	List   listFlags
+	Output outputFlag
+}
+
+func NewCmdCollectionList(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &CollectionListOption{
+		API: api,
+	}
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "get a list of your collections",
+		Aliases: []string{"ls"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	addListFlags(cmd, &o.List)
+	addOutputFlag(cmd, &o.Output, config)
+
+	return cmd
+}
+
+func (o *CollectionListOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmd.Help()
+	}
+
+	opts, err := o.List.options()
+	if err != nil {
+		return err
+	}
+	if err := o.Output.validate(); err != nil {
+		return err
+	}
+	collections, err := o.API.ListCollections(cmd.Context(), opts)
+	if err != nil {
+		return err
+	}
+	if o.Output.json() {
+		return printJSON(collections)
+	}
+	o.printColumn("ID", "Name", "Pipelines", "Description")
+	for _, c := range collections {
+		o.printColumn(c.ID, c.Name, len(c.PipelineIDs), c.Description)
+	}
+	return nil
+}
+
+func (o *CollectionListOption) printColumn(id, name, pipelines, description interface{}) {
+	fmt.Fprintf(os.Stdout, "%-8v%-28v%-12v%v\n", id, name, pipelines, description)
+}
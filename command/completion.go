@@ -0,0 +1,335 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/completion"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+func NewCmdCompletion() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "generate the completion script for a shell",
+		Long: `generate the completion script for a shell.
+pipelines, jobs, secrets, banners and templates are completed from the API and cached for a minute.
+
+  bash:       source <(sdctl completion bash)
+  zsh:        sdctl completion zsh > "${fpath[1]}/_sdctl"
+  fish:       sdctl completion fish > ~/.config/fish/completions/sdctl.fish
+  powershell: sdctl completion powershell | Out-String | Invoke-Expression`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(os.Stdout)
+			default:
+				return fmt.Errorf("unsupported shell %s", args[0])
+			}
+		},
+	}
+	return cmd
+}
+
+// completeFunc is a completion function of cobra
+type completeFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// registerCompletions registers completion functions to commands and flags of root
+func registerCompletions(root *cobra.Command, config sdctl_context.SdctlConfig, api sdapi.Interface) {
+	c := &completer{config: config, api: api}
+	args := map[string]completeFunc{
+		"build":                  positional(c.pipelines, c.jobsOfArg(0, true)),
+		"context set":            positional(c.contexts),
+		"context show":           positional(c.contexts),
+		"open pipeline":          positional(c.pipelines),
+		"open job":               positional(c.pipelines, c.jobsOfArg(0, false)),
+		"open template":          positional(c.templates),
+		"top":                    c.pipelines,
+		"collection create":      repeatLast(none, c.pipelines),
+		"collection add":         repeatLast(none, c.pipelines),
+		"collection remove":      repeatLast(none, c.pipelines),
+		"banner delete":          c.banners,
+		"banner update":          positional(c.banners),
+		"banner activate":        c.banners,
+		"banner deactivate":      c.banners,
+		"banner template set":    positional(c.bannerTemplates),
+		"banner template delete": positional(c.bannerTemplates),
+		"set alias":              positional(c.aliases),
+	}
+	flags := map[string]map[string]completeFunc{
+		"secret set":      {"pipeline": c.pipelines, "key": c.secrets},
+		"token create":    {"pipeline": c.pipelines},
+		"token list":      {"pipeline": c.pipelines, "output": c.outputs},
+		"token refresh":   {"pipeline": c.pipelines},
+		"token revoke":    {"pipeline": c.pipelines},
+		"set defaults":    {"pipeline": c.pipelines, "start-from": c.jobsOfFlag, "output": c.outputs},
+		"banner announce": {"template": c.bannerTemplates},
+		"banner get":      {"output": c.outputs},
+		"collection list": {"output": c.outputs},
+		"get build-pages": {"output": c.outputs},
+	}
+
+	for path, f := range args {
+		findCommand(root, path).ValidArgsFunction = f
+	}
+	for path, fs := range flags {
+		cmd := findCommand(root, path)
+		for name, f := range fs {
+			if err := cmd.RegisterFlagCompletionFunc(name, f); err != nil {
+				panic(fmt.Sprintf("completion of --%s of '%s': %v", name, path, err))
+			}
+		}
+	}
+}
+
+// findCommand returns the command at path of root. it panics when the command is not found,
+// so that renaming a command does not silently drop its completion.
+func findCommand(root *cobra.Command, path string) *cobra.Command {
+	cmd, _, err := root.Find(strings.Fields(path))
+	if err != nil || cmd.CommandPath() != root.Name()+" "+path {
+		panic(fmt.Sprintf("completion of unknown command '%s'", path))
+	}
+	return cmd
+}
+
+// positional completes the i-th argument by fs[i], and no more arguments
+func positional(fs ...completeFunc) completeFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= len(fs) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fs[len(args)](cmd, args, toComplete)
+	}
+}
+
+// repeatLast completes the i-th argument by fs[i], and the rest by the last one, such as pipelines of collection add
+func repeatLast(fs ...completeFunc) completeFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		i := len(args)
+		if i >= len(fs) {
+			i = len(fs) - 1
+		}
+		return fs[i](cmd, args, toComplete)
+	}
+}
+
+func none(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completer completes arguments from the config and the API. candidates from the API are cached on disk briefly.
+type completer struct {
+	config sdctl_context.SdctlConfig
+	api    sdapi.Interface
+}
+
+// cached returns candidates of kind and arg in the current context from the cache, or calls fetch
+func (c *completer) cached(kind, arg string, fetch func() ([]string, error)) ([]string, error) {
+	dir, err := completion.DefaultDir()
+	if err != nil {
+		return fetch()
+	}
+	sdctx := c.config.SdctlContexts[c.config.CurrentContext]
+	key := strings.Join([]string{c.config.CurrentContext, sdctx.APIURL, kind, arg}, "\x00")
+	return completion.NewCache(dir).Get(key, fetch)
+}
+
+// result filters candidates by toComplete and reports errors to the debug log of cobra
+func result(candidates []string, err error, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var filtered []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, toComplete) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, cobra.ShellCompDirectiveNoFileComp
+}
+
+func (c *completer) contexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for name := range c.config.SdctlContexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return result(names, nil, toComplete)
+}
+
+func (c *completer) bannerTemplates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for name := range c.config.BannerTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return result(names, nil, toComplete)
+}
+
+func (c *completer) aliases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for name, commandLine := range c.config.Aliases {
+		names = append(names, name+"\t"+commandLine)
+	}
+	sort.Strings(names)
+	return result(names, nil, toComplete)
+}
+
+func (c *completer) outputs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return result(sdctl_context.OutputFormats, nil, toComplete)
+}
+
+// pipelines completes repository names, or IDs when toComplete is a number
+func (c *completer) pipelines(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	_, err := strconv.Atoi(toComplete)
+	byID := err == nil
+	search := toComplete
+	if byID {
+		search = ""
+	}
+	candidates, err := c.cached("pipelines", search, func() ([]string, error) {
+		pipelines, err := c.api.ListPipelines(cmd.Context(), search, sdapi.ListOptions{Limit: sdapi.DefaultPageCount})
+		if err != nil {
+			return nil, err
+		}
+		var candidates []string
+		for _, p := range pipelines {
+			candidates = append(candidates, fmt.Sprintf("%s\t%d", p.SCMRepo.Name, p.ID), fmt.Sprintf("%d\t%s", p.ID, p.SCMRepo.Name))
+		}
+		return candidates, nil
+	})
+	if err != nil {
+		return result(nil, err, toComplete)
+	}
+	// names, or IDs for numbers
+	var filtered []string
+	for _, candidate := range candidates {
+		_, err := strconv.Atoi(strings.SplitN(candidate, "\t", 2)[0])
+		if (err == nil) == byID {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return result(filtered, nil, toComplete)
+}
+
+// templates completes full names of templates on the API, such as screwdriver/node
+func (c *completer) templates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	candidates, err := c.cached("templates", "", func() ([]string, error) {
+		templates, err := c.api.ListTemplates(cmd.Context(), sdapi.ListOptions{Limit: 10 * sdapi.DefaultPageCount})
+		if err != nil {
+			return nil, err
+		}
+		// the API may return a template for each version
+		seen := make(map[string]bool)
+		var candidates []string
+		for _, t := range templates {
+			if !seen[t.FullName()] {
+				seen[t.FullName()] = true
+				candidates = append(candidates, t.FullName()+"\t"+t.Description)
+			}
+		}
+		return candidates, nil
+	})
+	return result(candidates, err, toComplete)
+}
+
+// jobsOfArg completes jobs of the pipeline in args[i], with triggers of start_from such as ~commit if triggers
+func (c *completer) jobsOfArg(i int, triggers bool) completeFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return c.jobs(cmd, args[i], triggers, toComplete)
+	}
+}
+
+// jobsOfFlag completes jobs of the pipeline of --pipeline, default to the default pipeline of the context
+func (c *completer) jobsOfFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	pipeline, _ := cmd.Flags().GetString("pipeline")
+	if pipeline == "" {
+		pipeline = currentDefaults(c.config).Pipeline
+	}
+	if pipeline == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return c.jobs(cmd, pipeline, true, toComplete)
+}
+
+func (c *completer) jobs(cmd *cobra.Command, pipeline string, triggers bool, toComplete string) ([]string, cobra.ShellCompDirective) {
+	candidates, err := c.cached("jobs", pipeline, func() ([]string, error) {
+		pipelineID, err := c.api.ResolvePipelineID(cmd.Context(), pipeline)
+		if err != nil {
+			return nil, err
+		}
+		jobs, err := c.api.GetPipelineJobs(cmd.Context(), pipelineID)
+		if err != nil {
+			return nil, err
+		}
+		var candidates []string
+		for _, j := range jobs {
+			if !j.Archived {
+				candidates = append(candidates, j.Name)
+			}
+		}
+		return candidates, nil
+	})
+	if triggers && err == nil {
+		candidates = append(candidates, "~commit\tjobs triggered by commits", "~pr\tjobs triggered by pull requests")
+	}
+	return result(candidates, err, toComplete)
+}
+
+// secrets completes secret keys of the pipeline of --pipeline, whose default is the default pipeline of the context
+func (c *completer) secrets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	pipeline, _ := cmd.Flags().GetString("pipeline")
+	if pipeline == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	candidates, err := c.cached("secrets", pipeline, func() ([]string, error) {
+		pipelineID, err := c.api.ResolvePipelineID(cmd.Context(), pipeline)
+		if err != nil {
+			return nil, err
+		}
+		secrets, err := c.api.GetPipelineSecrets(cmd.Context(), pipelineID)
+		if err != nil {
+			return nil, err
+		}
+		var candidates []string
+		for _, s := range secrets {
+			candidates = append(candidates, s.Name)
+		}
+		return candidates, nil
+	})
+	return result(candidates, err, toComplete)
+}
+
+// banners completes banner IDs with their messages
+func (c *completer) banners(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	candidates, err := c.cached("banners", "", func() ([]string, error) {
+		banners, err := c.api.ListBanners(cmd.Context(), sdapi.ListOptions{Limit: sdapi.DefaultPageCount})
+		if err != nil {
+			return nil, err
+		}
+		var candidates []string
+		for _, b := range banners {
+			candidates = append(candidates, fmt.Sprintf("%d\t%s", b.ID, b.Message))
+		}
+		return candidates, nil
+	})
+	return result(candidates, err, toComplete)
+}
@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdConfig() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "view, edit and validate the config file of sdctl",
+		Long: `view, edit and validate the config file of sdctl.
+the config file is ~/.config/sdctl/config.yaml (or under $XDG_CONFIG_HOME) if it exists, otherwise ~/.sdctl`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		NewCmdConfigView(),
+		NewCmdConfigEdit(),
+		NewCmdConfigValidate())
+	return cmd
+}
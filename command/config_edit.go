@@ -0,0 +1,118 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type ConfigEditOption struct{}
+
+func NewCmdConfigEdit() *cobra.Command {
+	o := &ConfigEditOption{}
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "edit the config file with $VISUAL or $EDITOR, saving it only when it is valid",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *ConfigEditOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmd.Help()
+	}
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	original, err := ioutil.ReadFile(configPATH)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// the extension lets editors highlight the format
+	ext := ".json"
+	if sdctl_context.IsYAML(configPATH) {
+		ext = ".yaml"
+	}
+	tmp, err := ioutil.TempFile("", "sdctl-config-*"+ext)
+	if err != nil {
+		return err
+	}
+	defer tmp.Close()
+	if _, err := tmp.Write(original); err != nil {
+		return err
+	}
+	tmp.Close()
+
+	if err := runEditor(tmp.Name()); err != nil {
+		return err
+	}
+	edited, err := ioutil.ReadFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(original, edited) {
+		os.Remove(tmp.Name())
+		fmt.Fprintln(os.Stdout, "config is not changed")
+		return nil
+	}
+
+	config, _, err := sdctl_context.ParseConfig(edited, sdctl_context.IsYAML(configPATH))
+	if err == nil {
+		if errs := config.Validate(); len(errs) > 0 {
+			err = joinErrors(errs)
+		}
+	}
+	if err == nil {
+		err = sdctl_context.ReplaceConfig(configPATH, original, edited)
+	}
+	if err != nil {
+		return fmt.Errorf("config is not saved, your changes are kept in %s: %v", tmp.Name(), err)
+	}
+	os.Remove(tmp.Name())
+	fmt.Fprintf(os.Stdout, "%s is saved\n", configPATH)
+	return nil
+}
+
+// runEditor opens path with $VISUAL or $EDITOR, which may have arguments such as "code --wait"
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := strings.Fields(editor)
+	c := exec.Command(args[0], append(args[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %v", editor, err)
+	}
+	return nil
+}
+
+// joinErrors joins errors into one, a line per error
+func joinErrors(errs []error) error {
+	var lines []string
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	return errors.New(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type ConfigValidateOption struct{}
+
+func NewCmdConfigValidate() *cobra.Command {
+	o := &ConfigValidateOption{}
+	cmd := &cobra.Command{
+		Use:   "validate [<file>]",
+		Short: "validate a config file, default to the config file of sdctl",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *ConfigValidateOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return cmd.Help()
+	}
+	var path string
+	if len(args) == 1 {
+		path = args[0]
+	} else {
+		p, err := util.ConfigPATH()
+		if err != nil {
+			return err
+		}
+		path = p
+	}
+
+	config, version, err := sdctl_context.ReadConfig(path)
+	if err != nil {
+		return err
+	}
+	if errs := config.Validate(); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stdout, "%s: %v\n", path, err)
+		}
+		return fmt.Errorf("%s has %d problems", path, len(errs))
+	}
+	if version < sdctl_context.CurrentVersion {
+		fmt.Fprintf(os.Stdout, "%s is valid, and will be migrated from version %d to %d\n", path, version, sdctl_context.CurrentVersion)
+		return nil
+	}
+	fmt.Fprintf(os.Stdout, "%s is valid\n", path)
+	return nil
+}
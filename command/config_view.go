@@ -0,0 +1,83 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type ConfigViewOption struct {
+	Raw    bool
+	Format string
+}
+
+func NewCmdConfigView() *cobra.Command {
+	o := &ConfigViewOption{}
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "print the config file with tokens redacted",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().BoolVarP(&o.Raw, "raw", "", false, "print tokens and JWTs as they are")
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "yaml or json, default to the format of the config file")
+
+	return cmd
+}
+
+func (o *ConfigViewOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmd.Help()
+	}
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	isYAML := sdctl_context.IsYAML(configPATH)
+	switch o.Format {
+	case "":
+	case "yaml":
+		isYAML = true
+	case "json":
+		isYAML = false
+	default:
+		return fmt.Errorf("--format should be yaml or json: %s", o.Format)
+	}
+
+	config, _, err := sdctl_context.ReadConfig(configPATH)
+	if err != nil {
+		return err
+	}
+	if !o.Raw {
+		config = redactConfig(config)
+	}
+	b, err := sdctl_context.MarshalConfig(config, isYAML)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(b)
+	return err
+}
+
+// redactConfig returns a copy of config whose tokens and JWTs are replaced
+func redactConfig(config sdctl_context.SdctlConfig) sdctl_context.SdctlConfig {
+	redact := func(s string) string {
+		if s == "" {
+			return s
+		}
+		return "REDACTED"
+	}
+	contexts := make(map[string]sdctl_context.SdctlContext, len(config.SdctlContexts))
+	for name, sdctx := range config.SdctlContexts {
+		sdctx.UserToken = redact(sdctx.UserToken)
+		sdctx.PipelineToken = redact(sdctx.PipelineToken)
+		sdctx.SDJWT = redact(sdctx.SDJWT)
+		contexts[name] = sdctx
+	}
+	config.SdctlContexts = contexts
+	return config
+}
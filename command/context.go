@@ -6,7 +6,7 @@ import (
 	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
 )
 
-func NewCmdContext(config sdctl_context.SdctlConfig, api sdapi.SDAPI) *cobra.Command {
+func NewCmdContext(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "context",
 		Short: "handle screwdriver contexts",
@@ -18,6 +18,7 @@ func NewCmdContext(config sdctl_context.SdctlConfig, api sdapi.SDAPI) *cobra.Com
 	cmd.AddCommand(
 		NewCmdContextList(config),
 		NewCmdContextCurrent(config),
-		NewCmdContextSet(config))
+		NewCmdContextSet(config),
+		NewCmdContextShow(config))
 	return cmd
 }
@@ -0,0 +1,117 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+type ContextShowOption struct {
+	Config sdctl_context.SdctlConfig
+}
+
+func NewCmdContextShow(config sdctl_context.SdctlConfig) *cobra.Command {
+	o := &ContextShowOption{
+		Config: config,
+	}
+	cmd := &cobra.Command{
+		Use:   "show [<context>]",
+		Short: "show effective settings of a context, default to the current context. tokens are not shown",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *ContextShowOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return cmd.Help()
+	}
+	name := o.Config.CurrentContext
+	if len(args) == 1 {
+		name = args[0]
+	}
+	sdctx, ok := o.Config.SdctlContexts[name]
+	if !ok {
+		return fmt.Errorf("context %s does not exist", name)
+	}
+	return showContext(os.Stdout, name, sdctx)
+}
+
+func showContext(w io.Writer, name string, sdctx sdctl_context.SdctlContext) error {
+	set := func(v string) string {
+		if v == "" {
+			return "not set"
+		}
+		return "set"
+	}
+	orNone := func(v string) string {
+		if v == "" {
+			return "none"
+		}
+		return v
+	}
+
+	ui := sdctx.UIURL
+	if ui == "" {
+		ui = "none"
+		if api, err := sdapi.New(sdctx); err == nil {
+			ui = api.UIURL() + " (guessed from api)"
+		}
+	}
+	defaults := sdctx.GetDefaults()
+
+	network := sdctl_context.NetworkConfig{}
+	if sdctx.Network != nil {
+		network = *sdctx.Network
+	}
+	proxy := "none"
+	if u, fromEnv, err := sdapi.ProxyURL(sdctx); err != nil {
+		proxy = "invalid: " + err.Error()
+	} else if u != nil {
+		proxy = u.Redacted()
+		if fromEnv {
+			proxy += " (from environment)"
+		}
+	}
+	networkStatus := "ok"
+	if _, err := sdapi.NewTransport(sdctx.Network); err != nil {
+		networkStatus = "invalid: " + err.Error()
+	}
+	var retryStatus string
+	if retry, err := sdapi.NewRetryPolicy(sdctx.Retry); err != nil {
+		retryStatus = "invalid: " + err.Error()
+	} else {
+		retryStatus = fmt.Sprintf("%d attempts, up to %v between attempts", retry.MaxAttempts, retry.MaxDelay)
+	}
+
+	rows := [][2]string{
+		{"context", name},
+		{"api", orNone(sdctx.APIURL)},
+		{"token", set(sdctx.UserToken)},
+		{"pipeline_token", set(sdctx.PipelineToken)},
+		{"jwt", set(sdctx.SDJWT)},
+		{"ui", ui},
+		{"default_pipeline", orNone(defaults.Pipeline)},
+		{"default_start_from", orNone(defaults.StartFrom)},
+		{"default_output", orNone(defaults.Output)},
+		{"proxy", proxy},
+		{"ca_file", orNone(network.CAFile)},
+		{"insecure_skip_verify", fmt.Sprint(network.InsecureSkipVerify)},
+		{"client_cert_file", orNone(network.ClientCertFile)},
+		{"client_key_file", orNone(network.ClientKeyFile)},
+		{"network", networkStatus},
+		{"retry", retryStatus},
+	}
+	for _, r := range rows {
+		if _, err := fmt.Fprintf(w, "%-22v%v\n", r[0]+":", r[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
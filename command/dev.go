@@ -0,0 +1,21 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdDev() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "dev",
+		Short:  "tools for developing sdctl",
+		Hidden: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		NewCmdDevFakeServer(),
+	)
+	return cmd
+}
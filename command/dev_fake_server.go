@@ -0,0 +1,57 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi/sdapitest"
+)
+
+type DevFakeServerOption struct {
+	Addr  string
+	Empty bool
+}
+
+func NewCmdDevFakeServer() *cobra.Command {
+	o := &DevFakeServerOption{}
+	cmd := &cobra.Command{
+		Use:   "fake-server",
+		Short: "run an in-memory fake Screwdriver.cd API on localhost",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Addr, "addr", "a", "127.0.0.1:9090", "address to listen on")
+	cmd.Flags().BoolVarP(&o.Empty, "empty", "", false, "start without demo pipelines, builds, banners and templates")
+	return cmd
+}
+
+func (o *DevFakeServerOption) Run(cmd *cobra.Command, args []string) error {
+	fake := sdapitest.NewDemoServer()
+	if o.Empty {
+		fake = sdapitest.NewServer()
+	}
+
+	l, err := net.Listen("tcp", o.Addr)
+	if err != nil {
+		return err
+	}
+	url := "http://" + l.Addr().String()
+	fmt.Fprintf(os.Stdout, "fake Screwdriver.cd API is listening on %s, press Ctrl-C to stop\n", url)
+	fmt.Fprintf(os.Stdout, "to use it:\n  sdctl context set fake\n  sdctl set api %s\n  sdctl set token %s\n  sdctl set jwt\n", url, sdapitest.APIToken)
+
+	server := &http.Server{Handler: fake}
+	go func() {
+		<-cmd.Context().Done()
+		server.Shutdown(context.Background())
+	}()
+	if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -6,7 +6,7 @@ import (
 	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
 )
 
-func NewCmdGet(config sdctl_context.SdctlConfig, api sdapi.SDAPI) *cobra.Command {
+func NewCmdGet(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "get",
 		Short: "get sdctl settings and Screwdriver.cd information",
@@ -17,8 +17,10 @@ func NewCmdGet(config sdctl_context.SdctlConfig, api sdapi.SDAPI) *cobra.Command
 
 	cmd.AddCommand(
 		NewCmdGetToken(config),
+		NewCmdGetPipelineToken(config),
 		NewCmdGetAPI(config),
 		NewCmdGetJWT(config),
-		NewCmdGetBuildPages(api))
+		NewCmdGetUI(api),
+		NewCmdGetBuildPages(config, api))
 	return cmd
 }
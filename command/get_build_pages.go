@@ -1,37 +1,197 @@
 package command
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
 )
 
 type GetBuildPagesOption struct {
-	API sdapi.SDAPI
+	API         sdapi.Interface
+	File        string
+	Concurrency int
+	Output      outputFlag
 }
 
-func NewCmdGetBuildPages(api sdapi.SDAPI) *cobra.Command {
+func NewCmdGetBuildPages(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
 	o := &GetBuildPagesOption{
 		API: api,
 	}
 	cmd := &cobra.Command{
-		Use:     "build-pages <BUILD_ID>",
-		Short:   "get build page url",
+		Use:   "build-pages [<BUILD_ID>...]",
+		Short: "get build page url",
+		Long: `get build page urls of build IDs.
+build IDs are separated by spaces, commas or newlines, and read from stdin when it is "-" or piped.
+rows are printed in the order of build IDs, with an error for each build which is not found.`,
+		Example: `  sdctl get build-pages 156442 156518
+  sdctl get build-pages -f build-ids.txt -c 8
+  grep -o 'build [0-9]*' incident.log | cut -d' ' -f2 | sdctl get build-pages`,
 		Aliases: []string{"bp"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.Run(cmd, args)
 		},
 	}
+	cmd.Flags().StringVarP(&o.File, "file", "f", "", `file of build IDs, or "-" for stdin`)
+	cmd.Flags().IntVarP(&o.Concurrency, "concurrency", "c", 4, "number of builds looked up in parallel")
+	addOutputFlag(cmd, &o.Output, config)
 	return cmd
 }
 
+// buildIDInput is a word of the input, which is a build ID unless err is set
+type buildIDInput struct {
+	word string
+	id   int
+	err  error
+}
+
+// buildPageRow is a row of --output json
+type buildPageRow struct {
+	BuildID    string `json:"buildId"`
+	PipelineID int    `json:"pipelineId,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Repo       string `json:"repo,omitempty"`
+	Job        string `json:"job,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
 func (o *GetBuildPagesOption) Run(cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
-		return cmd.Help()
+	if err := o.Output.validate(); err != nil {
+		return err
+	}
+	if o.Concurrency < 1 {
+		return fmt.Errorf("--concurrency should be positive: %d", o.Concurrency)
 	}
-	buildID := args[0]
 
-	if err := o.API.GetPipelinePageFromBuildID(buildID); err != nil {
+	r, err := o.input(args)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		return cmd.Help()
+	}
+	if c, ok := r.(io.Closer); ok && r != os.Stdin {
+		defer c.Close()
+	}
+	inputs, err := readBuildIDs(r)
+	if err != nil {
 		return err
 	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no build IDs are given")
+	}
+
+	var buildIDs []int
+	for _, in := range inputs {
+		if in.err == nil {
+			buildIDs = append(buildIDs, in.id)
+		}
+	}
+
+	var rows []buildPageRow
+	failed := 0
+	addRow := func(row buildPageRow) {
+		if row.Error != "" {
+			failed++
+		}
+		if o.Output.json() {
+			rows = append(rows, row)
+			return
+		}
+		if row.Error != "" {
+			o.printColumn("build "+row.BuildID, "ERROR: "+row.Error)
+			return
+		}
+		o.printColumn(row.URL, fmt.Sprintf("%s (%s)", row.Repo, row.Job))
+	}
+
+	if !o.Output.json() {
+		o.printColumn("BuildURL", "Repo (Job)")
+	}
+	// results come in the order of valid build IDs, and invalid ones are printed between them
+	next := 0
+	printInvalid := func() {
+		for ; next < len(inputs) && inputs[next].err != nil; next++ {
+			addRow(buildPageRow{BuildID: inputs[next].word, Error: inputs[next].err.Error()})
+		}
+	}
+	o.API.GetBuildPages(cmd.Context(), buildIDs, o.Concurrency, func(result sdapi.BuildPageResult) {
+		printInvalid()
+		next++
+		row := buildPageRow{BuildID: strconv.Itoa(result.BuildID)}
+		if result.Err != nil {
+			row.Error = result.Err.Error()
+		} else {
+			row.PipelineID = result.Page.PipelineID
+			row.URL = result.Page.URL
+			row.Repo = result.Page.Repo
+			row.Job = result.Page.Job
+		}
+		addRow(row)
+	})
+	printInvalid()
+
+	if o.Output.json() {
+		if err := printJSON(rows); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d builds failed", failed, len(inputs))
+	}
 	return nil
 }
+
+// input returns the reader of build IDs from --file, stdin or args. it is nil when nothing is given on a terminal.
+func (o *GetBuildPagesOption) input(args []string) (io.Reader, error) {
+	if o.File != "" {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("build IDs and --file cannot be used together")
+		}
+		if o.File == "-" {
+			return os.Stdin, nil
+		}
+		return os.Open(o.File)
+	}
+	if len(args) == 1 && args[0] == "-" {
+		return os.Stdin, nil
+	}
+	if len(args) > 0 {
+		return strings.NewReader(strings.Join(args, " ")), nil
+	}
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice == 0 {
+		return os.Stdin, nil
+	}
+	return nil, nil
+}
+
+// readBuildIDs reads words separated by spaces, commas or newlines, keeping words which are not build IDs as errors
+func readBuildIDs(r io.Reader) ([]buildIDInput, error) {
+	var inputs []buildIDInput
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		for _, word := range strings.Split(scanner.Text(), ",") {
+			if word == "" {
+				continue
+			}
+			id, err := strconv.Atoi(word)
+			if err != nil || id < 1 {
+				inputs = append(inputs, buildIDInput{word: word, err: fmt.Errorf("invalid build ID %s", word)})
+				continue
+			}
+			inputs = append(inputs, buildIDInput{word: word, id: id})
+		}
+	}
+	return inputs, scanner.Err()
+}
+
+func (o *GetBuildPagesOption) printColumn(url, repo interface{}) {
+	fmt.Fprintf(os.Stdout, "%-80v%-15v\n", url, repo)
+}
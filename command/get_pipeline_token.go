@@ -0,0 +1,29 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+type GetPipelineTokenOption struct {
+	Config sdctl_context.SdctlConfig
+}
+
+func NewCmdGetPipelineToken(config sdctl_context.SdctlConfig) *cobra.Command {
+	o := &GetPipelineTokenOption{
+		Config: config,
+	}
+	cmd := &cobra.Command{
+		Use:   "pipeline-token",
+		Short: "get the pipeline token of the current context",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *GetPipelineTokenOption) Run(cmd *cobra.Command, args []string) error {
+	o.Config.PrintParam(sdctl_context.PipelineTokenKey, nil)
+	return nil
+}
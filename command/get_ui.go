@@ -0,0 +1,32 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type GetUIOption struct {
+	API sdapi.Interface
+}
+
+func NewCmdGetUI(api sdapi.Interface) *cobra.Command {
+	o := &GetUIOption{
+		API: api,
+	}
+	cmd := &cobra.Command{
+		Use:   "ui",
+		Short: "get the UI url, guessed from the api url when it is not set",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *GetUIOption) Run(cmd *cobra.Command, args []string) error {
+	fmt.Fprintln(os.Stdout, o.API.UIURL())
+	return nil
+}
@@ -0,0 +1,91 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/lint"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type LintOption struct {
+	PipelineFilePATH []string
+	ConfigPATH       string
+	ListRules        bool
+}
+
+func NewCmdLint() *cobra.Command {
+	o := &LintOption{}
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "check your screwdriver.yaml against conventions, default to screwdriver.yaml",
+		Long: `check your screwdriver.yaml against conventions beyond the schema, default to screwdriver.yaml.
+severities of rules (error, warning, off) are configured in .sdctl-lint.yaml:
+
+  rules:
+    unpinned-image: error
+    duplicate-steps: off
+
+add "# sdctl:ignore <rule>" to a line, or to the line before it, to ignore an issue`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringSliceVarP(&o.PipelineFilePATH, "file", "f", []string{"screwdriver.yaml"}, "specify pipeline file paths or globs")
+	cmd.Flags().StringVarP(&o.ConfigPATH, "config", "", lint.DefaultConfigPATH, "specify lint config file path")
+	cmd.Flags().BoolVarP(&o.ListRules, "list-rules", "", false, "show rules and their severities")
+
+	return cmd
+}
+
+func (o *LintOption) Run(cmd *cobra.Command, args []string) error {
+	config, err := lint.LoadConfig(o.ConfigPATH)
+	if err != nil {
+		return err
+	}
+	linter, err := lint.New(config, lint.DefaultRules()...)
+	if err != nil {
+		return err
+	}
+
+	if o.ListRules {
+		o.printColumn("Rule", "Severity", "Description")
+		for _, r := range linter.Rules() {
+			o.printColumn(r.Name(), linter.Severity(r.Name()), r.Description())
+		}
+		return nil
+	}
+
+	files, err := util.ExpandGlobs(o.PipelineFilePATH)
+	if err != nil {
+		return err
+	}
+
+	errorCount := 0
+	for _, f := range files {
+		src, err := util.LoadYaml(f)
+		if err != nil {
+			return err
+		}
+		issues, err := linter.Lint(src)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %v", f, err)
+		}
+		for _, i := range issues {
+			fmt.Fprintf(os.Stdout, "%s:%d: [%s] %s: %s\n", f, i.Line, i.Severity, i.Rule, i.Message)
+			if i.Severity == lint.SeverityError {
+				errorCount++
+			}
+		}
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("%d lint errors found", errorCount)
+	}
+	return nil
+}
+
+func (o *LintOption) printColumn(rule, severity, description interface{}) {
+	fmt.Fprintf(os.Stdout, "%-20v%-10v%v\n", rule, severity, description)
+}
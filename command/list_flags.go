@@ -0,0 +1,29 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+// listFlags are --limit and --all of list commands
+type listFlags struct {
+	Limit int
+	All   bool
+}
+
+func addListFlags(cmd *cobra.Command, f *listFlags) {
+	cmd.Flags().IntVarP(&f.Limit, "limit", "", sdapi.DefaultPageCount, "maximum number of items to list")
+	cmd.Flags().BoolVarP(&f.All, "all", "", false, "list all items, paging through results")
+}
+
+func (f listFlags) options() (sdapi.ListOptions, error) {
+	if f.All {
+		return sdapi.ListOptions{}, nil
+	}
+	if f.Limit < 1 {
+		return sdapi.ListOptions{}, fmt.Errorf("--limit should be positive: %d", f.Limit)
+	}
+	return sdapi.ListOptions{Limit: f.Limit}, nil
+}
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+// openFlags are flags of open commands
+type openFlags struct {
+	Print bool
+}
+
+func NewCmdOpen(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	f := &openFlags{}
+	cmd := &cobra.Command{
+		Use:   "open",
+		Short: "open pages of the Screwdriver.cd UI in a browser",
+		Long: `open pages of the Screwdriver.cd UI in a browser.
+the UI url is set by 'sdctl set ui', or guessed from the api url`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().BoolVarP(&f.Print, "print", "p", false, "only print the url")
+
+	cmd.AddCommand(
+		NewCmdOpenPipeline(config, api, f),
+		NewCmdOpenBuild(api, f),
+		NewCmdOpenEvent(api, f),
+		NewCmdOpenJob(config, api, f),
+		NewCmdOpenTemplate(api, f),
+	)
+	return cmd
+}
+
+// open prints url and launches a browser for it unless --print.
+// the url is still printed when no browser can be launched, such as on remote hosts.
+func (f *openFlags) open(url string) error {
+	fmt.Fprintln(os.Stdout, url)
+	if f.Print {
+		return nil
+	}
+	if err := browserCommand(url).Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] failed to launch a browser: %v\n", err)
+	}
+	return nil
+}
+
+// browserCommand returns the command opening url in $BROWSER or the default browser of the OS
+func browserCommand(url string) *exec.Cmd {
+	if browser := os.Getenv("BROWSER"); browser != "" {
+		return exec.Command(browser, url)
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url)
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		return exec.Command("xdg-open", url)
+	}
+}
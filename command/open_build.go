@@ -0,0 +1,48 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type OpenBuildOption struct {
+	API   sdapi.Interface
+	Flags *openFlags
+}
+
+func NewCmdOpenBuild(api sdapi.Interface, f *openFlags) *cobra.Command {
+	o := &OpenBuildOption{
+		API:   api,
+		Flags: f,
+	}
+	cmd := &cobra.Command{
+		Use:   "build <build id>",
+		Short: "open a build",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *OpenBuildOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+	buildID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid build ID %s", args[0])
+	}
+	build, err := o.API.GetBuild(cmd.Context(), buildID)
+	if err != nil {
+		return err
+	}
+	job, err := o.API.GetJob(cmd.Context(), build.JobID)
+	if err != nil {
+		return err
+	}
+	return o.Flags.open(o.API.BuildPageURL(job.PipelineID, buildID))
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type OpenEventOption struct {
+	API   sdapi.Interface
+	Flags *openFlags
+}
+
+func NewCmdOpenEvent(api sdapi.Interface, f *openFlags) *cobra.Command {
+	o := &OpenEventOption{
+		API:   api,
+		Flags: f,
+	}
+	cmd := &cobra.Command{
+		Use:   "event <event id>",
+		Short: "open an event",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *OpenEventOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+	eventID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid event ID %s", args[0])
+	}
+	event, err := o.API.GetEvent(cmd.Context(), eventID)
+	if err != nil {
+		return err
+	}
+	return o.Flags.open(o.API.EventPageURL(event.PipelineID, eventID))
+}
@@ -0,0 +1,59 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+type OpenJobOption struct {
+	API      sdapi.Interface
+	Defaults sdctl_context.DefaultsConfig
+	Flags    *openFlags
+}
+
+func NewCmdOpenJob(config sdctl_context.SdctlConfig, api sdapi.Interface, f *openFlags) *cobra.Command {
+	o := &OpenJobOption{
+		API:      api,
+		Defaults: currentDefaults(config),
+		Flags:    f,
+	}
+	cmd := &cobra.Command{
+		Use:   "job [<pipeline>] <job>",
+		Short: "open a job by name, the pipeline defaults to the default pipeline of the context",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *OpenJobOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return cmd.Help()
+	}
+	pipeline, jobName := o.Defaults.Pipeline, args[len(args)-1]
+	if len(args) == 2 {
+		pipeline = args[0]
+	}
+	if pipeline == "" {
+		return errors.New("pipeline is required without the default pipeline, set it by 'sdctl set defaults --pipeline'")
+	}
+	pipelineID, err := o.API.ResolvePipelineID(cmd.Context(), pipeline)
+	if err != nil {
+		return err
+	}
+	jobs, err := o.API.GetPipelineJobs(cmd.Context(), pipelineID)
+	if err != nil {
+		return err
+	}
+	for _, j := range jobs {
+		if j.Name == jobName {
+			return o.Flags.open(o.API.JobPageURL(pipelineID, j.ID))
+		}
+	}
+	return fmt.Errorf("job %s is not found in pipeline %d", jobName, pipelineID)
+}
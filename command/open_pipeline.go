@@ -0,0 +1,49 @@
+package command
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+type OpenPipelineOption struct {
+	API      sdapi.Interface
+	Defaults sdctl_context.DefaultsConfig
+	Flags    *openFlags
+}
+
+func NewCmdOpenPipeline(config sdctl_context.SdctlConfig, api sdapi.Interface, f *openFlags) *cobra.Command {
+	o := &OpenPipelineOption{
+		API:      api,
+		Defaults: currentDefaults(config),
+		Flags:    f,
+	}
+	cmd := &cobra.Command{
+		Use:   "pipeline [<pipeline>]",
+		Short: "open a pipeline by ID or repository name, default to the default pipeline of the context",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *OpenPipelineOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return cmd.Help()
+	}
+	pipeline := o.Defaults.Pipeline
+	if len(args) == 1 {
+		pipeline = args[0]
+	}
+	if pipeline == "" {
+		return errors.New("pipeline is required without the default pipeline, set it by 'sdctl set defaults --pipeline'")
+	}
+	pipelineID, err := o.API.ResolvePipelineID(cmd.Context(), pipeline)
+	if err != nil {
+		return err
+	}
+	return o.Flags.open(o.API.PipelinePageURL(pipelineID))
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type OpenTemplateOption struct {
+	API   sdapi.Interface
+	Flags *openFlags
+}
+
+func NewCmdOpenTemplate(api sdapi.Interface, f *openFlags) *cobra.Command {
+	o := &OpenTemplateOption{
+		API:   api,
+		Flags: f,
+	}
+	cmd := &cobra.Command{
+		Use:   "template <namespace/name>",
+		Short: "open a template, templates without namespaces are in the default namespace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *OpenTemplateOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+	return o.Flags.open(o.API.TemplatePageURL(args[0]))
+}
@@ -0,0 +1,49 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+// outputFlag is --output of list commands, default to the output of the defaults of the current context
+type outputFlag struct {
+	Output string
+}
+
+func addOutputFlag(cmd *cobra.Command, f *outputFlag, config sdctl_context.SdctlConfig) {
+	output := currentDefaults(config).Output
+	if output == "" {
+		output = sdctl_context.OutputTable
+	}
+	cmd.Flags().StringVarP(&f.Output, "output", "o", output, fmt.Sprintf("output format (%s)", strings.Join(sdctl_context.OutputFormats, ", ")))
+}
+
+func (f outputFlag) validate() error {
+	for _, o := range sdctl_context.OutputFormats {
+		if f.Output == o {
+			return nil
+		}
+	}
+	return fmt.Errorf("--output should be one of %s: %s", strings.Join(sdctl_context.OutputFormats, ", "), f.Output)
+}
+
+func (f outputFlag) json() bool {
+	return f.Output == sdctl_context.OutputJSON
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// currentDefaults returns the defaults of the current context
+func currentDefaults(config sdctl_context.SdctlConfig) sdctl_context.DefaultsConfig {
+	return config.SdctlContexts[config.CurrentContext].GetDefaults()
+}
@@ -0,0 +1,21 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+func NewCmdRun(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "run jobs outside of Screwdriver.cd",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		NewCmdRunLocal(config, api))
+	return cmd
+}
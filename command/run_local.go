@@ -0,0 +1,119 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/localrun"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type RunLocalOption struct {
+	Config           sdctl_context.SdctlConfig
+	API              sdapi.Interface
+	PipelineFilePATH string
+	SourceDir        string
+	Env              []string
+	Matrix           []string
+	Docker           string
+}
+
+func NewCmdRunLocal(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &RunLocalOption{
+		Config: config,
+		API:    api,
+	}
+	cmd := &cobra.Command{
+		Use:   "local <job>",
+		Short: "run steps of a job locally in Docker",
+		Long: `run steps of a job locally in Docker.
+the job is expanded by the validator and its image is started with the working tree mounted at /sd/workspace/src.
+SD_* environment variables are stubbed and meta is backed by a file. secrets can be passed with --env,
+which are given to docker in a temporary env file instead of its arguments.
+a job with matrix runs the entry chosen by --matrix, e.g. --matrix NODE_VERSION=16`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&o.PipelineFilePATH, "file", "f", "screwdriver.yaml", "specify pipeline file path")
+	cmd.Flags().StringVarP(&o.SourceDir, "src", "s", ".", "working tree mounted to the container")
+	cmd.Flags().StringArrayVarP(&o.Env, "env", "e", nil, "additional environment variable KEY=VALUE, e.g. secrets")
+	cmd.Flags().StringArrayVarP(&o.Matrix, "matrix", "m", nil, "environment variable KEY=VALUE of the matrix entry to run")
+	cmd.Flags().StringVarP(&o.Docker, "docker", "", "docker", "docker command")
+
+	return cmd
+}
+
+func (o *RunLocalOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+	jobName := args[0]
+
+	env := make(map[string]string)
+	for _, e := range o.Env {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("environment variable should be KEY=VALUE: %s", e)
+		}
+		env[kv[0]] = kv[1]
+	}
+
+	yaml, err := util.ReadYaml(o.PipelineFilePATH)
+	if err != nil {
+		return err
+	}
+	vr, err := o.API.ValidatePipeline(cmd.Context(), yaml)
+	if err != nil {
+		return err
+	}
+	jobs, ok := vr.Jobs[jobName]
+	if !ok || len(jobs) == 0 {
+		return fmt.Errorf("job %s is not found in %s", jobName, o.PipelineFilePATH)
+	}
+	job, err := localrun.SelectJob(jobName, jobs, o.Matrix)
+	if err != nil {
+		return err
+	}
+
+	results, err := localrun.Run(localrun.Options{
+		Context:   cmd.Context(),
+		JobName:   jobName,
+		Job:       job,
+		SourceDir: o.SourceDir,
+		APIURL:    o.Config.SdctlContexts[o.Config.CurrentContext].APIURL,
+		Env:       env,
+		Docker:    o.Docker,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stdout)
+	o.printColumn("Step", "ExitCode", "Duration")
+	failed := false
+	for _, r := range results {
+		if r.Skipped {
+			o.printColumn(r.Name, "-", "skipped")
+			continue
+		}
+		if r.ExitCode != 0 {
+			failed = true
+		}
+		o.printColumn(r.Name, r.ExitCode, r.Duration.Round(time.Millisecond))
+	}
+	if failed {
+		return errors.New("job failed")
+	}
+	return nil
+}
+
+func (o *RunLocalOption) printColumn(step, exitCode, duration interface{}) {
+	fmt.Fprintf(os.Stdout, "%-30v%-10v%v\n", step, exitCode, duration)
+}
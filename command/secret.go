@@ -3,9 +3,10 @@ package command
 import (
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
 )
 
-func NewCmdSecret(api sdapi.SDAPI) *cobra.Command {
+func NewCmdSecret(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "secret",
 		Short: "handle screwdriver secrets (write only)",
@@ -16,7 +17,7 @@ func NewCmdSecret(api sdapi.SDAPI) *cobra.Command {
 	}
 
 	cmd.AddCommand(
-		NewCmdSecretSet(api),
+		NewCmdSecretSet(config, api),
 	)
 	return cmd
 }
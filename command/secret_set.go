@@ -1,24 +1,25 @@
 package command
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
 )
 
 type SecretSetOption struct {
-	API         sdapi.SDAPI
+	API         sdapi.Interface
 	PipelineID  string
 	SecretKey   string
 	SecretValue string
 	AllowInPR   bool
 }
 
-func NewCmdSecretSet(api sdapi.SDAPI) *cobra.Command {
+func NewCmdSecretSet(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
 	o := &SecretSetOption{
 		API: api,
 	}
@@ -30,8 +31,7 @@ func NewCmdSecretSet(api sdapi.SDAPI) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&o.PipelineID, "pipeline", "p", "", "specify pipeline id")
-	_ = cmd.MarkFlagRequired("pipeline")
+	cmd.Flags().StringVarP(&o.PipelineID, "pipeline", "p", currentDefaults(config).Pipeline, "specify pipeline id or repository name, default to the default pipeline of the context")
 	cmd.Flags().StringVarP(&o.SecretKey, "key", "k", "", "SECRET_KEY")
 	_ = cmd.MarkFlagRequired("key")
 	cmd.Flags().StringVarP(&o.SecretValue, "value", "v", "", "SECRET_VALUE")
@@ -42,14 +42,17 @@ func NewCmdSecretSet(api sdapi.SDAPI) *cobra.Command {
 }
 
 func (o *SecretSetOption) Run(cmd *cobra.Command, args []string) error {
-	pipelineIDNum, err := strconv.Atoi(o.PipelineID)
+	if o.PipelineID == "" {
+		return errors.New("--pipeline is required without the default pipeline, set it by 'sdctl set defaults --pipeline'")
+	}
+	pipelineIDNum, err := o.API.ResolvePipelineID(cmd.Context(), o.PipelineID)
 	if err != nil {
-		return fmt.Errorf("failed to convert %s to int: %v", o.PipelineID, err)
+		return err
 	}
 
 	// Screwdriver allow only "/^[A-Z_][A-Z0-9_]*$/]" as secret key
 	uppperKey := strings.ToUpper(o.SecretKey)
-	if err := o.API.SetSecret(pipelineIDNum, uppperKey, o.SecretValue, o.AllowInPR); err != nil {
+	if err := o.API.SetSecret(cmd.Context(), pipelineIDNum, uppperKey, o.SecretValue, o.AllowInPR); err != nil {
 		return fmt.Errorf("failed to set secret: %v", err)
 	}
 
@@ -6,7 +6,7 @@ import (
 	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
 )
 
-func NewCmdSet(config sdctl_context.SdctlConfig, api sdapi.SDAPI) *cobra.Command {
+func NewCmdSet(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "set",
 		Short: "set sdctl settings",
@@ -17,7 +17,13 @@ func NewCmdSet(config sdctl_context.SdctlConfig, api sdapi.SDAPI) *cobra.Command
 
 	cmd.AddCommand(
 		NewCmdSetToken(config),
+		NewCmdSetPipelineToken(config),
 		NewCmdSetAPI(config),
-		NewCmdSetJWT(config, api))
+		NewCmdSetUI(config, api),
+		NewCmdSetJWT(config, api),
+		NewCmdSetRetry(config),
+		NewCmdSetNetwork(config),
+		NewCmdSetDefaults(config),
+		NewCmdSetAlias(config))
 	return cmd
 }
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type SetAliasOption struct {
+	Config sdctl_context.SdctlConfig
+	Delete bool
+}
+
+func NewCmdSetAlias(config sdctl_context.SdctlConfig) *cobra.Command {
+	o := &SetAliasOption{
+		Config: config,
+	}
+	cmd := &cobra.Command{
+		Use:   "alias <name> <command line>",
+		Short: "set an alias expanded to sdctl arguments, such as 'sdctl set alias deploy-prod \"build org/app deploy-prod\"'",
+		Long: `set an alias expanded to sdctl arguments, shared by all contexts.
+'sdctl <name> [args...]' runs 'sdctl <command line> [args...]'. the command line is split into words like a shell,
+and may start with another alias. commands of sdctl cannot be overridden by aliases`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().BoolVarP(&o.Delete, "delete", "d", false, "delete the alias")
+
+	return cmd
+}
+
+func (o *SetAliasOption) Run(cmd *cobra.Command, args []string) error {
+	if (o.Delete && len(args) != 1) || (!o.Delete && len(args) != 2) {
+		return cmd.Help()
+	}
+	name := args[0]
+	commandLine := ""
+	if !o.Delete {
+		if isCommand(cmd.Root(), name) {
+			return fmt.Errorf("%s is a command of sdctl", name)
+		}
+		commandLine = args[1]
+		words, err := sdctl_context.SplitCommandLine(commandLine)
+		if err != nil {
+			return fmt.Errorf("command line is invalid: %v", err)
+		}
+		if len(words) == 0 {
+			return fmt.Errorf("command line is empty")
+		}
+	} else if _, ok := o.Config.Aliases[name]; !ok {
+		return fmt.Errorf("alias %s does not exist", name)
+	}
+
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	o.Config.SetAlias(name, commandLine)
+	if _, err := ExpandAlias(cmd.Root(), o.Config, []string{name}); err != nil {
+		return err
+	}
+	if err := o.Config.Update(configPATH); err != nil {
+		return err
+	}
+	if o.Delete {
+		fmt.Fprintf(os.Stdout, "alias %s is deleted\n", name)
+	} else {
+		fmt.Fprintf(os.Stdout, "alias %s is set: %s\n", name, commandLine)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type SetDefaultsOption struct {
+	Config    sdctl_context.SdctlConfig
+	Pipeline  string
+	StartFrom string
+	Output    outputFlag
+}
+
+func NewCmdSetDefaults(config sdctl_context.SdctlConfig) *cobra.Command {
+	o := &SetDefaultsOption{
+		Config: config,
+	}
+	cmd := &cobra.Command{
+		Use:   "defaults",
+		Short: "set defaults of commands in the current context. an empty value removes the default",
+		Long: `set defaults of commands in the current context. an empty value removes the default.
+the pipeline is used by 'build' and 'secret set', start_from by 'build', and the output by list commands`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Pipeline, "pipeline", "p", "", "pipeline ID or repository name")
+	cmd.Flags().StringVarP(&o.StartFrom, "start-from", "s", "", "job or trigger builds start from, such as ~commit")
+	cmd.Flags().StringVarP(&o.Output.Output, "output", "o", "", "output format of lists (table, json)")
+
+	return cmd
+}
+
+func (o *SetDefaultsOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 || cmd.Flags().NFlag() == 0 {
+		return cmd.Help()
+	}
+
+	defaults := currentDefaults(o.Config)
+	flags := cmd.Flags()
+	if flags.Changed("pipeline") {
+		defaults.Pipeline = o.Pipeline
+	}
+	if flags.Changed("start-from") {
+		defaults.StartFrom = o.StartFrom
+	}
+	if flags.Changed("output") {
+		if o.Output.Output != "" {
+			if err := o.Output.validate(); err != nil {
+				return err
+			}
+		}
+		defaults.Output = o.Output.Output
+	}
+
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	o.Config.SetDefaults(defaults)
+	if err := o.Config.Update(configPATH); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, "'defaults' is set")
+	return nil
+}
@@ -1,6 +1,10 @@
 package command
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
 	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
@@ -9,10 +13,10 @@ import (
 
 type SetJWTOption struct {
 	Config sdctl_context.SdctlConfig
-	API    sdapi.SDAPI
+	API    sdapi.Interface
 }
 
-func NewCmdSetJWT(config sdctl_context.SdctlConfig, api sdapi.SDAPI) *cobra.Command {
+func NewCmdSetJWT(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
 	o := &SetJWTOption{
 		Config: config,
 		API:    api,
@@ -28,7 +32,7 @@ func NewCmdSetJWT(config sdctl_context.SdctlConfig, api sdapi.SDAPI) *cobra.Comm
 }
 
 func (o *SetJWTOption) Run(cmd *cobra.Command, args []string) error {
-	token, err := o.API.GetJWT()
+	token, err := o.API.GetJWT(cmd.Context())
 	if err != nil {
 		return err
 	}
@@ -38,7 +42,20 @@ func (o *SetJWTOption) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	o.Config.SetParam(sdctl_context.SDJWTKey, token, nil)
-	o.Config.Update(configPATH)
+	// only the API is asked for the UI on login. 'set ui' also probes hosts guessed from the API URL, or sets it by hand
+	ui := ""
+	if o.Config.SdctlContexts[o.Config.CurrentContext].UIURL == "" {
+		if u, err := o.API.UIURLFromAPI(cmd.Context()); err == nil {
+			ui = u
+			o.Config.SetParam(sdctl_context.UIURLKey, ui, ioutil.Discard)
+		}
+	}
+	if err := o.Config.Update(configPATH); err != nil {
+		return err
+	}
 	println("Bearer " + token)
+	if ui != "" {
+		fmt.Fprintf(os.Stdout, "'ui' is discovered: %s\n", ui)
+	}
 	return nil
 }
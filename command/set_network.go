@@ -0,0 +1,104 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type SetNetworkOption struct {
+	Config             sdctl_context.SdctlConfig
+	Proxy              string
+	CAFile             string
+	InsecureSkipVerify bool
+	ClientCertFile     string
+	ClientKeyFile      string
+}
+
+func NewCmdSetNetwork(config sdctl_context.SdctlConfig) *cobra.Command {
+	o := &SetNetworkOption{
+		Config: config,
+	}
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "set the proxy and TLS of API requests of the current context",
+		Long: `set the proxy and TLS of API requests of the current context.
+only given flags are changed, and an empty value unsets the setting`,
+		Example: `  sdctl set network --proxy http://proxy.example.com:8080 --ca-file ./internal-ca.pem
+  sdctl set network --client-cert ./client.pem --client-key ./client-key.pem
+  sdctl set network --proxy ""`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Proxy, "proxy", "", "", "URL of the proxy. HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used when it is not set")
+	cmd.Flags().StringVarP(&o.CAFile, "ca-file", "", "", "PEM file of CA certificates trusted in addition to the system ones")
+	cmd.Flags().BoolVarP(&o.InsecureSkipVerify, "insecure-skip-verify", "", false, "skip verification of server certificates. use it only for testing")
+	cmd.Flags().StringVarP(&o.ClientCertFile, "client-cert", "", "", "PEM file of the client certificate for mutual TLS")
+	cmd.Flags().StringVarP(&o.ClientKeyFile, "client-key", "", "", "PEM file of the key of the client certificate")
+
+	return cmd
+}
+
+func (o *SetNetworkOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 || cmd.Flags().NFlag() == 0 {
+		return cmd.Help()
+	}
+
+	var network sdctl_context.NetworkConfig
+	if n := o.Config.SdctlContexts[o.Config.CurrentContext].Network; n != nil {
+		network = *n
+	}
+	flags := cmd.Flags()
+	if flags.Changed("proxy") {
+		network.Proxy = o.Proxy
+	}
+	if flags.Changed("insecure-skip-verify") {
+		network.InsecureSkipVerify = o.InsecureSkipVerify
+	}
+	// files are kept in absolute paths to work in any directory
+	for _, f := range []struct {
+		flag  string
+		value string
+		dest  *string
+	}{
+		{"ca-file", o.CAFile, &network.CAFile},
+		{"client-cert", o.ClientCertFile, &network.ClientCertFile},
+		{"client-key", o.ClientKeyFile, &network.ClientKeyFile},
+	} {
+		if !flags.Changed(f.flag) {
+			continue
+		}
+		*f.dest = f.value
+		if f.value == "" {
+			continue
+		}
+		abs, err := filepath.Abs(f.value)
+		if err != nil {
+			return err
+		}
+		*f.dest = abs
+	}
+	if _, err := sdapi.NewTransport(&network); err != nil {
+		return err
+	}
+
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	o.Config.SetNetwork(network)
+	if err := o.Config.Update(configPATH); err != nil {
+		return err
+	}
+	if network.InsecureSkipVerify {
+		fmt.Fprintln(os.Stderr, "[WARN] server certificates are not verified in this context")
+	}
+	fmt.Fprintln(os.Stdout, "'network' is set")
+	return nil
+}
@@ -0,0 +1,40 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type SetPipelineTokenOption struct {
+	Config sdctl_context.SdctlConfig
+}
+
+func NewCmdSetPipelineToken(config sdctl_context.SdctlConfig) *cobra.Command {
+	o := &SetPipelineTokenOption{
+		Config: config,
+	}
+	cmd := &cobra.Command{
+		Use:   "pipeline-token <token>",
+		Short: "set a pipeline token to make the current context pipeline-token-based",
+		Long: `set a pipeline token to make the current context pipeline-token-based.
+the pipeline token is exchanged for a JWT instead of the user token. set "" to switch back to the user token`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *SetPipelineTokenOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	o.Config.SetParam(sdctl_context.PipelineTokenKey, args[0], nil)
+	return o.Config.Update(configPATH)
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type SetRetryOption struct {
+	Config      sdctl_context.SdctlConfig
+	MaxAttempts int
+	MaxDelay    time.Duration
+}
+
+func NewCmdSetRetry(config sdctl_context.SdctlConfig) *cobra.Command {
+	o := &SetRetryOption{
+		Config: config,
+	}
+	cmd := &cobra.Command{
+		Use:   "retry",
+		Short: "set retries of idempotent API requests of the current context",
+		Long: `set retries of idempotent API requests of the current context.
+GET and PUT safe to repeat are retried on connection resets, 429, 502, 503 and 504 with jittered exponential backoff`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().IntVarP(&o.MaxAttempts, "max-attempts", "", sdapi.DefaultRetryPolicy.MaxAttempts, "maximum number of attempts including the first one. 1 disables retries")
+	cmd.Flags().DurationVarP(&o.MaxDelay, "max-delay", "", sdapi.DefaultRetryPolicy.MaxDelay, "maximum wait between attempts, also caps Retry-After")
+
+	return cmd
+}
+
+func (o *SetRetryOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmd.Help()
+	}
+	if o.MaxAttempts < 1 {
+		return fmt.Errorf("--max-attempts should be positive: %d", o.MaxAttempts)
+	}
+	if o.MaxDelay < 0 {
+		return fmt.Errorf("--max-delay should not be negative: %v", o.MaxDelay)
+	}
+
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	o.Config.SetRetry(sdctl_context.RetryConfig{
+		MaxAttempts: o.MaxAttempts,
+		MaxDelay:    o.MaxDelay.String(),
+	})
+	if err := o.Config.Update(configPATH); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "'retry' is set: %d attempts, up to %v between attempts\n", o.MaxAttempts, o.MaxDelay)
+	return nil
+}
@@ -34,6 +34,5 @@ func (o *SetTokenOption) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	o.Config.SetParam(sdctl_context.UserTokenKey, args[0], nil)
-	o.Config.Update(configPATH)
-	return nil
+	return o.Config.Update(configPATH)
 }
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type SetUIOption struct {
+	Config sdctl_context.SdctlConfig
+	API    sdapi.Interface
+}
+
+func NewCmdSetUI(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &SetUIOption{
+		Config: config,
+		API:    api,
+	}
+	cmd := &cobra.Command{
+		Use:   "ui [<url>]",
+		Short: "set your Screwdriver.cd UI url, discovered from the api url when it is omitted",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	return cmd
+}
+
+func (o *SetUIOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return cmd.Help()
+	}
+
+	var ui string
+	if len(args) == 1 {
+		ui = strings.TrimSuffix(args[0], "/")
+		if u, err := url.Parse(ui); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%q should be a URL such as https://cd.screwdriver.cd", args[0])
+		}
+	} else {
+		u, err := o.API.DiscoverUIURL(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("%v, set it by 'sdctl set ui <url>'", err)
+		}
+		ui = u
+		fmt.Fprintf(os.Stdout, "'ui' is discovered: %s\n", ui)
+	}
+
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	o.Config.SetParam(sdctl_context.UIURLKey, ui, nil)
+	return o.Config.Update(configPATH)
+}
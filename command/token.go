@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+func NewCmdToken(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "handle API tokens of the user or a pipeline",
+		Long: `handle API tokens of the user, or of a pipeline with --pipeline.
+a pipeline is an ID or a repository name such as org/repo`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewCmdTokenList(config, api))
+	cmd.AddCommand(NewCmdTokenCreate(config, api))
+	cmd.AddCommand(NewCmdTokenRefresh(config, api))
+	cmd.AddCommand(NewCmdTokenRevoke(api))
+
+	return cmd
+}
+
+// tokenPipelineID returns the ID of the pipeline, or 0 for user tokens when pipeline is empty
+func tokenPipelineID(ctx context.Context, api sdapi.Interface, pipeline string) (int, error) {
+	if pipeline == "" {
+		return 0, nil
+	}
+	return api.ResolvePipelineID(ctx, pipeline)
+}
+
+// findToken returns a token of the pipeline or the user by a name or an ID
+func findToken(ctx context.Context, api sdapi.Interface, pipelineID int, nameOrID string) (sdapi.Token, error) {
+	tokens, err := api.GetTokens(ctx, pipelineID)
+	if err != nil {
+		return sdapi.Token{}, err
+	}
+	id, err := strconv.Atoi(nameOrID)
+	for _, t := range tokens {
+		if (err == nil && t.ID == id) || t.Name == nameOrID {
+			return t, nil
+		}
+	}
+	return sdapi.Token{}, fmt.Errorf("token %s is %w", nameOrID, sdapi.ErrNotFound)
+}
+
+// printTokenValue shows the value of a token which the API never returns again
+func printTokenValue(token *sdapi.Token) {
+	fmt.Fprintln(os.Stdout, "the value is shown only once, keep it in a safe place:")
+	fmt.Fprintln(os.Stdout, token.Value)
+}
+
+// useToken stores the token in the current context, as a pipeline token when pipelineID is not 0.
+// a user token clears the pipeline token, which is used in preference to it.
+func useToken(config sdctl_context.SdctlConfig, pipelineID int, value string) error {
+	configPATH, err := util.ConfigPATH()
+	if err != nil {
+		return err
+	}
+	if pipelineID != 0 {
+		config.SetParam(sdctl_context.PipelineTokenKey, value, nil)
+		return config.Update(configPATH)
+	}
+	if config.SdctlContexts[config.CurrentContext].PipelineToken != "" {
+		config.SetParam(sdctl_context.PipelineTokenKey, "", ioutil.Discard)
+		fmt.Fprintln(os.Stdout, "the pipeline token is cleared to use the user token")
+	}
+	config.SetParam(sdctl_context.UserTokenKey, value, nil)
+	return config.Update(configPATH)
+}
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+type TokenCreateOption struct {
+	Config      sdctl_context.SdctlConfig
+	API         sdapi.Interface
+	Pipeline    string
+	Description string
+	Use         bool
+}
+
+func NewCmdTokenCreate(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &TokenCreateOption{
+		Config: config,
+		API:    api,
+	}
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "create a token and show its value",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Pipeline, "pipeline", "p", "", "handle tokens of the pipeline instead of the user")
+	cmd.Flags().StringVarP(&o.Description, "description", "d", "", "token description")
+	cmd.Flags().BoolVarP(&o.Use, "use", "", false, "store the token in the current context. a pipeline token makes the context pipeline-token-based")
+
+	return cmd
+}
+
+func (o *TokenCreateOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+	pipelineID, err := tokenPipelineID(cmd.Context(), o.API, o.Pipeline)
+	if err != nil {
+		return err
+	}
+
+	token, err := o.API.CreateToken(cmd.Context(), pipelineID, sdapi.TokenRequest{
+		Name:        args[0],
+		Description: o.Description,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Successfully created a token ID %v\n", token.ID)
+	printTokenValue(token)
+
+	if o.Use {
+		return useToken(o.Config, pipelineID, token.Value)
+	}
+	return nil
+}
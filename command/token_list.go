@@ -0,0 +1,74 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+type TokenListOption struct {
+	API      sdapi.Interface
+	Pipeline string
+	List     listFlags
+	Output   outputFlag
+}
+
+func NewCmdTokenList(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &TokenListOption{
+		API: api,
+	}
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "get a list of tokens",
+		Aliases: []string{"ls"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Pipeline, "pipeline", "p", "", "handle tokens of the pipeline instead of the user")
+	addListFlags(cmd, &o.List)
+	addOutputFlag(cmd, &o.Output, config)
+
+	return cmd
+}
+
+func (o *TokenListOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmd.Help()
+	}
+	pipelineID, err := tokenPipelineID(cmd.Context(), o.API, o.Pipeline)
+	if err != nil {
+		return err
+	}
+
+	opts, err := o.List.options()
+	if err != nil {
+		return err
+	}
+	if err := o.Output.validate(); err != nil {
+		return err
+	}
+	tokens, err := o.API.ListTokens(cmd.Context(), pipelineID, opts)
+	if err != nil {
+		return err
+	}
+	if o.Output.json() {
+		return printJSON(tokens)
+	}
+	o.printColumn("ID", "Name", "LastUsed", "Description")
+	for _, t := range tokens {
+		lastUsed := t.LastUsed
+		if lastUsed == "" {
+			lastUsed = "-"
+		}
+		o.printColumn(t.ID, t.Name, lastUsed, t.Description)
+	}
+	return nil
+}
+
+func (o *TokenListOption) printColumn(id, name, lastUsed, description interface{}) {
+	fmt.Fprintf(os.Stdout, "%-8v%-24v%-28v%v\n", id, name, lastUsed, description)
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+type TokenRefreshOption struct {
+	Config   sdctl_context.SdctlConfig
+	API      sdapi.Interface
+	Pipeline string
+	Use      bool
+}
+
+func NewCmdTokenRefresh(config sdctl_context.SdctlConfig, api sdapi.Interface) *cobra.Command {
+	o := &TokenRefreshOption{
+		Config: config,
+		API:    api,
+	}
+	cmd := &cobra.Command{
+		Use:   "refresh <name or id>",
+		Short: "regenerate the value of a token, the old value stops working",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Pipeline, "pipeline", "p", "", "handle tokens of the pipeline instead of the user")
+	cmd.Flags().BoolVarP(&o.Use, "use", "", false, "store the token in the current context. a pipeline token makes the context pipeline-token-based")
+
+	return cmd
+}
+
+func (o *TokenRefreshOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+	pipelineID, err := tokenPipelineID(cmd.Context(), o.API, o.Pipeline)
+	if err != nil {
+		return err
+	}
+	t, err := findToken(cmd.Context(), o.API, pipelineID, args[0])
+	if err != nil {
+		return err
+	}
+
+	token, err := o.API.RefreshToken(cmd.Context(), pipelineID, t.ID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Successfully refreshed a token ID %v\n", token.ID)
+	printTokenValue(token)
+
+	if o.Use {
+		return useToken(o.Config, pipelineID, token.Value)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+type TokenRevokeOption struct {
+	API      sdapi.Interface
+	Pipeline string
+}
+
+func NewCmdTokenRevoke(api sdapi.Interface) *cobra.Command {
+	o := &TokenRevokeOption{
+		API: api,
+	}
+	cmd := &cobra.Command{
+		Use:     "revoke <name or id...>",
+		Short:   "revoke tokens",
+		Aliases: []string{"rm"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Pipeline, "pipeline", "p", "", "handle tokens of the pipeline instead of the user")
+
+	return cmd
+}
+
+func (o *TokenRevokeOption) Run(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+	pipelineID, err := tokenPipelineID(cmd.Context(), o.API, o.Pipeline)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range args {
+		t, err := findToken(cmd.Context(), o.API, pipelineID, a)
+		if err != nil {
+			return err
+		}
+		if err := o.API.RevokeToken(cmd.Context(), pipelineID, t.ID); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Successfully revoked a token ID %v\n", t.ID)
+	}
+	return nil
+}
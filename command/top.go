@@ -0,0 +1,119 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/top"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type TopOption struct {
+	API         sdapi.Interface
+	Collections []string
+	Interval    time.Duration
+	Concurrency int
+}
+
+func NewCmdTop(api sdapi.Interface) *cobra.Command {
+	o := &TopOption{
+		API: api,
+	}
+	cmd := &cobra.Command{
+		Use:   "top [pipeline...]",
+		Short: "show live status of jobs of pipelines, default to pipelines in your collections",
+		Long: `show live status of jobs of pipelines, default to pipelines in your collections.
+pipelines are IDs or repository names such as org/repo.
+
+keys:
+  j/k, up/down  move
+  l             show logs of the failed or running step of the build
+  r             restart the job
+  s             stop the build
+  o             open the build page in a browser
+  R             refresh now
+  q, Ctrl-C     quit`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringSliceVarP(&o.Collections, "collection", "C", nil, "show pipelines of collections by names or IDs")
+	cmd.Flags().DurationVarP(&o.Interval, "interval", "i", 10*time.Second, "refresh interval")
+	cmd.Flags().IntVarP(&o.Concurrency, "concurrency", "c", 4, "number of pipelines polled in parallel")
+
+	return cmd
+}
+
+func (o *TopOption) Run(cmd *cobra.Command, args []string) error {
+	if o.Interval < time.Second {
+		return fmt.Errorf("--interval should be 1s or longer: %v", o.Interval)
+	}
+	if o.Concurrency < 1 {
+		return fmt.Errorf("--concurrency should be positive: %d", o.Concurrency)
+	}
+
+	pipelines, err := o.pipelines(cmd.Context(), args)
+	if err != nil {
+		return err
+	}
+	if len(pipelines) == 0 {
+		return fmt.Errorf("no pipelines to show")
+	}
+
+	return top.Run(top.Options{
+		Context:     cmd.Context(),
+		API:         o.API,
+		Pipelines:   pipelines,
+		Interval:    o.Interval,
+		Concurrency: o.Concurrency,
+		Open:        util.OpenBrowser,
+	})
+}
+
+// pipelines returns pipelines of the arguments and the collections without duplicates
+func (o *TopOption) pipelines(ctx context.Context, args []string) ([]sdapi.Pipeline, error) {
+	var pipelines []sdapi.Pipeline
+	seen := make(map[int]bool)
+	add := func(p sdapi.Pipeline) {
+		if !seen[p.ID] {
+			seen[p.ID] = true
+			pipelines = append(pipelines, p)
+		}
+	}
+
+	ids, err := resolvePipelineIDs(ctx, o.API, args)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		p, err := o.API.GetPipeline(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		add(*p)
+	}
+
+	collections := o.Collections
+	if len(args) == 0 && len(collections) == 0 {
+		all, err := o.API.GetCollections(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range all {
+			collections = append(collections, fmt.Sprint(c.ID))
+		}
+	}
+	for _, name := range collections {
+		c, err := o.API.FindCollection(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range c.Pipelines {
+			add(p)
+		}
+	}
+	return pipelines, nil
+}
@@ -1,43 +1,113 @@
 package command
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/workflow"
 	"github.com/tk3fftk/sdctl/util"
+	"gopkg.in/yaml.v2"
 )
 
 type ValidateOption struct {
-	API sdapi.SDAPI
+	API              sdapi.Interface
+	PipelineFilePATH []string
+	ValidatedOutput  bool
+	Concurrency      int
+	Graph            string
+	Job              string
 }
 
-var pipelineFilePATH string
-var validatedOutput bool
-
-func NewCmdValidate(api sdapi.SDAPI) *cobra.Command {
+func NewCmdValidate(api sdapi.Interface) *cobra.Command {
 	o := &ValidateOption{
 		API: api,
 	}
 	cmd := &cobra.Command{
 		Use:     "validate",
 		Short:   "validate your screwdriver.yaml, default to screwdriver.yaml",
+		Long:    "validate your screwdriver.yaml, default to screwdriver.yaml. -f can be repeated and accepts globs such as '**/screwdriver.yaml'",
 		Aliases: []string{"v"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.Run(cmd, args)
 		},
 	}
-	cmd.Flags().StringVarP(&pipelineFilePATH, "file", "f", "screwdriver.yaml", "specify pipeline file path")
-	cmd.Flags().BoolVarP(&validatedOutput, "output", "o", false, "print velidator result")
+	cmd.Flags().StringSliceVarP(&o.PipelineFilePATH, "file", "f", []string{"screwdriver.yaml"}, "specify pipeline file paths or globs")
+	cmd.Flags().BoolVarP(&o.ValidatedOutput, "output", "o", false, "print velidator result")
+	cmd.Flags().IntVarP(&o.Concurrency, "concurrency", "c", 4, "number of files validated concurrently")
+	cmd.Flags().StringVarP(&o.Graph, "graph", "g", "", fmt.Sprintf("render the resolved workflow graph (%s)", strings.Join(workflow.Formats, ", ")))
+	cmd.Flags().Lookup("graph").NoOptDefVal = workflow.FormatASCII
+	cmd.Flags().StringVarP(&o.Job, "job", "j", "", "show the job definition merged with templates and shared settings")
+
+	cmd.AddCommand(NewCmdValidateDiff(api))
 
 	return cmd
 }
 
 func (o *ValidateOption) Run(cmd *cobra.Command, args []string) error {
-	yaml, err := util.ReadYaml(pipelineFilePATH)
+	if o.Graph != "" || o.Job != "" {
+		return o.runExpanded(cmd.Context())
+	}
+
+	return validateFiles(o.PipelineFilePATH, o.Concurrency, func(yamlStr string, w io.Writer) error {
+		vr, err := o.API.ValidatePipelineRaw(cmd.Context(), yamlStr)
+		if err != nil {
+			return err
+		}
+		if o.ValidatedOutput {
+			return yaml.NewEncoder(w).Encode(vr)
+		}
+		fmt.Fprintln(w, "Your screwdriver.yaml is valid🙆")
+		return nil
+	})
+}
+
+func (o *ValidateOption) runExpanded(ctx context.Context) error {
+	files, err := util.ExpandGlobs(o.PipelineFilePATH)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return errors.New("--graph and --job accept only one file")
+	}
+
+	yamlStr, err := util.ReadYaml(files[0])
 	if err != nil {
 		return err
 	}
-	if err := o.API.Validator(yaml, false, validatedOutput); err != nil {
+	// the raw response keeps fields of jobs which are not modeled such as cache and provider
+	vr, err := o.API.ValidatePipelineRaw(ctx, yamlStr)
+	if err != nil {
 		return err
 	}
+
+	if o.Graph != "" {
+		pipeline, err := vr.Pipeline()
+		if err != nil {
+			return err
+		}
+		if err := workflow.New(pipeline.WorkflowGraph).Render(os.Stdout, o.Graph); err != nil {
+			return err
+		}
+	}
+	if o.Job != "" {
+		jobs, ok := vr.Job(o.Job)
+		if !ok {
+			return fmt.Errorf("job %s is not found in %s", o.Job, files[0])
+		}
+		// a job has multiple configs only when it uses matrix
+		var out interface{} = jobs[0]
+		if len(jobs) > 1 {
+			out = jobs
+		}
+		if err := yaml.NewEncoder(os.Stdout).Encode(out); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/workflow"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+type ValidateDiffOption struct {
+	API              sdapi.Interface
+	Base             string
+	PipelineFilePATH string
+}
+
+func NewCmdValidateDiff(api sdapi.Interface) *cobra.Command {
+	o := &ValidateDiffOption{
+		API: api,
+	}
+	cmd := &cobra.Command{
+		Use:   "diff [<base_file> <head_file>]",
+		Short: "show the difference of the effective configuration between two versions of a pipeline",
+		Long: `show the difference of the effective configuration between two versions of a pipeline.
+both versions are expanded by the validator, and added or removed jobs, requires, images, steps,
+environment, secrets, annotations and settings are compared.
+specify two files, or --base to compare the file at a git revision with the working tree`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&o.Base, "base", "b", "", "git revision to compare with the working tree")
+	cmd.Flags().StringVarP(&o.PipelineFilePATH, "file", "f", "screwdriver.yaml", "specify pipeline file path used with --base")
+
+	return cmd
+}
+
+func (o *ValidateDiffOption) Run(cmd *cobra.Command, args []string) error {
+	var baseYaml, headYaml string
+	var err error
+
+	switch {
+	case o.Base != "" && len(args) == 0:
+		if baseYaml, err = util.ReadYamlAtRevision(o.Base, o.PipelineFilePATH); err != nil {
+			return err
+		}
+		if headYaml, err = util.ReadYaml(o.PipelineFilePATH); err != nil {
+			return err
+		}
+	case o.Base == "" && len(args) == 2:
+		if baseYaml, err = util.ReadYaml(args[0]); err != nil {
+			return err
+		}
+		if headYaml, err = util.ReadYaml(args[1]); err != nil {
+			return err
+		}
+	case o.Base != "":
+		return errors.New("--base can not be used with file arguments")
+	default:
+		return cmd.Help()
+	}
+
+	base, err := o.API.ValidatePipeline(cmd.Context(), baseYaml)
+	if err != nil {
+		return fmt.Errorf("base is invalid: %v", err)
+	}
+	head, err := o.API.ValidatePipeline(cmd.Context(), headYaml)
+	if err != nil {
+		return fmt.Errorf("head is invalid: %v", err)
+	}
+
+	workflow.PrintDiff(os.Stdout, workflow.Diff(base, head))
+	return nil
+}
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/tk3fftk/sdctl/util"
+)
+
+// validateFunc validates a quoted yaml string and writes the result to w
+type validateFunc func(yaml string, w io.Writer) error
+
+type validateResult struct {
+	path   string
+	output bytes.Buffer
+	err    error
+}
+
+// validateFiles validates files matched with patterns by a bounded worker pool.
+// It prints each result in the order of files and a summary when there are multiple files.
+func validateFiles(patterns []string, concurrency int, validate validateFunc) error {
+	files, err := util.ExpandGlobs(patterns)
+	if err != nil {
+		return err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]*validateResult, len(files))
+	for i, f := range files {
+		results[i] = &validateResult{path: f}
+	}
+
+	queue := make(chan *validateResult)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for r := range queue {
+				yaml, err := util.ReadYaml(r.path)
+				if err != nil {
+					r.err = err
+					continue
+				}
+				r.err = validate(yaml, &r.output)
+			}
+		}()
+	}
+	for _, r := range results {
+		queue <- r
+	}
+	close(queue)
+	wg.Wait()
+
+	if len(results) == 1 {
+		r := results[0]
+		io.Copy(os.Stdout, &r.output)
+		return r.err
+	}
+
+	for _, r := range results {
+		if r.output.Len() == 0 {
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "==> %s <==\n", r.path)
+		io.Copy(os.Stdout, &r.output)
+		fmt.Fprintln(os.Stdout)
+	}
+
+	failed := 0
+	printValidateColumn("Result", "File", "")
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			printValidateColumn("FAIL", r.path, r.err)
+			continue
+		}
+		printValidateColumn("PASS", r.path, "")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files are invalid", failed, len(results))
+	}
+	return nil
+}
+
+func printValidateColumn(result, path, msg interface{}) {
+	fmt.Fprintf(os.Stdout, "%-8v%-60v%v\n", result, path, msg)
+}
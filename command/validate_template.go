@@ -1,40 +1,49 @@
 package command
 
 import (
+	"fmt"
+	"io"
+
 	"github.com/spf13/cobra"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
-	"github.com/tk3fftk/sdctl/util"
 )
 
 type ValidateTemplateOption struct {
-	API sdapi.SDAPI
+	API              sdapi.Interface
+	TemplateFilePATH []string
+	Concurrency      int
 }
 
-var templateFilePATH string
-
-func NewCmdValidateTemplate(api sdapi.SDAPI) *cobra.Command {
+func NewCmdValidateTemplate(api sdapi.Interface) *cobra.Command {
 	o := &ValidateTemplateOption{
 		API: api,
 	}
 	cmd := &cobra.Command{
 		Use:     "validate-template",
 		Short:   "validate your sd-template.yaml, default to sd-template.yaml",
+		Long:    "validate your sd-template.yaml, default to sd-template.yaml. -f can be repeated and accepts globs such as '**/sd-template.yaml'",
 		Aliases: []string{"vt"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.Run(cmd, args)
 		},
 	}
-	cmd.Flags().StringVarP(&templateFilePATH, "file", "f", "sd-template.yaml", "specify template file path")
+	cmd.Flags().StringSliceVarP(&o.TemplateFilePATH, "file", "f", []string{"sd-template.yaml"}, "specify template file paths or globs")
+	cmd.Flags().IntVarP(&o.Concurrency, "concurrency", "c", 4, "number of files validated concurrently")
 	return cmd
 }
 
 func (o *ValidateTemplateOption) Run(cmd *cobra.Command, args []string) error {
-	yaml, err := util.ReadYaml(templateFilePATH)
-	if err != nil {
-		return err
-	}
-	if err := o.API.ValidatorTemplate(yaml, false); err != nil {
-		return err
-	}
-	return nil
+	return validateFiles(o.TemplateFilePATH, o.Concurrency, func(yaml string, w io.Writer) error {
+		tvr, err := o.API.ValidateTemplate(cmd.Context(), yaml)
+		if tvr != nil {
+			for _, e := range tvr.Errors {
+				fmt.Fprintf(w, "%v\n", e.Message)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "Your template is valid🙆")
+		return nil
+	})
 }
@@ -5,10 +5,12 @@ go 1.17
 require (
 	github.com/google/go-cmp v0.5.5
 	github.com/spf13/cobra v1.2.1
+	golang.org/x/term v0.1.0
 	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1 // indirect
 )
@@ -0,0 +1,104 @@
+// Package completion caches candidates of shell completion on disk, so that completion stays fast
+// while candidates such as pipelines and jobs come from the API.
+package completion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTTL is how long candidates are cached
+const DefaultTTL = time.Minute
+
+// Cache stores candidates by key as files in Dir. it is safe for concurrent use by processes.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+	// Now returns the current time, default to time.Now
+	Now func() time.Time
+}
+
+type entry struct {
+	Key        string    `json:"key"`
+	Time       time.Time `json:"time"`
+	Candidates []string  `json:"candidates"`
+}
+
+// DefaultDir returns the directory of the cache in the user cache directory, such as ~/.cache/sdctl/completion
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sdctl", "completion"), nil
+}
+
+// NewCache returns a cache in dir with DefaultTTL
+func NewCache(dir string) *Cache {
+	return &Cache{Dir: dir, TTL: DefaultTTL}
+}
+
+// Get returns the candidates of key cached within TTL, or calls fetch and caches its candidates.
+// failures of the cache are ignored since it only speeds up completion.
+func (c *Cache) Get(key string, fetch func() ([]string, error)) ([]string, error) {
+	path := c.path(key)
+	if b, err := ioutil.ReadFile(path); err == nil {
+		var e entry
+		if json.Unmarshal(b, &e) == nil && e.Key == key {
+			if age := c.now().Sub(e.Time); age >= 0 && age < c.TTL {
+				return e.Candidates, nil
+			}
+		}
+	}
+
+	candidates, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.put(path, entry{Key: key, Time: c.now(), Candidates: candidates})
+	return candidates, nil
+}
+
+// Clear removes all cached candidates
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.Dir)
+}
+
+func (c *Cache) put(path string, e entry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return
+	}
+	// a temporary file and rename keep other processes from reading a partial file
+	f, err := ioutil.TempFile(c.Dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(b)
+	if cerr := f.Close(); err != nil || cerr != nil {
+		return
+	}
+	os.Rename(f.Name(), path)
+}
+
+// path hashes key, which may contain any characters such as slashes of repository names
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *Cache) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,62 @@
+package completion
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCache_Get(t *testing.T) {
+	now := time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC)
+	c := NewCache(filepath.Join(t.TempDir(), "completion"))
+	c.Now = func() time.Time { return now }
+
+	calls := 0
+	fetch := func(candidates ...string) func() ([]string, error) {
+		return func() ([]string, error) {
+			calls++
+			return candidates, nil
+		}
+	}
+
+	cases := []struct {
+		name     string
+		key      string
+		elapsed  time.Duration
+		fetch    func() ([]string, error)
+		expected []string
+		calls    int
+	}{
+		{"fetched at first", "pipelines\x00org/", 0, fetch("org/app", "org/web"), []string{"org/app", "org/web"}, 1},
+		{"cached", "pipelines\x00org/", 30 * time.Second, fetch("org/new"), []string{"org/app", "org/web"}, 1},
+		{"other key", "jobs\x001", 30 * time.Second, fetch("main"), []string{"main"}, 2},
+		{"expired", "pipelines\x00org/", DefaultTTL, fetch("org/new"), []string{"org/new"}, 3},
+	}
+	for _, v := range cases {
+		now = now.Add(v.elapsed)
+		actual, err := c.Get(v.key, v.fetch)
+		if err != nil {
+			t.Fatalf("%s: %v", v.name, err)
+		}
+		if diff := cmp.Diff(v.expected, actual); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", v.name, diff)
+		}
+		if calls != v.calls {
+			t.Errorf("%s: fetch should be called %d times, but actual is %d", v.name, v.calls, calls)
+		}
+	}
+
+	if _, err := c.Get("failure", func() ([]string, error) { return nil, errors.New("failure") }); err == nil {
+		t.Errorf("error of fetch should be returned")
+	}
+	if err := c.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.Dir); !os.IsNotExist(err) {
+		t.Errorf("cache should be cleared: %v", err)
+	}
+}
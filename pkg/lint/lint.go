@@ -0,0 +1,165 @@
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// severities of rules
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityOff     = "off"
+)
+
+// DefaultConfigPATH is the lint config file looked up in the working directory
+const DefaultConfigPATH = ".sdctl-lint.yaml"
+
+// Rule checks a pipeline against a convention
+type Rule interface {
+	Name() string
+	Description() string
+	DefaultSeverity() string
+	Check(p *Pipeline) []Issue
+}
+
+// Issue is a violation of a rule
+type Issue struct {
+	Rule     string
+	Severity string
+	Line     int
+	Message  string
+}
+
+// Config configures severities of rules
+type Config struct {
+	Rules map[string]string `yaml:"rules"`
+}
+
+// LoadConfig loads a lint config. A missing file results in the default config.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	f, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.UnmarshalStrict(f, &config); err != nil {
+		return config, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// Linter runs rules on pipelines
+type Linter struct {
+	rules    []Rule
+	severity map[string]string
+}
+
+// New creates a Linter with rules configured by config
+func New(config Config, rules ...Rule) (*Linter, error) {
+	l := &Linter{
+		rules:    rules,
+		severity: make(map[string]string),
+	}
+	known := make(map[string]bool)
+	for _, r := range rules {
+		known[r.Name()] = true
+		l.severity[r.Name()] = r.DefaultSeverity()
+	}
+	for name, severity := range config.Rules {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown rule %s", name)
+		}
+		switch severity {
+		case SeverityError, SeverityWarning, SeverityOff:
+		default:
+			return nil, fmt.Errorf("severity of %s should be %s, %s or %s: %s", name, SeverityError, SeverityWarning, SeverityOff, severity)
+		}
+		l.severity[name] = severity
+	}
+	return l, nil
+}
+
+// Rules returns rules of the linter
+func (l *Linter) Rules() []Rule {
+	return l.rules
+}
+
+// Severity returns the configured severity of a rule
+func (l *Linter) Severity(name string) string {
+	return l.severity[name]
+}
+
+// Lint checks a screwdriver.yaml and returns issues ordered by line
+func (l *Linter) Lint(src []byte) ([]Issue, error) {
+	p, err := ParsePipeline(src)
+	if err != nil {
+		return nil, err
+	}
+	ignored := ignoredRules(src)
+
+	var issues []Issue
+	for _, r := range l.rules {
+		severity := l.severity[r.Name()]
+		if severity == SeverityOff {
+			continue
+		}
+		for _, i := range r.Check(p) {
+			if ignored[i.Line][r.Name()] || ignored[i.Line][""] {
+				continue
+			}
+			i.Rule = r.Name()
+			i.Severity = severity
+			issues = append(issues, i)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+var ignoreComment = regexp.MustCompile(`#\s*sdctl:ignore\b(.*)$`)
+
+// ignoredRules collects "# sdctl:ignore rule1,rule2" comments by line.
+// A comment on its own line applies to the next line, and no rule name means all rules.
+func ignoredRules(src []byte) map[int]map[string]bool {
+	ignored := make(map[int]map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		m := ignoreComment.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		target := lineNo
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			target++
+		}
+		if ignored[target] == nil {
+			ignored[target] = make(map[string]bool)
+		}
+		names := strings.FieldsFunc(m[1], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(names) == 0 {
+			ignored[target][""] = true
+		}
+		for _, n := range names {
+			ignored[target][n] = true
+		}
+	}
+	return ignored
+}
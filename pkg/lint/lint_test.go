@@ -0,0 +1,198 @@
+package lint
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLinter_Lint(t *testing.T) {
+	src, err := ioutil.ReadFile("testdata/screwdriver.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		config   Config
+		expected []Issue
+	}{
+		"default rules": {
+			Config{},
+			[]Issue{
+				{"unpinned-image", SeverityWarning, 2, "image node:latest uses the latest tag"},
+				{"unpinned-image", SeverityWarning, 16, "image node has no tag"},
+				{"unpinned-template", SeverityWarning, 17, "template sd/publish of job publish is not pinned to a version"},
+				{"unreachable-job", SeverityError, 24, "job deploy can not be reached from any trigger"},
+				{"undeclared-secret", SeverityError, 29, "secret GITHUB_TOKEN is used in job deploy but not declared in its secrets"},
+				{"duplicate-steps", SeverityWarning, 30, "step install of job deploy duplicates step install of job main"},
+				{"missing-requires", SeverityWarning, 31, "job manual has no requires"},
+			},
+		},
+		"configured severities": {
+			Config{Rules: map[string]string{
+				"unpinned-image":    SeverityOff,
+				"unpinned-template": SeverityOff,
+				"duplicate-steps":   SeverityOff,
+				"missing-requires":  SeverityError,
+			}},
+			[]Issue{
+				{"unreachable-job", SeverityError, 24, "job deploy can not be reached from any trigger"},
+				{"undeclared-secret", SeverityError, 29, "secret GITHUB_TOKEN is used in job deploy but not declared in its secrets"},
+				{"missing-requires", SeverityError, 31, "job manual has no requires"},
+			},
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			l, err := New(v.config, DefaultRules()...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := l.Lint(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(v.expected, actual); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := map[string]struct {
+		config    Config
+		expectErr bool
+	}{
+		"valid config": {
+			Config{Rules: map[string]string{"unpinned-image": SeverityError}},
+			false,
+		},
+		"unknown rule": {
+			Config{Rules: map[string]string{"unknown": SeverityError}},
+			true,
+		},
+		"unknown severity": {
+			Config{Rules: map[string]string{"unpinned-image": "fatal"}},
+			true,
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			_, err := New(v.config, DefaultRules()...)
+			if (err != nil) != v.expectErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestIndexLines(t *testing.T) {
+	src := []byte(`jobs:
+  main:
+    steps:
+    - a: |
+        b: c
+    - d: e
+    secrets: [A]
+`)
+	expected := map[string]int{
+		"jobs":                 1,
+		"jobs.main":            2,
+		"jobs.main.steps":      3,
+		"jobs.main.steps[0]":   4,
+		"jobs.main.steps[0].a": 4,
+		"jobs.main.steps[1]":   6,
+		"jobs.main.steps[1].d": 6,
+		"jobs.main.secrets":    7,
+	}
+	if diff := cmp.Diff(expected, indexLines(src)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUndeclaredSecret(t *testing.T) {
+	cases := map[string]struct {
+		src      string
+		expected []string
+	}{
+		"secret declared by another job": {
+			`jobs:
+  main:
+    secrets: [TOKEN]
+    steps:
+      - a: echo $TOKEN
+  deploy:
+    steps:
+      - a: echo ${TOKEN}
+`,
+			[]string{"secret TOKEN is used in job deploy but not declared in its secrets"},
+		},
+		"variable never declared": {
+			`jobs:
+  main:
+    steps:
+      - a: curl -H "$API_KEY" example.com
+      - b: echo $API_KEY $OTHER
+`,
+			[]string{
+				"variable API_KEY is used in job main but not declared in secrets or environment",
+				"variable API_KEY is used in job main but not declared in secrets or environment",
+				"variable OTHER is used in job main but not declared in secrets or environment",
+			},
+		},
+		"declared and provided variables": {
+			`shared:
+  environment:
+    SHARED: a
+  secrets: [SHARED_SECRET]
+jobs:
+  main:
+    environment:
+      LOCAL: b
+    secrets: [TOKEN]
+    steps:
+      - set: export VERSION=$(cat VERSION); COUNT=1
+      - loop: for f in *; do echo $f; done
+      - read: read -r NAME < name.txt
+      - a: echo $SHARED $SHARED_SECRET $LOCAL $TOKEN $VERSION $COUNT $NAME
+      - b: echo $SD_BUILD_ID $HOME $PATH $GIT_BRANCH ${OPTIONAL:-default}
+`,
+			nil,
+		},
+		"steps of templates": {
+			`jobs:
+  main:
+    template: sd/publish@1
+    steps:
+      - a: echo $FROM_TEMPLATE
+`,
+			nil,
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			p, err := ParsePipeline([]byte(v.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual []string
+			for _, issue := range (undeclaredSecret{}).Check(p) {
+				actual = append(actual, issue.Message)
+			}
+			if diff := cmp.Diff(v.expected, actual); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Pipeline is a screwdriver.yaml as written by users, before templates and shared settings are applied
+type Pipeline struct {
+	Shared Job            `yaml:"shared"`
+	Jobs   map[string]Job `yaml:"jobs"`
+
+	lines map[string]int
+}
+
+// Job is a job definition in a screwdriver.yaml
+type Job struct {
+	Image       string              `yaml:"image"`
+	Template    string              `yaml:"template"`
+	Requires    *stringList         `yaml:"requires"`
+	Steps       []map[string]string `yaml:"steps"`
+	Secrets     []string            `yaml:"secrets"`
+	Environment map[string]string   `yaml:"environment"`
+}
+
+// stringList accepts both a string and a list of strings
+type stringList []string
+
+func (s *stringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*s = list
+		return nil
+	}
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	*s = []string{str}
+	return nil
+}
+
+// ParsePipeline parses a screwdriver.yaml
+func ParsePipeline(src []byte) (*Pipeline, error) {
+	p := new(Pipeline)
+	if err := yaml.Unmarshal(src, p); err != nil {
+		return nil, err
+	}
+	p.lines = indexLines(src)
+	return p, nil
+}
+
+// Line returns the line number of a path such as "jobs.main.steps[0]", or 0 if it is not found
+func (p *Pipeline) Line(path string) int {
+	return p.lines[path]
+}
+
+// StepName returns the name of a step
+func StepName(step map[string]string) string {
+	for k := range step {
+		return k
+	}
+	return ""
+}
+
+// indexLines maps paths of keys and sequence items to their line numbers.
+// It understands only block style used in screwdriver.yaml, which is enough to point at issues.
+func indexLines(src []byte) map[string]int {
+	type frame struct {
+		indent int
+		path   string
+		item   bool
+	}
+
+	index := make(map[string]int)
+	items := make(map[string]int)
+	var stack []frame
+	blockIndent := -1
+
+	top := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return stack[len(stack)-1].path
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+
+		// skip contents of block scalars such as "|"
+		if blockIndent >= 0 {
+			if content == "" || indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+
+		for content == "-" || strings.HasPrefix(content, "- ") {
+			for len(stack) > 0 {
+				f := stack[len(stack)-1]
+				if f.indent < indent || (f.indent == indent && !f.item) {
+					break
+				}
+				stack = stack[:len(stack)-1]
+			}
+			parent := top()
+			path := fmt.Sprintf("%s[%d]", parent, items[parent])
+			items[parent]++
+			index[path] = lineNo
+			stack = append(stack, frame{indent: indent, path: path, item: true})
+
+			rest := strings.TrimPrefix(content, "-")
+			content = strings.TrimLeft(rest, " ")
+			indent += 1 + len(rest) - len(content)
+		}
+
+		key, value, ok := splitKey(content)
+		if !ok {
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		path := key
+		if parent := top(); parent != "" {
+			path = parent + "." + key
+		}
+		index[path] = lineNo
+		stack = append(stack, frame{indent: indent, path: path})
+
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockIndent = indent
+		}
+	}
+	return index
+}
+
+// splitKey splits "key: value" and strips quotes and comments
+func splitKey(content string) (key, value string, ok bool) {
+	if strings.HasPrefix(content, "{") || strings.HasPrefix(content, "[") {
+		return "", "", false
+	}
+	i := strings.Index(content, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(stripComment(content), ":") {
+			return "", "", false
+		}
+		content = stripComment(content)
+		i = len(content) - 1
+	}
+	key = strings.Trim(strings.TrimSpace(content[:i]), `"'`)
+	value = strings.TrimSpace(stripComment(content[i+1:]))
+	return key, value, true
+}
+
+func stripComment(s string) string {
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
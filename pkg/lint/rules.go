@@ -0,0 +1,293 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/workflow"
+)
+
+// DefaultRules returns built-in rules
+func DefaultRules() []Rule {
+	return []Rule{
+		unpinnedImage{},
+		unpinnedTemplate{},
+		undeclaredSecret{},
+		unreachableJob{},
+		duplicateSteps{},
+		missingRequires{},
+	}
+}
+
+// jobNames returns job names in the order of the file
+func jobNames(p *Pipeline) []string {
+	var names []string
+	for name := range p.Jobs {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		li, lj := p.Line("jobs."+names[i]), p.Line("jobs."+names[j])
+		if li != lj {
+			return li < lj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+type unpinnedImage struct{}
+
+func (unpinnedImage) Name() string { return "unpinned-image" }
+func (unpinnedImage) Description() string {
+	return "images should be pinned to a tag other than latest or a digest"
+}
+func (unpinnedImage) DefaultSeverity() string { return SeverityWarning }
+
+func (r unpinnedImage) Check(p *Pipeline) []Issue {
+	var issues []Issue
+	check := func(image, path string) {
+		if image == "" || strings.Contains(image, "{{") || strings.Contains(image, "@") {
+			return
+		}
+		name := image[strings.LastIndex(image, "/")+1:]
+		i := strings.LastIndex(name, ":")
+		switch {
+		case i < 0:
+			issues = append(issues, Issue{Line: p.Line(path), Message: fmt.Sprintf("image %s has no tag", image)})
+		case name[i+1:] == "latest":
+			issues = append(issues, Issue{Line: p.Line(path), Message: fmt.Sprintf("image %s uses the latest tag", image)})
+		}
+	}
+
+	check(p.Shared.Image, "shared.image")
+	for _, name := range jobNames(p) {
+		check(p.Jobs[name].Image, "jobs."+name+".image")
+	}
+	return issues
+}
+
+type unpinnedTemplate struct{}
+
+func (unpinnedTemplate) Name() string { return "unpinned-template" }
+func (unpinnedTemplate) Description() string {
+	return "templates should be pinned to a version"
+}
+func (unpinnedTemplate) DefaultSeverity() string { return SeverityWarning }
+
+func (r unpinnedTemplate) Check(p *Pipeline) []Issue {
+	var issues []Issue
+	for _, name := range jobNames(p) {
+		template := p.Jobs[name].Template
+		if template == "" {
+			continue
+		}
+		i := strings.LastIndex(template, "@")
+		if i < 0 || template[i+1:] == "latest" {
+			issues = append(issues, Issue{
+				Line:    p.Line("jobs." + name + ".template"),
+				Message: fmt.Sprintf("template %s of job %s is not pinned to a version", template, name),
+			})
+		}
+	}
+	return issues
+}
+
+type undeclaredSecret struct{}
+
+var (
+	envReference = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)`)
+	// defaultedReference matches variables with a default such as "${A:-1}", which may be left unset
+	defaultedReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*):?[-=]`)
+	// envAssignment matches variables set by steps such as "export A=1", "B=$(cat b)", "for C in" and "read D"
+	envAssignment = regexp.MustCompile(`(?:^|[\s;&|(])(?:(?:export|local|readonly|declare)\s+)?([A-Za-z_][A-Za-z0-9_]*)=|\b(?:for|read(?:\s+-\w+)*)\s+([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// builtinEnv is variables of the shell and Screwdriver.cd in builds other than SD_*
+var builtinEnv = map[string]bool{
+	"HOME": true, "PATH": true, "PWD": true, "OLDPWD": true, "USER": true, "SHELL": true, "HOSTNAME": true,
+	"TERM": true, "LANG": true, "TMPDIR": true, "IFS": true, "RANDOM": true, "SECONDS": true, "LINENO": true,
+	"PPID": true, "UID": true, "EUID": true, "BASH_SOURCE": true, "PIPESTATUS": true, "FUNCNAME": true, "REPLY": true,
+	"CI": true, "CONTINUOUS_INTEGRATION": true, "SCREWDRIVER": true, "USER_SHELL_BIN": true,
+	"GIT_URL": true, "GIT_BRANCH": true, "CONFIG_URL": true,
+}
+
+func (undeclaredSecret) Name() string { return "undeclared-secret" }
+func (undeclaredSecret) Description() string {
+	return "variables used in steps should be declared under secrets or environment of the job or shared"
+}
+func (undeclaredSecret) DefaultSeverity() string { return SeverityError }
+
+func (r undeclaredSecret) Check(p *Pipeline) []Issue {
+	// a secret is known to the pipeline when any job declares it
+	known := make(map[string]bool)
+	for _, s := range p.Shared.Secrets {
+		known[s] = true
+	}
+	for _, j := range p.Jobs {
+		for _, s := range j.Secrets {
+			known[s] = true
+		}
+	}
+
+	var issues []Issue
+	for _, name := range jobNames(p) {
+		job := p.Jobs[name]
+		declared := make(map[string]bool)
+		for _, s := range append(append([]string{}, p.Shared.Secrets...), job.Secrets...) {
+			declared[s] = true
+		}
+		for _, env := range []map[string]string{p.Shared.Environment, job.Environment} {
+			for k := range env {
+				declared[k] = true
+			}
+		}
+		defaulted := make(map[string]bool)
+		// steps share the shell of the build, so a variable set by any step is not reported
+		for _, step := range job.Steps {
+			for _, command := range step {
+				for _, m := range envAssignment.FindAllStringSubmatch(command, -1) {
+					declared[m[1]+m[2]] = true
+				}
+				for _, m := range defaultedReference.FindAllStringSubmatch(command, -1) {
+					defaulted[m[1]] = true
+				}
+			}
+		}
+		// templates provide their own environment and secrets
+		templated := job.Template != "" || p.Shared.Template != ""
+
+		for i, step := range job.Steps {
+			reported := make(map[string]bool)
+			for _, command := range step {
+				for _, m := range envReference.FindAllStringSubmatch(command, -1) {
+					v := m[1]
+					if declared[v] || reported[v] {
+						continue
+					}
+					var message string
+					switch {
+					case known[v]:
+						message = fmt.Sprintf("secret %s is used in job %s but not declared in its secrets", v, name)
+					case templated || defaulted[v] || builtinEnv[v] || strings.HasPrefix(v, "SD_"):
+						continue
+					default:
+						message = fmt.Sprintf("variable %s is used in job %s but not declared in secrets or environment", v, name)
+					}
+					reported[v] = true
+					issues = append(issues, Issue{
+						Line:    p.Line(fmt.Sprintf("jobs.%s.steps[%d]", name, i)),
+						Message: message,
+					})
+				}
+			}
+		}
+	}
+	return issues
+}
+
+type unreachableJob struct{}
+
+func (unreachableJob) Name() string { return "unreachable-job" }
+func (unreachableJob) Description() string {
+	return "jobs should be reachable from a trigger such as ~commit or ~pr"
+}
+func (unreachableJob) DefaultSeverity() string { return SeverityError }
+
+func (r unreachableJob) Check(p *Pipeline) []Issue {
+	var wg sdapi.WorkflowGraph
+	var roots []string
+	for _, name := range jobNames(p) {
+		job := p.Jobs[name]
+		wg.Nodes = append(wg.Nodes, sdapi.WorkflowNode{Name: name})
+		// jobs without requires are started manually
+		if job.Requires == nil || len(*job.Requires) == 0 {
+			roots = append(roots, name)
+			continue
+		}
+		for _, req := range *job.Requires {
+			src := strings.TrimPrefix(req, "~")
+			if _, ok := p.Jobs[src]; !ok && (strings.HasPrefix(req, "~") || strings.HasPrefix(req, "sd@")) {
+				// triggers like ~commit, ~pr:branch and external jobs
+				roots = append(roots, req)
+				src = req
+			}
+			wg.Edges = append(wg.Edges, sdapi.WorkflowEdge{Src: src, Dest: name})
+		}
+	}
+
+	reached := workflow.New(wg).Reachable(roots)
+	var issues []Issue
+	for _, name := range jobNames(p) {
+		if !reached[name] {
+			issues = append(issues, Issue{
+				Line:    p.Line("jobs." + name + ".requires"),
+				Message: fmt.Sprintf("job %s can not be reached from any trigger", name),
+			})
+		}
+	}
+	return issues
+}
+
+type duplicateSteps struct{}
+
+func (duplicateSteps) Name() string { return "duplicate-steps" }
+func (duplicateSteps) Description() string {
+	return "the same step should not be repeated across jobs; use shared or a template"
+}
+func (duplicateSteps) DefaultSeverity() string { return SeverityWarning }
+
+func (r duplicateSteps) Check(p *Pipeline) []Issue {
+	type location struct {
+		job  string
+		step string
+	}
+	first := make(map[string]location)
+
+	var issues []Issue
+	for _, name := range jobNames(p) {
+		for i, step := range p.Jobs[name].Steps {
+			stepName := StepName(step)
+			command := strings.TrimSpace(step[stepName])
+			if command == "" {
+				continue
+			}
+			l, ok := first[command]
+			if !ok {
+				first[command] = location{job: name, step: stepName}
+				continue
+			}
+			if l.job == name {
+				continue
+			}
+			issues = append(issues, Issue{
+				Line:    p.Line(fmt.Sprintf("jobs.%s.steps[%d]", name, i)),
+				Message: fmt.Sprintf("step %s of job %s duplicates step %s of job %s", stepName, name, l.step, l.job),
+			})
+		}
+	}
+	return issues
+}
+
+type missingRequires struct{}
+
+func (missingRequires) Name() string { return "missing-requires" }
+func (missingRequires) Description() string {
+	return "jobs should declare requires; use an empty list for jobs started manually"
+}
+func (missingRequires) DefaultSeverity() string { return SeverityWarning }
+
+func (r missingRequires) Check(p *Pipeline) []Issue {
+	var issues []Issue
+	for _, name := range jobNames(p) {
+		if p.Jobs[name].Requires == nil {
+			issues = append(issues, Issue{
+				Line:    p.Line("jobs." + name),
+				Message: fmt.Sprintf("job %s has no requires", name),
+			})
+		}
+	}
+	return issues
+}
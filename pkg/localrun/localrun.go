@@ -0,0 +1,285 @@
+package localrun
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+// paths in the build container, same as Screwdriver.cd builds
+const (
+	WorkspaceDir = "/sd/workspace"
+	SourceDir    = WorkspaceDir + "/src"
+	ArtifactsDir = WorkspaceDir + "/artifacts"
+	toolsDir     = "/opt/sdctl"
+	metaFile     = toolsDir + "/meta.txt"
+	envFile      = toolsDir + "/env"
+)
+
+// metaScript is a file-backed stub of the meta command of Screwdriver.cd. keys are flat.
+const metaScript = `#!/bin/sh
+file=${SD_META_PATH:-` + metaFile + `}
+touch "$file"
+key=$2
+case "$1" in
+get)
+  value=null
+  while IFS= read -r line; do
+    case "$line" in "$key="*) value=${line#"$key="} ;; esac
+  done < "$file"
+  echo "$value"
+  ;;
+set)
+  shift 2
+  : > "$file.tmp"
+  while IFS= read -r line; do
+    case "$line" in "$key="*) ;; *) echo "$line" >> "$file.tmp" ;; esac
+  done < "$file"
+  echo "$key=$*" >> "$file.tmp"
+  mv "$file.tmp" "$file"
+  ;;
+*)
+  echo "usage: meta get <key> | meta set <key> <value>" >&2
+  exit 1
+  ;;
+esac
+`
+
+// stepScript runs a step in the shell environment left by the previous steps
+const stepScript = `[ -f ` + envFile + ` ] && . ` + envFile + `
+export PATH="` + toolsDir + `/bin:$PATH"
+cd "$SD_SOURCE_DIR"
+eval "$SD_STEP_COMMAND"
+rc=$?
+export -p > ` + envFile + `
+exit $rc
+`
+
+// Options configures a local run of a job
+type Options struct {
+	// Context stops the running step and skips the rest when it is done
+	Context   context.Context
+	JobName   string
+	Job       sdapi.JobConfig
+	SourceDir string
+	APIURL    string
+	// Env is added to the job environment, e.g. secrets
+	Env    map[string]string
+	Docker string
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// StepResult is the result of a step
+type StepResult struct {
+	Name     string
+	ExitCode int
+	Skipped  bool
+	Duration time.Duration
+}
+
+// Run runs steps of a job in order in a container of the job image
+func Run(o Options) ([]StepResult, error) {
+	if o.Job.Image == "" {
+		return nil, fmt.Errorf("job %s has no image", o.JobName)
+	}
+	if o.Context == nil {
+		o.Context = context.Background()
+	}
+	if o.Docker == "" {
+		o.Docker = "docker"
+	}
+	if o.Stdout == nil {
+		o.Stdout = os.Stdout
+	}
+	if o.Stderr == nil {
+		o.Stderr = os.Stderr
+	}
+	src, err := filepath.Abs(o.SourceDir)
+	if err != nil {
+		return nil, err
+	}
+	o.SourceDir = src
+
+	tools, err := ioutil.TempDir("", "sdctl-local-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tools)
+	if err := os.Mkdir(filepath.Join(tools, "bin"), 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(tools, "bin", "meta"), []byte(metaScript), 0755); err != nil {
+		return nil, err
+	}
+
+	// --env is passed in a file instead of arguments, which are seen by other users in ps
+	var envPath string
+	var envVars []string
+	if len(o.Env) != 0 {
+		if envPath, envVars, err = WriteEnvFile(o.Env); err != nil {
+			return nil, err
+		}
+	}
+
+	container := fmt.Sprintf("sdctl-local-%d", os.Getpid())
+	var stderr bytes.Buffer
+	run := exec.CommandContext(o.Context, o.Docker, RunArgs(o, container, tools, envPath)...)
+	run.Env = append(os.Environ(), envVars...)
+	run.Stderr = &stderr
+	err = run.Run()
+	// docker reads the env file only on start, so it is not left on the disk while steps run
+	if envPath != "" {
+		os.Remove(envPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s: %v: %s", o.Job.Image, err, strings.TrimSpace(stderr.String()))
+	}
+	defer exec.Command(o.Docker, "rm", "-f", container).Run()
+
+	var results []StepResult
+	failed := false
+	for _, c := range o.Job.Commands {
+		if err := o.Context.Err(); err != nil {
+			return results, err
+		}
+		// teardown steps run even if a step failed
+		if failed && !strings.HasPrefix(c.Name, "teardown-") {
+			results = append(results, StepResult{Name: c.Name, Skipped: true})
+			continue
+		}
+
+		fmt.Fprintf(o.Stdout, "==> %s\n$ %s\n", c.Name, c.Command)
+		start := time.Now()
+		step := exec.CommandContext(o.Context, o.Docker, "exec",
+			"-e", "SD_STEP_NAME="+c.Name,
+			"-e", "SD_STEP_COMMAND="+c.Command,
+			container, "/bin/sh", "-c", stepScript)
+		step.Stdout = o.Stdout
+		step.Stderr = o.Stderr
+
+		r := StepResult{Name: c.Name}
+		if err := step.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				return results, err
+			}
+			r.ExitCode = exitErr.ExitCode()
+			failed = true
+		}
+		r.Duration = time.Since(start)
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// RunArgs returns arguments of docker to start a container for the job.
+// o.Env is left to envPath written by WriteEnvFile, and is not in the arguments.
+func RunArgs(o Options, container, tools, envPath string) []string {
+	args := []string{
+		"run", "-d", "--rm",
+		"--name", container,
+		"-v", o.SourceDir + ":" + SourceDir,
+		"-v", tools + ":" + toolsDir,
+		"-w", SourceDir,
+	}
+	if envPath != "" {
+		args = append(args, "--env-file", envPath)
+	}
+	for _, e := range Environment(o) {
+		if _, ok := o.Env[e[:strings.Index(e, "=")]]; ok {
+			continue
+		}
+		args = append(args, "-e", e)
+	}
+	return append(args, "--entrypoint", "/bin/sh", o.Job.Image, "-c", "mkdir -p "+ArtifactsDir+"; tail -f /dev/null")
+}
+
+// WriteEnvFile writes env into a temporary file readable only by the user for docker run --env-file, and returns its path.
+// env files have a variable per line, so a value with newlines is left to the environment of docker, returned as KEY=VALUE.
+func WriteEnvFile(env map[string]string) (string, []string, error) {
+	f, err := ioutil.TempFile("", "sdctl-local-env-")
+	if err != nil {
+		return "", nil, err
+	}
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", nil, err
+	}
+
+	var keys []string
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	var vars []string
+	for _, k := range keys {
+		v := env[k]
+		if strings.ContainsAny(v, "\r\n") {
+			// a variable without a value is taken from the environment of docker
+			fmt.Fprintln(&buf, k)
+			vars = append(vars, k+"="+v)
+			continue
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", k, v)
+	}
+	_, err = f.Write(buf.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", nil, err
+	}
+	return f.Name(), vars, nil
+}
+
+// Environment returns environment variables of the job with stubbed SD_* variables
+func Environment(o Options) []string {
+	env := map[string]string{
+		"SCREWDRIVER":            "true",
+		"CI":                     "true",
+		"CONTINUOUS_INTEGRATION": "true",
+		"SD_JOB_NAME":            o.JobName,
+		"SD_PIPELINE_ID":         "0",
+		"SD_EVENT_ID":            "0",
+		"SD_BUILD_ID":            "0",
+		"SD_ROOT_DIR":            WorkspaceDir,
+		"SD_SOURCE_DIR":          SourceDir,
+		"SD_ARTIFACTS_DIR":       ArtifactsDir,
+		"SD_META_DIR":            toolsDir,
+		"SD_META_PATH":           metaFile,
+		"SD_API_URL":             strings.TrimSuffix(o.APIURL, "/") + "/v4/",
+		"SD_LOCAL":               "true",
+	}
+	if sha, err := exec.Command("git", "-C", o.SourceDir, "rev-parse", "HEAD").Output(); err == nil {
+		env["SD_BUILD_SHA"] = strings.TrimSpace(string(sha))
+	}
+	for k, v := range o.Job.Environment {
+		env[k] = v
+	}
+	for k, v := range o.Env {
+		env[k] = v
+	}
+
+	var list []string
+	for k, v := range env {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,148 @@
+package localrun
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+func TestRunArgs(t *testing.T) {
+	o := Options{
+		JobName:   "main",
+		Job:       sdapi.JobConfig{Image: "node:12", Environment: map[string]string{"FOO": "bar", "SD_BUILD_ID": "1"}},
+		SourceDir: "/tmp/src",
+		APIURL:    "https://api.example.com/",
+		Env:       map[string]string{"NPM_TOKEN": "secret"},
+	}
+	args := RunArgs(o, "container", "/tmp/tools", "/tmp/env")
+
+	expectedHead := []string{"run", "-d", "--rm", "--name", "container", "-v", "/tmp/src:/sd/workspace/src", "-v", "/tmp/tools:/opt/sdctl", "-w", "/sd/workspace/src", "--env-file", "/tmp/env"}
+	if diff := cmp.Diff(expectedHead, args[:len(expectedHead)]); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	expectedTail := []string{"--entrypoint", "/bin/sh", "node:12", "-c", "mkdir -p /sd/workspace/artifacts; tail -f /dev/null"}
+	if diff := cmp.Diff(expectedTail, args[len(args)-len(expectedTail):]); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	joined := strings.Join(args, " ")
+	if !strings.Contains(joined, "-e FOO=bar") || strings.Contains(joined, "NPM_TOKEN") {
+		t.Errorf("only --env should be left to the env file: %v", args)
+	}
+
+	env := strings.Join(Environment(o), "\n") + "\n"
+	for _, e := range []string{"FOO=bar", "NPM_TOKEN=secret", "SD_BUILD_ID=1", "SD_JOB_NAME=main", "SD_API_URL=https://api.example.com/v4/", "SD_SOURCE_DIR=/sd/workspace/src"} {
+		if !strings.Contains(env, e+"\n") {
+			t.Errorf("environment should contain %s: %s", e, env)
+		}
+	}
+}
+
+func TestWriteEnvFile(t *testing.T) {
+	path, vars, err := WriteEnvFile(map[string]string{"NPM_TOKEN": "secret", "EMPTY": "", "KEY": "-----BEGIN\nabc\n-----END"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("env file should be readable only by the user: %v", info.Mode())
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("EMPTY=\nKEY\nNPM_TOKEN=secret\n", string(b)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"KEY=-----BEGIN\nabc\n-----END"}, vars); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMetaScript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "meta")
+	if err := os.WriteFile(script, []byte(metaScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	meta := func(args ...string) string {
+		cmd := exec.Command(script, args...)
+		cmd.Env = append(os.Environ(), "SD_META_PATH="+filepath.Join(dir, "meta.txt"))
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("meta %v: %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	if v := meta("get", "example.coverage"); v != "null" {
+		t.Errorf("unset key should be null, but %s", v)
+	}
+	meta("set", "example.coverage", "99.95")
+	meta("set", "example.name", "foo bar")
+	meta("set", "example.coverage", "100")
+	if v := meta("get", "example.coverage"); v != "100" {
+		t.Errorf("example.coverage should be 100, but %s", v)
+	}
+	if v := meta("get", "example.name"); v != "foo bar" {
+		t.Errorf("example.name should be foo bar, but %s", v)
+	}
+}
+
+func TestSelectJob(t *testing.T) {
+	configs := []sdapi.JobConfig{
+		{Image: "node:14", Environment: map[string]string{"NODE": "14", "OS": "linux", "FOO": "bar"}},
+		{Image: "node:16", Environment: map[string]string{"NODE": "16", "OS": "linux", "FOO": "bar"}},
+		{Image: "node:16", Environment: map[string]string{"NODE": "16", "OS": "alpine", "FOO": "bar"}},
+	}
+
+	cases := map[string]struct {
+		configs       []sdapi.JobConfig
+		selectors     []string
+		expectedImage string
+		expectedErr   string
+	}{
+		"no matrix":         {configs[:1], nil, "node:14", ""},
+		"a matrix entry":    {configs, []string{"NODE=14"}, "node:14", ""},
+		"multiple keys":     {configs, []string{"NODE=16", "OS=alpine"}, "node:16", ""},
+		"matrix not chosen": {configs, nil, "", "NODE=14 OS=linux, NODE=16 OS=linux, NODE=16 OS=alpine"},
+		"ambiguous":         {configs, []string{"NODE=16"}, "", "has 2 matrix entries"},
+		"no entries":        {configs, []string{"NODE=12"}, "", "no matrix entry"},
+		"invalid selector":  {configs, []string{"NODE"}, "", "KEY=VALUE"},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			job, err := SelectJob("main", v.configs, v.selectors)
+			if v.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), v.expectedErr) {
+					t.Errorf("error should contain '%v', but actual is '%v'", v.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.Image != v.expectedImage {
+				t.Errorf("image should be %s, but actual is %s", v.expectedImage, job.Image)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package localrun
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+// SelectJob returns the config of a job by selectors of KEY=VALUE, which choose one of the configs expanded by matrix.
+// a job without matrix has a config, which needs no selectors.
+func SelectJob(jobName string, configs []sdapi.JobConfig, selectors []string) (sdapi.JobConfig, error) {
+	want := make(map[string]string)
+	for _, s := range selectors {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 {
+			return sdapi.JobConfig{}, fmt.Errorf("matrix should be KEY=VALUE: %s", s)
+		}
+		want[kv[0]] = kv[1]
+	}
+
+	var matched []sdapi.JobConfig
+	for _, c := range configs {
+		ok := true
+		for k, v := range want {
+			if c.Environment[k] != v {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, c)
+		}
+	}
+	if len(matched) == 1 {
+		return matched[0], nil
+	}
+
+	entries := make([]string, len(configs))
+	for i, c := range configs {
+		entries[i] = matrixEntry(c, configs)
+	}
+	if len(matched) == 0 {
+		return sdapi.JobConfig{}, fmt.Errorf("no matrix entry of job %s matches %s, choose one of: %s", jobName, strings.Join(selectors, " "), strings.Join(entries, ", "))
+	}
+	return sdapi.JobConfig{}, fmt.Errorf("job %s has %d matrix entries, choose one by --matrix KEY=VALUE: %s", jobName, len(matched), strings.Join(entries, ", "))
+}
+
+// matrixEntry describes a config by environment variables whose values differ among configs, such as NODE=14
+func matrixEntry(c sdapi.JobConfig, configs []sdapi.JobConfig) string {
+	var keys []string
+	for k, v := range c.Environment {
+		for _, other := range configs {
+			if other.Environment[k] != v {
+				keys = append(keys, k)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+	var kvs []string
+	for _, k := range keys {
+		kvs = append(kvs, k+"="+c.Environment[k])
+	}
+	return strings.Join(kvs, " ")
+}
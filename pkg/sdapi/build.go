@@ -0,0 +1,246 @@
+package sdapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Step represents Step API response schema. Code is nil until the step finishes.
+type Step struct {
+	Name      string `json:"name"`
+	Code      *int   `json:"code"`
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+}
+
+// LogLine represents a line of step logs
+type LogLine struct {
+	Time    int64  `json:"t"`
+	Message string `json:"m"`
+	Line    int    `json:"n"`
+}
+
+// GetBuild returns a build
+func (sd *SDAPI) GetBuild(ctx context.Context, buildID int) (*Build, error) {
+	path := fmt.Sprintf("/v4/builds/%d", buildID)
+	res, err := sd.request(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("build %d is %w", buildID, ErrNotFound)
+	default:
+		return nil, fmt.Errorf("GET /v4/builds/%d status code is not %d: %d", buildID, http.StatusOK, res.StatusCode)
+	}
+
+	build := new(Build)
+	if err := json.NewDecoder(res.Body).Decode(build); err != nil {
+		return nil, err
+	}
+	return build, nil
+}
+
+// GetBuildSteps returns steps of a build
+func (sd *SDAPI) GetBuildSteps(ctx context.Context, buildID int) ([]Step, error) {
+	path := fmt.Sprintf("/v4/builds/%d/steps", buildID)
+	res, err := sd.request(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET /v4/builds/%d/steps status code is not %d: %d", buildID, http.StatusOK, res.StatusCode)
+	}
+
+	var steps []Step
+	if err := json.NewDecoder(res.Body).Decode(&steps); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// GetStepLogs returns the first page of logs of a step, or the last page in ascending order when tail is true
+func (sd *SDAPI) GetStepLogs(ctx context.Context, buildID int, step string, tail bool) ([]LogLine, error) {
+	sort := "ascending"
+	if tail {
+		sort = "descending"
+	}
+	path := fmt.Sprintf("/v4/builds/%d/steps/%s/logs", buildID, url.PathEscape(step))
+	res, err := sd.request(ctx, http.MethodGet, path, url.Values{"sort": {sort}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET /v4/builds/%d/steps/%s/logs status code is not %d: %d", buildID, step, http.StatusOK, res.StatusCode)
+	}
+
+	var lines []LogLine
+	if err := json.NewDecoder(res.Body).Decode(&lines); err != nil {
+		return nil, err
+	}
+	if tail {
+		for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+			lines[i], lines[j] = lines[j], lines[i]
+		}
+	}
+	return lines, nil
+}
+
+// StopBuild aborts a running build
+func (sd *SDAPI) StopBuild(ctx context.Context, buildID int) error {
+	path := fmt.Sprintf("/v4/builds/%d", buildID)
+	jsonBody, err := json.Marshal(map[string]string{"status": StatusAborted})
+	if err != nil {
+		return err
+	}
+
+	// aborting a build twice is harmless
+	res, err := sd.request(withIdempotent(ctx), http.MethodPut, path, nil, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("PUT %s status code is not %d: %d", path, http.StatusOK, res.StatusCode)
+	}
+	return nil
+}
+
+// BuildPage represents a build page on the UI with the repository and the job of the build
+type BuildPage struct {
+	BuildID    int
+	PipelineID int
+	URL        string
+	Repo       string
+	Job        string
+}
+
+// GetBuildPage returns the build page of a build
+func (sd *SDAPI) GetBuildPage(ctx context.Context, buildID int) (*BuildPage, error) {
+	return sd.buildPage(ctx, buildID, newJobPages())
+}
+
+// BuildPageResult is the build page of a build ID, or the error looking it up
+type BuildPageResult struct {
+	BuildID int
+	Page    *BuildPage
+	Err     error
+}
+
+// GetBuildPages looks up build pages of buildIDs by concurrency workers, and calls found with each result
+// in the order of buildIDs as soon as the results before it are found.
+// jobs are looked up once per call, so builds of the same job skip requests of the job and its pipeline.
+func (sd *SDAPI) GetBuildPages(ctx context.Context, buildIDs []int, concurrency int, found func(BuildPageResult)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	jobs := newJobPages()
+	results := make([]BuildPageResult, len(buildIDs))
+	done := make([]chan struct{}, len(buildIDs))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	indexes := make(chan int)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			for i := range indexes {
+				page, err := sd.buildPage(ctx, buildIDs[i], jobs)
+				results[i] = BuildPageResult{BuildID: buildIDs[i], Page: page, Err: err}
+				close(done[i])
+			}
+		}()
+	}
+	go func() {
+		for i := range buildIDs {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+
+	for i := range buildIDs {
+		<-done[i]
+		found(results[i])
+	}
+}
+
+func (sd *SDAPI) buildPage(ctx context.Context, buildID int, jobs *jobPages) (*BuildPage, error) {
+	build, err := sd.GetBuild(ctx, buildID)
+	if err != nil {
+		return nil, err
+	}
+	job := jobs.get(ctx, sd, build.JobID)
+	if job.err != nil {
+		return nil, job.err
+	}
+	return &BuildPage{
+		BuildID:    buildID,
+		PipelineID: job.pipelineID,
+		URL:        sd.BuildPageURL(job.pipelineID, buildID),
+		Repo:       job.repo,
+		Job:        job.name,
+	}, nil
+}
+
+// jobPage is the job and the pipeline shown on build pages of a job
+type jobPage struct {
+	done       chan struct{}
+	pipelineID int
+	repo       string
+	name       string
+	err        error
+}
+
+// jobPages looks up each job once, and lets concurrent lookups of the same job wait for the first one
+type jobPages struct {
+	mu    sync.Mutex
+	pages map[int]*jobPage
+}
+
+func newJobPages() *jobPages {
+	return &jobPages{pages: map[int]*jobPage{}}
+}
+
+func (j *jobPages) get(ctx context.Context, sd *SDAPI, jobID int) *jobPage {
+	j.mu.Lock()
+	page, ok := j.pages[jobID]
+	if !ok {
+		page = &jobPage{done: make(chan struct{})}
+		j.pages[jobID] = page
+	}
+	j.mu.Unlock()
+	if ok {
+		<-page.done
+		return page
+	}
+
+	defer close(page.done)
+	job, err := sd.GetJob(ctx, jobID)
+	if err != nil {
+		page.err = err
+		return page
+	}
+	pipeline, err := sd.GetPipeline(ctx, job.PipelineID)
+	if err != nil {
+		page.err = err
+		return page
+	}
+	page.pipelineID = job.PipelineID
+	page.repo = pipeline.SCMRepo.Name
+	page.name = job.Name
+	return page
+}
+
+// BuildPageURL returns the URL of a build page on the UI
+func (sd *SDAPI) BuildPageURL(pipelineID, buildID int) string {
+	return fmt.Sprintf("%s/builds/%d", sd.PipelinePageURL(pipelineID), buildID)
+}
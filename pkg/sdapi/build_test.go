@@ -0,0 +1,219 @@
+package sdapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetStepLogs(t *testing.T) {
+	muxAPI := http.NewServeMux()
+	testAPIServer := httptest.NewServer(muxAPI)
+	defer testAPIServer.Close()
+
+	muxAPI.HandleFunc("/v4/builds/1/steps/test/logs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sort") == "descending" {
+			fmt.Fprint(w, `[{"t":3,"m":"c","n":2},{"t":2,"m":"b","n":1}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"t":1,"m":"a","n":0},{"t":2,"m":"b","n":1}]`)
+	})
+
+	mockSDContext.APIURL = testAPIServer.URL
+	sdapi, err := New(mockSDContext)
+	if err != nil {
+		t.Fatal("should not cause error")
+	}
+
+	cases := map[string]struct {
+		tail     bool
+		expected []LogLine
+	}{
+		"head": {false, []LogLine{{1, "a", 0}, {2, "b", 1}}},
+		"tail": {true, []LogLine{{2, "b", 1}, {3, "c", 2}}},
+	}
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			lines, err := sdapi.GetStepLogs(context.Background(), 1, "test", v.tail)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(v.expected, lines); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStopBuild(t *testing.T) {
+	cases := map[string]struct {
+		statusCode  int
+		expectedErr bool
+	}{
+		"Stop a build successfully": {http.StatusOK, false},
+		"Failed to stop a build":    {http.StatusForbidden, true},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			muxAPI := http.NewServeMux()
+			testAPIServer := httptest.NewServer(muxAPI)
+			defer testAPIServer.Close()
+
+			muxAPI.HandleFunc("/v4/builds/1", func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				if r.Method != http.MethodPut || string(body) != `{"status":"ABORTED"}` {
+					t.Errorf("unexpected request: %s %s", r.Method, body)
+				}
+				w.WriteHeader(v.statusCode)
+			})
+			muxAPI.HandleFunc("/v4/auth/token", func(w http.ResponseWriter, r *http.Request) {
+				http.ServeFile(w, r, mockSDJWTResponse)
+			})
+
+			mockSDContext.APIURL = testAPIServer.URL
+			sdapi, err := New(mockSDContext)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+			if err := sdapi.StopBuild(context.Background(), 1); (err != nil) != v.expectedErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildPageURL(t *testing.T) {
+	ctx := mockSDContext
+	ctx.APIURL = "https://api-cd.screwdriver.cd/"
+	sdapi, err := New(ctx)
+	if err != nil {
+		t.Fatal("should not cause error")
+	}
+	expected := "https://cd.screwdriver.cd/pipelines/1/builds/2"
+	if actual := sdapi.BuildPageURL(1, 2); actual != expected {
+		t.Errorf("url should be %s, but actual is %s", expected, actual)
+	}
+
+	ctx.UIURL = "https://sd.example.com/"
+	sdapi, err = New(ctx)
+	if err != nil {
+		t.Fatal("should not cause error")
+	}
+	expected = "https://sd.example.com/pipelines/1/builds/2"
+	if actual := sdapi.BuildPageURL(1, 2); actual != expected {
+		t.Errorf("url should be %s, but actual is %s", expected, actual)
+	}
+}
+
+func TestGetBuildPages(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	running, maxRunning := 0, 0
+	muxAPI := http.NewServeMux()
+	count := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[r.URL.Path]++
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}()
+			next(w, r)
+		}
+	}
+	muxAPI.HandleFunc("/v4/builds/", count(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v4/builds/1":
+			// the first build is found last
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprint(w, `{"id":1,"jobId":10}`)
+		case "/v4/builds/2":
+			fmt.Fprint(w, `{"id":2,"jobId":10}`)
+		case "/v4/builds/4":
+			fmt.Fprint(w, `{"id":4,"jobId":20}`)
+		case "/v4/builds/5":
+			fmt.Fprint(w, `{"id":5,"jobId":10}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	muxAPI.HandleFunc("/v4/jobs/10", count(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":10,"pipelineId":100,"name":"main"}`)
+	}))
+	muxAPI.HandleFunc("/v4/jobs/20", count(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":20,"pipelineId":100,"name":"publish"}`)
+	}))
+	muxAPI.HandleFunc("/v4/pipelines/100", count(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":100,"scmRepo":{"name":"org/repo"}}`)
+	}))
+	testAPIServer := httptest.NewServer(muxAPI)
+	defer testAPIServer.Close()
+
+	ctx := mockSDContext
+	ctx.APIURL = testAPIServer.URL
+	ctx.UIURL = "https://cd.screwdriver.cd"
+	sdapi, err := New(ctx)
+	if err != nil {
+		t.Fatal("should not cause error")
+	}
+
+	var results []BuildPageResult
+	sdapi.GetBuildPages(context.Background(), []int{1, 2, 3, 4, 5}, 2, func(r BuildPageResult) {
+		results = append(results, r)
+	})
+
+	var ids []int
+	for _, r := range results {
+		ids = append(ids, r.BuildID)
+	}
+	if diff := cmp.Diff([]int{1, 2, 3, 4, 5}, ids); diff != "" {
+		t.Errorf("results should be in the order of build IDs (-want +got):\n%s", diff)
+	}
+	expected := map[int]*BuildPage{
+		1: {BuildID: 1, PipelineID: 100, URL: "https://cd.screwdriver.cd/pipelines/100/builds/1", Repo: "org/repo", Job: "main"},
+		2: {BuildID: 2, PipelineID: 100, URL: "https://cd.screwdriver.cd/pipelines/100/builds/2", Repo: "org/repo", Job: "main"},
+		4: {BuildID: 4, PipelineID: 100, URL: "https://cd.screwdriver.cd/pipelines/100/builds/4", Repo: "org/repo", Job: "publish"},
+		5: {BuildID: 5, PipelineID: 100, URL: "https://cd.screwdriver.cd/pipelines/100/builds/5", Repo: "org/repo", Job: "main"},
+	}
+	for _, r := range results {
+		if r.BuildID == 3 {
+			if !errors.Is(r.Err, ErrNotFound) || r.Page != nil {
+				t.Errorf("build 3 should not be found: %+v", r)
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Errorf("build %d should be found: %v", r.BuildID, r.Err)
+			continue
+		}
+		if diff := cmp.Diff(expected[r.BuildID], r.Page); diff != "" {
+			t.Errorf("build %d mismatch (-want +got):\n%s", r.BuildID, diff)
+		}
+	}
+
+	if hits["/v4/jobs/10"] != 1 || hits["/v4/jobs/20"] != 1 || hits["/v4/pipelines/100"] != 2 {
+		t.Errorf("each job should be looked up once: %v", hits)
+	}
+	if maxRunning > 2 {
+		t.Errorf("requests should be limited by the concurrency, but %d ran at once", maxRunning)
+	}
+}
@@ -0,0 +1,273 @@
+package sdapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redacted replaces credentials in cassettes
+const redacted = "REDACTED"
+
+// Interaction is a pair of a request and its response saved in a cassette
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request with credentials redacted. URL is the path and the query.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response with credentials redacted
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// WrapTransport wraps the transport sending each attempt of requests, such as with NewRecorder.
+// retries stay above the wrapped transport so that replayed attempts are retried as recorded. call it before sending requests.
+func (sd *SDAPI) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	if rt, ok := sd.client.HTTPClient.Transport.(*retryTransport); ok {
+		rt.base = wrap(rt.base)
+		return
+	}
+	sd.client.HTTPClient.Transport = wrap(sd.client.HTTPClient.Transport)
+}
+
+// recorder saves interactions through base into a directory, a file per interaction
+type recorder struct {
+	base http.RoundTripper
+	dir  string
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewRecorder returns a RoundTripper saving interactions through base into dir as a cassette.
+// Authorization headers, token query parameters, JWTs and values of tokens and secrets are redacted.
+func NewRecorder(base http.RoundTripper, dir string) (http.RoundTripper, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// append to the existing cassette
+	return &recorder{base: base, dir: dir, next: len(files) + 1}, nil
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := readBody(&res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	in := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    scrubURL(req.URL),
+			Header: scrubHeader(req.Header),
+			Body:   scrubBody(req.URL.Path, reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     res.Header.Clone(),
+			Body:       scrubBody(req.URL.Path, resBody),
+		},
+	}
+	in.Response.Header.Del("Date")
+	b, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path := filepath.Join(r.dir, fmt.Sprintf("%04d.json", r.next))
+	if err := os.WriteFile(path, append(b, '\n'), 0600); err != nil {
+		return nil, err
+	}
+	r.next++
+	return res, nil
+}
+
+// replayer responds with interactions of a cassette without sending requests
+type replayer struct {
+	dir string
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// NewReplayer returns a RoundTripper responding with interactions recorded in dir by NewRecorder.
+// a request matches the first unused interaction with the same method, URL and body after redaction.
+func NewReplayer(dir string) (http.RoundTripper, error) {
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no interactions are recorded in %s", dir)
+	}
+	r := &replayer{dir: dir, used: make([]bool, len(files))}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		in := new(Interaction)
+		if err := json.Unmarshal(b, in); err != nil {
+			return nil, fmt.Errorf("invalid interaction %s: %v", f, err)
+		}
+		r.interactions = append(r.interactions, in)
+	}
+	return r, nil
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	u := scrubURL(req.URL)
+	body = scrubBody(req.URL.Path, body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || in.Request.Method != req.Method || in.Request.URL != u || in.Request.Body != body {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no interaction for %s %s is recorded in %s", req.Method, u, r.dir)
+}
+
+// cassetteFiles returns interaction files of a cassette in the recorded order
+func cassetteFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// readBody reads a body and replaces it to be read again
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+	b, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return "", err
+	}
+	*body = io.NopCloser(bytes.NewReader(b))
+	return string(b), nil
+}
+
+// scrubURL returns the path and the query of u with credentials redacted
+func scrubURL(u *url.URL) string {
+	query := u.Query()
+	for _, key := range []string{"token", "api_token"} {
+		if query.Get(key) != "" {
+			query.Set(key, redacted)
+		}
+	}
+	if len(query) == 0 {
+		return u.EscapedPath()
+	}
+	return u.EscapedPath() + "?" + query.Encode()
+}
+
+func scrubHeader(h http.Header) http.Header {
+	h = h.Clone()
+	if h.Get("Authorization") != "" {
+		h.Set("Authorization", redacted)
+	}
+	return h
+}
+
+// scrubBody redacts JWTs of /v4/auth/token and values of tokens and secrets in a JSON body
+func scrubBody(path, body string) string {
+	var keys []string
+	switch {
+	case path == "/v4/auth/token":
+		keys = []string{"token"}
+	case strings.Contains(path, "/tokens"), strings.HasPrefix(path, "/v4/secrets"):
+		keys = []string{"value"}
+	default:
+		return body
+	}
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return body
+	}
+	if !redactKeys(v, keys) {
+		return body
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return string(b)
+}
+
+// redactKeys replaces values of keys in JSON objects and arrays of them. it returns whether v is changed.
+func redactKeys(v interface{}, keys []string) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, k := range keys {
+			if _, ok := v[k]; ok {
+				v[k] = redacted
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			changed = redactKeys(e, keys) || changed
+		}
+	}
+	return changed
+}
@@ -0,0 +1,102 @@
+package sdapi
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	muxAPI := http.NewServeMux()
+	testAPIServer := httptest.NewServer(muxAPI)
+
+	muxAPI.HandleFunc("/v4/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token":"secret_jwt"}`)
+	})
+	muxAPI.HandleFunc("/v4/pipelines/1234", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":1234,"name":"org/repo"}`)
+	})
+	muxAPI.HandleFunc("/v4/secrets", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":1,"pipelineId":1234,"name":"FOO","allowInPR":false}`)
+	})
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	sdctx := mockSDContext
+	sdctx.APIURL = testAPIServer.URL
+	sdctx.UserToken = "secret_api_token"
+
+	recording, err := New(sdctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder, err := NewRecorder(nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recording.WrapTransport(func(base http.RoundTripper) http.RoundTripper { return recorder })
+	if _, err := recording.GetJWT(ctx); err != nil {
+		t.Fatal(err)
+	}
+	recording.tokens = StaticTokenSource("secret_jwt")
+	if _, err := recording.GetPipeline(ctx, mockPipelineID); err != nil {
+		t.Fatal(err)
+	}
+	if err := recording.createSecret(ctx, mockPipelineID, "FOO", "secret_value", false); err != nil {
+		t.Fatal(err)
+	}
+	testAPIServer.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 3 {
+		t.Fatalf("3 interactions should be recorded, but actual is %v", files)
+	}
+	for _, f := range files {
+		b, _ := ioutil.ReadFile(f)
+		for _, secret := range []string{"secret_api_token", "secret_jwt", "secret_value"} {
+			if strings.Contains(string(b), secret) {
+				t.Errorf("%s should be redacted in %s:\n%s", secret, f, b)
+			}
+		}
+	}
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdctx.UserToken = "another_api_token"
+	replaying, err := New(sdctx, WithTokenSource(StaticTokenSource("another_jwt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaying.WrapTransport(func(base http.RoundTripper) http.RoundTripper { return replayer })
+
+	p, err := replaying.GetPipeline(ctx, mockPipelineID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "org/repo" {
+		t.Errorf("recorded pipeline should be replayed: %+v", p)
+	}
+	if err := replaying.createSecret(ctx, mockPipelineID, "FOO", "another_value", false); err != nil {
+		t.Errorf("request differing only in credentials should be replayed: %v", err)
+	}
+	if _, err := replaying.GetPipeline(ctx, mockPipelineID); err == nil {
+		t.Errorf("interaction should be replayed only once")
+	}
+	if _, err := replaying.GetPipeline(ctx, 5678); err == nil {
+		t.Errorf("request not recorded should fail")
+	}
+}
+
+func TestNewReplayerWithoutInteractions(t *testing.T) {
+	if _, err := NewReplayer(t.TempDir()); err == nil {
+		t.Errorf("empty cassette should be an error")
+	}
+}
@@ -0,0 +1,139 @@
+package sdapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Collection represents Collection API response schema
+type Collection struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Type        string     `json:"type,omitempty"`
+	PipelineIDs []int      `json:"pipelineIds"`
+	Pipelines   []Pipeline `json:"pipelines,omitempty"`
+}
+
+// CollectionRequest represents Collection API request schema
+type CollectionRequest struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	PipelineIDs []int  `json:"pipelineIds"`
+}
+
+// GetCollections returns all collections of the user
+func (sd *SDAPI) GetCollections(ctx context.Context) ([]Collection, error) {
+	return sd.ListCollections(ctx, ListOptions{})
+}
+
+// ListCollections returns collections of the user following pages up to the limit
+func (sd *SDAPI) ListCollections(ctx context.Context, opts ListOptions) ([]Collection, error) {
+	var collections []Collection
+	if err := sd.ListAll(ctx, "/v4/collections", nil, opts, &collections); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+// GetCollection returns a collection with its pipelines
+func (sd *SDAPI) GetCollection(ctx context.Context, id int) (*Collection, error) {
+	path := fmt.Sprintf("/v4/collections/%d", id)
+	res, err := sd.request(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("collection %d is %w", id, ErrNotFound)
+	default:
+		return nil, fmt.Errorf("GET /v4/collections/%d status code is not %d: %d", id, http.StatusOK, res.StatusCode)
+	}
+
+	collection := new(Collection)
+	if err := json.NewDecoder(res.Body).Decode(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// FindCollection returns a collection from an ID or a name
+func (sd *SDAPI) FindCollection(ctx context.Context, nameOrID string) (*Collection, error) {
+	if id, err := strconv.Atoi(nameOrID); err == nil {
+		return sd.GetCollection(ctx, id)
+	}
+
+	collections, err := sd.GetCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range collections {
+		if c.Name == nameOrID {
+			return sd.GetCollection(ctx, c.ID)
+		}
+	}
+	return nil, fmt.Errorf("collection %s is %w", nameOrID, ErrNotFound)
+}
+
+// CreateCollection creates a collection
+func (sd *SDAPI) CreateCollection(ctx context.Context, collection CollectionRequest) (*Collection, error) {
+	return sd.requestCollection(ctx, http.MethodPost, "/v4/collections", collection, http.StatusCreated)
+}
+
+// UpdateCollection replaces name, description and pipelines of a collection
+func (sd *SDAPI) UpdateCollection(ctx context.Context, id int, collection CollectionRequest) (*Collection, error) {
+	return sd.requestCollection(withIdempotent(ctx), http.MethodPut, fmt.Sprintf("/v4/collections/%d", id), collection, http.StatusOK)
+}
+
+// DeleteCollection deletes a collection
+func (sd *SDAPI) DeleteCollection(ctx context.Context, id int) error {
+	_, err := sd.requestCollection(ctx, http.MethodDelete, fmt.Sprintf("/v4/collections/%d", id), nil, http.StatusNoContent)
+	return err
+}
+
+func (sd *SDAPI) requestCollection(ctx context.Context, method, path string, body interface{}, expectedStatus int) (*Collection, error) {
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewBuffer(jsonBody)
+	}
+
+	res, err := sd.request(ctx, method, path, nil, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case expectedStatus:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s is %w", path, ErrNotFound)
+	case http.StatusConflict:
+		name := ""
+		if req, ok := body.(CollectionRequest); ok {
+			name = req.Name
+		}
+		return nil, fmt.Errorf("collection %s %w", name, ErrAlreadyExists)
+	default:
+		return nil, fmt.Errorf("%s %s status code is not %d: %d", method, path, expectedStatus, res.StatusCode)
+	}
+
+	if expectedStatus == http.StatusNoContent {
+		return nil, nil
+	}
+	collection := new(Collection)
+	if err := json.NewDecoder(res.Body).Decode(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
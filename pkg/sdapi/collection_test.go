@@ -0,0 +1,151 @@
+package sdapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindCollection(t *testing.T) {
+	cases := map[string]struct {
+		nameOrID    string
+		expectedID  int
+		expectedErr bool
+	}{
+		"collection ID":   {"2", 2, false},
+		"collection name": {"team-x", 2, false},
+		"not found":       {"team-y", 0, true},
+		"unknown ID":      {"3", 0, true},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			muxAPI := http.NewServeMux()
+			testAPIServer := httptest.NewServer(muxAPI)
+			defer testAPIServer.Close()
+
+			muxAPI.HandleFunc("/v4/collections", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"id":1,"name":"My Pipelines","pipelineIds":[]},{"id":2,"name":"team-x","pipelineIds":[1,2]}]`)
+			})
+			muxAPI.HandleFunc("/v4/collections/2", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"id":2,"name":"team-x","pipelineIds":[1,2],"pipelines":[{"id":1,"name":"org/a"},{"id":2,"name":"org/b"}]}`)
+			})
+			muxAPI.HandleFunc("/v4/collections/3", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			})
+
+			mockSDContext.APIURL = testAPIServer.URL
+			sdapi, err := New(mockSDContext)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+			c, err := sdapi.FindCollection(context.Background(), v.nameOrID)
+			if (err != nil) != v.expectedErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if c.ID != v.expectedID || len(c.Pipelines) != 2 {
+				t.Errorf("unexpected collection: %#v", c)
+			}
+		})
+	}
+}
+
+func TestRequestCollection(t *testing.T) {
+	request := CollectionRequest{Name: "team-x", PipelineIDs: []int{1, 2}}
+
+	cases := map[string]struct {
+		method           string
+		statusCode       int
+		expectedBody     string
+		expectedRequests int
+		expectedErr      bool
+	}{
+		"Create a collection successfully":           {http.MethodPost, http.StatusCreated, `{"name":"team-x","pipelineIds":[1,2]}`, 1, false},
+		"Update a collection successfully":           {http.MethodPut, http.StatusOK, `{"name":"team-x","pipelineIds":[1,2]}`, 1, false},
+		"Delete a collection successfully":           {http.MethodDelete, http.StatusNoContent, "", 1, false},
+		"Create a collection with a refreshed JWT":   {http.MethodPost, http.StatusUnauthorized, `{"name":"team-x","pipelineIds":[1,2]}`, 2, false},
+		"Failed to update a collection":              {http.MethodPut, http.StatusNotFound, "", 1, true},
+		"Failed to create a forbidden collection":    {http.MethodPost, http.StatusForbidden, "", 1, true},
+		"Failed to create an existing collection":    {http.MethodPost, http.StatusConflict, "", 1, true},
+		"Failed to create a collection by bad input": {http.MethodPost, http.StatusBadRequest, "", 1, true},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			muxAPI := http.NewServeMux()
+			testAPIServer := httptest.NewServer(muxAPI)
+			defer testAPIServer.Close()
+
+			requests := 0
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				statusCode := v.statusCode
+				if statusCode == http.StatusUnauthorized && r.Header.Get("Authorization") == "Bearer thisissdjwttoken" {
+					statusCode = http.StatusCreated
+				}
+				if r.Method != v.method {
+					t.Errorf("method should be %s, but actual is %s", v.method, r.Method)
+				}
+				body, _ := ioutil.ReadAll(r.Body)
+				if v.expectedBody != "" && string(body) != v.expectedBody {
+					t.Errorf("body should be %s, but actual is %s", v.expectedBody, body)
+				}
+				w.WriteHeader(statusCode)
+				if statusCode != http.StatusNoContent {
+					b, _ := json.Marshal(Collection{ID: 2, Name: "team-x", PipelineIDs: []int{1, 2}})
+					w.Write(b)
+				}
+			}
+			muxAPI.HandleFunc("/v4/collections", handler)
+			muxAPI.HandleFunc("/v4/collections/2", handler)
+			muxAPI.HandleFunc("/v4/auth/token", func(w http.ResponseWriter, r *http.Request) {
+				http.ServeFile(w, r, mockSDJWTResponse)
+			})
+
+			mockSDContext.APIURL = testAPIServer.URL
+			sdapi, err := New(mockSDContext)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+
+			var c *Collection
+			switch v.method {
+			case http.MethodPost:
+				c, err = sdapi.CreateCollection(context.Background(), request)
+			case http.MethodPut:
+				c, err = sdapi.UpdateCollection(context.Background(), 2, request)
+			case http.MethodDelete:
+				err = sdapi.DeleteCollection(context.Background(), 2)
+			}
+			if (err != nil) != v.expectedErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if requests != v.expectedRequests {
+				t.Errorf("request should be sent %d times, but actual is %d", v.expectedRequests, requests)
+			}
+			if v.statusCode == http.StatusConflict && (!errors.Is(err, ErrAlreadyExists) || err.Error() != "collection team-x already exists") {
+				t.Errorf("conflict should be an error of the existing collection: %v", err)
+			}
+			if err != nil || v.method == http.MethodDelete {
+				return
+			}
+			if diff := cmp.Diff(&Collection{ID: 2, Name: "team-x", PipelineIDs: []int{1, 2}}, c); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package sdapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CacheTTL is how long responses of a resource type are used without asking the API
+type CacheTTL struct {
+	// Pattern matches paths of the resource type
+	Pattern *regexp.Regexp
+	// TTL is for any response. Finished is for builds which have finished, which never change.
+	TTL      time.Duration
+	Finished time.Duration
+}
+
+// DefaultCacheTTLs are TTLs of resources which change rarely. responses of other paths are not cached.
+// stale responses are revalidated with If-None-Match when the API returned an ETag.
+var DefaultCacheTTLs = []CacheTTL{
+	{Pattern: regexp.MustCompile(`^/v4/pipelines/\d+$`), TTL: 10 * time.Minute},
+	{Pattern: regexp.MustCompile(`^/v4/pipelines/\d+/jobs$`), TTL: 10 * time.Minute},
+	{Pattern: regexp.MustCompile(`^/v4/pipelines$`), TTL: 5 * time.Minute},
+	{Pattern: regexp.MustCompile(`^/v4/jobs/\d+$`), TTL: 10 * time.Minute},
+	{Pattern: regexp.MustCompile(`^/v4/builds/\d+$`), Finished: 24 * time.Hour},
+}
+
+// CacheDir returns the directory of cached responses of a context, such as ~/.cache/sdctl/http/<hash>.
+// it differs by the API token in use, so that responses for another identity are never served after switching tokens.
+func CacheDir(contextName, apiURL, apiToken string) (string, error) {
+	dir, err := cacheRoot()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(contextName + "\x00" + apiURL + "\x00" + apiToken))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])), nil
+}
+
+// ClearCache removes cached responses of all contexts
+func ClearCache() error {
+	dir, err := cacheRoot()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+func cacheRoot() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sdctl", "http"), nil
+}
+
+// cacheEntry is a response saved in a file
+type cacheEntry struct {
+	URL        string      `json:"url"`
+	Time       time.Time   `json:"time"`
+	TTL        int64       `json:"ttl"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+func (e *cacheEntry) fresh(now time.Time) bool {
+	age := now.Sub(e.Time)
+	return age >= 0 && age < time.Duration(e.TTL)
+}
+
+// cacheTransport serves GET requests of resources in ttls from files in dir
+type cacheTransport struct {
+	base http.RoundTripper
+	dir  string
+	ttls []CacheTTL
+	now  func() time.Time
+}
+
+// NewCacheTransport returns a RoundTripper caching responses through base in dir, using them within ttls
+// and revalidating them by ETags. writes to a path remove the cached response of the path.
+// use a directory per context, such as by CacheDir, since responses depend on the user.
+func NewCacheTransport(base http.RoundTripper, dir string, ttls []CacheTTL) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &cacheTransport{base: base, dir: dir, ttls: ttls, now: time.Now}
+}
+
+func (c *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := c.path(req)
+	if req.Method != http.MethodGet {
+		res, err := c.base.RoundTrip(req)
+		if err == nil && res.StatusCode < 300 {
+			os.Remove(path)
+		}
+		return res, err
+	}
+	ttl, ok := c.ttl(req.URL.Path)
+	if !ok {
+		return c.base.RoundTrip(req)
+	}
+
+	entry := c.load(path, req)
+	if entry != nil && entry.fresh(c.now()) {
+		return entry.response(req), nil
+	}
+	if entry != nil && entry.Header.Get("ETag") != "" {
+		// the request of the caller is kept as is for retries
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.Header.Get("ETag"))
+	}
+	res, err := c.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotModified && entry != nil {
+		res.Body.Close()
+		entry.Time = c.now()
+		c.save(path, entry)
+		return entry.response(req), nil
+	}
+	if res.StatusCode != http.StatusOK || strings.Contains(res.Header.Get("Cache-Control"), "no-store") {
+		return res, nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	entry = &cacheEntry{
+		URL:        req.URL.RequestURI(),
+		Time:       c.now(),
+		TTL:        int64(ttl.of(body)),
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Body:       body,
+	}
+	entry.Header.Del("Date")
+	if entry.TTL > 0 || entry.Header.Get("ETag") != "" {
+		c.save(path, entry)
+	}
+	return res, nil
+}
+
+// of returns the TTL of a response body
+func (t CacheTTL) of(body []byte) time.Duration {
+	if t.Finished == 0 {
+		return t.TTL
+	}
+	var build struct {
+		EndTime string `json:"endTime"`
+	}
+	if json.Unmarshal(body, &build) == nil && build.EndTime != "" {
+		return t.Finished
+	}
+	return t.TTL
+}
+
+func (c *cacheTransport) ttl(path string) (CacheTTL, bool) {
+	for _, t := range c.ttls {
+		if t.Pattern.MatchString(path) {
+			return t, true
+		}
+	}
+	return CacheTTL{}, false
+}
+
+// path returns the file of the URL of req. writes remove the file of GET of the same URL without the query.
+func (c *cacheTransport) path(req *http.Request) string {
+	u := req.URL.Path
+	if req.Method == http.MethodGet && req.URL.RawQuery != "" {
+		u += "?" + req.URL.RawQuery
+	}
+	sum := sha256.Sum256([]byte(u))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *cacheTransport) load(path string, req *http.Request) *cacheEntry {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if json.Unmarshal(b, &e) != nil || e.URL != req.URL.RequestURI() {
+		return nil
+	}
+	return &e
+}
+
+// save writes e into path atomically. failures are ignored since the cache only speeds up requests.
+func (c *cacheTransport) save(path string, e *cacheEntry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+	f, err := ioutil.TempFile(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(b)
+	if cerr := f.Close(); err != nil || cerr != nil {
+		return
+	}
+	os.Rename(f.Name(), path)
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
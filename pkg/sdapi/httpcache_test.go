@@ -0,0 +1,138 @@
+package sdapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheTransport(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	notModified := 0
+	etag := `"v1"`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		hits[r.Method+" "+r.URL.Path]++
+		switch r.URL.Path {
+		case "/v4/jobs/1":
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprint(w, `{"id":1,"pipelineId":2,"name":"main"}`)
+		case "/v4/builds/1":
+			fmt.Fprint(w, `{"id":1,"jobId":1,"status":"RUNNING"}`)
+		case "/v4/builds/2":
+			if r.Method == http.MethodPut {
+				fmt.Fprint(w, `{"id":2,"jobId":1,"status":"ABORTED"}`)
+				return
+			}
+			fmt.Fprint(w, `{"id":2,"jobId":1,"status":"SUCCESS","endTime":"2021-08-01T10:00:00Z"}`)
+		case "/v4/pipelines/2/secrets":
+			fmt.Fprint(w, `[]`)
+		case "/v4/events/3":
+			fmt.Fprint(w, `{"id":3,"pipelineId":2}`)
+		}
+	}))
+	defer ts.Close()
+
+	ctx := mockSDContext
+	ctx.APIURL = ts.URL
+	api, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC)
+	api.WrapTransport(func(base http.RoundTripper) http.RoundTripper {
+		c := NewCacheTransport(base, filepath.Join(t.TempDir(), "cache"), DefaultCacheTTLs).(*cacheTransport)
+		c.now = func() time.Time { return now }
+		return c
+	})
+
+	get := func(path string) {
+		t.Helper()
+		res, err := api.request(context.Background(), http.MethodGet, path, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("%s: status code should be 200, but actual is %d", path, res.StatusCode)
+		}
+	}
+
+	// fresh responses are served from the cache
+	get("/v4/jobs/1")
+	get("/v4/jobs/1")
+	if hits["GET /v4/jobs/1"] != 1 {
+		t.Errorf("fresh job should be cached: %v", hits)
+	}
+	// stale responses are revalidated
+	now = now.Add(11 * time.Minute)
+	get("/v4/jobs/1")
+	get("/v4/jobs/1")
+	if hits["GET /v4/jobs/1"] != 2 || notModified != 1 {
+		t.Errorf("stale job should be revalidated once: %v, %d", hits, notModified)
+	}
+	if job, err := api.GetJob(context.Background(), 1); err != nil || job.Name != "main" {
+		t.Errorf("revalidated job should be returned: %+v, %v", job, err)
+	}
+
+	// running builds change
+	get("/v4/builds/1")
+	get("/v4/builds/1")
+	if hits["GET /v4/builds/1"] != 2 {
+		t.Errorf("running build should not be cached: %v", hits)
+	}
+	// finished builds do not, unless they are written
+	get("/v4/builds/2")
+	get("/v4/builds/2")
+	if hits["GET /v4/builds/2"] != 1 {
+		t.Errorf("finished build should be cached: %v", hits)
+	}
+	if err := api.StopBuild(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	get("/v4/builds/2")
+	if hits["GET /v4/builds/2"] != 2 {
+		t.Errorf("written build should be requested again: %v", hits)
+	}
+
+	// other resources are never cached
+	get("/v4/pipelines/2/secrets")
+	get("/v4/pipelines/2/secrets")
+	if hits["GET /v4/pipelines/2/secrets"] != 2 {
+		t.Errorf("secrets should not be cached: %v", hits)
+	}
+	// events change with the statuses of their builds
+	get("/v4/events/3")
+	get("/v4/events/3")
+	if hits["GET /v4/events/3"] != 2 {
+		t.Errorf("events should not be cached: %v", hits)
+	}
+}
+
+func TestCacheDir(t *testing.T) {
+	a, err := CacheDir("default", "https://api.screwdriver.cd", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := CacheDir("test", "https://api.screwdriver.cd", "token")
+	c, _ := CacheDir("default", "https://api.example.com", "token")
+	d, _ := CacheDir("default", "https://api.screwdriver.cd", "pipeline-token")
+	if a == b || a == c || filepath.Dir(a) != filepath.Dir(b) {
+		t.Errorf("contexts should have their own directories: %s, %s, %s", a, b, c)
+	}
+	if a == d {
+		t.Errorf("tokens should have their own directories: %s, %s", a, d)
+	}
+}
@@ -0,0 +1,67 @@
+package sdapi
+
+import (
+	"context"
+)
+
+// Interface is the set of Screwdriver.cd API operations implemented by SDAPI. depend on it to use a fake in tests.
+// paging helpers returning an Iterator are methods of SDAPI only.
+type Interface interface {
+	GetJWT(ctx context.Context) (string, error)
+
+	GetBanners(ctx context.Context) ([]BannerResponse, error)
+	ListBanners(ctx context.Context, opts ListOptions) ([]BannerResponse, error)
+	CreateBanner(ctx context.Context, banner BannerRequest) (BannerResponse, error)
+	UpdateBanner(ctx context.Context, id int, banner BannerRequest) (BannerResponse, error)
+	DeleteBanner(ctx context.Context, id int) error
+
+	ResolvePipelineID(ctx context.Context, nameOrID string) (int, error)
+	ListPipelines(ctx context.Context, search string, opts ListOptions) ([]Pipeline, error)
+	GetPipeline(ctx context.Context, pipelineID int) (*Pipeline, error)
+	GetPipelineJobs(ctx context.Context, pipelineID int) ([]Job, error)
+	GetPipelineEvents(ctx context.Context, pipelineID, count int) ([]Event, error)
+	GetJob(ctx context.Context, jobID int) (*Job, error)
+	GetEvent(ctx context.Context, eventID int) (*Event, error)
+	GetEventBuilds(ctx context.Context, eventID int) ([]Build, error)
+	PostEvent(ctx context.Context, pipelineID int, startFrom string) (*Event, error)
+
+	GetBuild(ctx context.Context, buildID int) (*Build, error)
+	GetBuildSteps(ctx context.Context, buildID int) ([]Step, error)
+	GetStepLogs(ctx context.Context, buildID int, step string, tail bool) ([]LogLine, error)
+	StopBuild(ctx context.Context, buildID int) error
+	GetBuildPage(ctx context.Context, buildID int) (*BuildPage, error)
+	GetBuildPages(ctx context.Context, buildIDs []int, concurrency int, found func(BuildPageResult))
+	BuildPageURL(pipelineID, buildID int) string
+
+	UIURL() string
+	DiscoverUIURL(ctx context.Context) (string, error)
+	UIURLFromAPI(ctx context.Context) (string, error)
+	PipelinePageURL(pipelineID int) string
+	EventPageURL(pipelineID, eventID int) string
+	JobPageURL(pipelineID, jobID int) string
+	TemplatePageURL(fullName string) string
+
+	ValidatePipeline(ctx context.Context, yamlStr string) (*ValidatorResponse, error)
+	ValidatePipelineRaw(ctx context.Context, yamlStr string) (RawValidatorResponse, error)
+	ValidateTemplate(ctx context.Context, yamlStr string) (*TemplateValidatorResponse, error)
+	ListTemplates(ctx context.Context, opts ListOptions) ([]Template, error)
+
+	GetPipelineSecrets(ctx context.Context, pipelineID int) ([]Secret, error)
+	SetSecret(ctx context.Context, pipelineID int, key, value string, allowInPR bool) error
+
+	GetCollections(ctx context.Context) ([]Collection, error)
+	ListCollections(ctx context.Context, opts ListOptions) ([]Collection, error)
+	GetCollection(ctx context.Context, id int) (*Collection, error)
+	FindCollection(ctx context.Context, nameOrID string) (*Collection, error)
+	CreateCollection(ctx context.Context, collection CollectionRequest) (*Collection, error)
+	UpdateCollection(ctx context.Context, id int, collection CollectionRequest) (*Collection, error)
+	DeleteCollection(ctx context.Context, id int) error
+
+	GetTokens(ctx context.Context, pipelineID int) ([]Token, error)
+	ListTokens(ctx context.Context, pipelineID int, opts ListOptions) ([]Token, error)
+	CreateToken(ctx context.Context, pipelineID int, token TokenRequest) (*Token, error)
+	RefreshToken(ctx context.Context, pipelineID, tokenID int) (*Token, error)
+	RevokeToken(ctx context.Context, pipelineID, tokenID int) error
+}
+
+var _ Interface = (*SDAPI)(nil)
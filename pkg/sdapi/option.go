@@ -0,0 +1,88 @@
+package sdapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// DefaultUserAgent is the User-Agent header sent when New is called without WithUserAgent
+const DefaultUserAgent = "sdctl"
+
+// Option configures a SDAPI created by New
+type Option func(*options)
+
+type options struct {
+	httpClient  *http.Client
+	userAgent   string
+	tokenSource TokenSource
+}
+
+// WithHTTPClient sets the HTTP client instead of the one following the network config of the context.
+// it is copied to retry idempotent requests with the retry policy of the context.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = c
+	}
+}
+
+// WithUserAgent sets the User-Agent header of requests
+func WithUserAgent(ua string) Option {
+	return func(o *options) {
+		o.userAgent = ua
+	}
+}
+
+// WithTokenSource sets the source of JWTs instead of the JWT and the API token of the context
+func WithTokenSource(ts TokenSource) Option {
+	return func(o *options) {
+		o.tokenSource = ts
+	}
+}
+
+// TokenSource provides JWTs sent with requests. it must be safe for concurrent use.
+type TokenSource interface {
+	// Token returns the current JWT
+	Token(ctx context.Context) (string, error)
+	// Refresh returns a new JWT after the API rejects the current one
+	Refresh(ctx context.Context) (string, error)
+}
+
+// StaticTokenSource returns a TokenSource of a fixed JWT such as SD_TOKEN in builds. Refresh returns the same JWT.
+func StaticTokenSource(jwt string) TokenSource {
+	return staticTokenSource(jwt)
+}
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token(ctx context.Context) (string, error) {
+	return string(s), nil
+}
+
+func (s staticTokenSource) Refresh(ctx context.Context) (string, error) {
+	return string(s), nil
+}
+
+// contextTokenSource starts from the JWT of the context and exchanges the API token of the context on Refresh
+type contextTokenSource struct {
+	sd  *SDAPI
+	mu  sync.RWMutex
+	jwt string
+}
+
+func (s *contextTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.jwt, nil
+}
+
+func (s *contextTokenSource) Refresh(ctx context.Context) (string, error) {
+	jwt, err := s.sd.GetJWT(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jwt = jwt
+	return jwt, nil
+}
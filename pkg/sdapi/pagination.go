@@ -0,0 +1,168 @@
+package sdapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DefaultPageCount is the number of items requested per page
+const DefaultPageCount = 50
+
+// sort orders of list APIs
+const (
+	SortAscending  = "ascending"
+	SortDescending = "descending"
+)
+
+// ListOptions configures paging of list APIs with page, count and sort query parameters
+type ListOptions struct {
+	// Page is the first page to fetch, default to 1
+	Page int
+	// Count is the number of items per page, default to DefaultPageCount
+	Count int
+	// Sort is SortAscending or SortDescending, default to the order of the API
+	Sort string
+	// Limit is the maximum number of items in total. 0 means all items.
+	Limit int
+}
+
+// Iterator streams items of a list API page by page. stop calling Next to exit early.
+//
+//	it := api.Iterate("/v4/pipelines/1/events", nil, sdapi.ListOptions{})
+//	for it.Next(ctx) {
+//		var e sdapi.Event
+//		if err := it.Scan(&e); err != nil {
+//			return err
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator struct {
+	sd    *SDAPI
+	path  string
+	query url.Values
+	opts  ListOptions
+
+	page  int
+	items []json.RawMessage
+	index int
+	count int
+	last  bool
+	err   error
+	// first is the first item of the previous page, to detect APIs returning the same page for any page
+	first json.RawMessage
+}
+
+// Iterate returns an iterator over items of the list API at path with query
+func (sd *SDAPI) Iterate(path string, query url.Values, opts ListOptions) *Iterator {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.Count < 1 {
+		opts.Count = DefaultPageCount
+	}
+	if opts.Limit > 0 && opts.Limit < opts.Count {
+		opts.Count = opts.Limit
+	}
+	return &Iterator{sd: sd, path: path, query: query, opts: opts, page: opts.Page, index: -1}
+}
+
+// Next advances to the next item, fetching the next page when needed. it returns false at the end or on an error.
+func (it *Iterator) Next(ctx context.Context) bool {
+	if it.err != nil || (it.opts.Limit > 0 && it.count >= it.opts.Limit) {
+		return false
+	}
+	it.index++
+	for it.index >= len(it.items) {
+		if it.last {
+			return false
+		}
+		if err := it.fetch(ctx); err != nil {
+			it.err = err
+			return false
+		}
+		it.index = 0
+	}
+	it.count++
+	return true
+}
+
+// Scan decodes the current item into v
+func (it *Iterator) Scan(v interface{}) error {
+	if it.index < 0 || it.index >= len(it.items) {
+		return fmt.Errorf("no item to scan in %s", it.path)
+	}
+	return json.Unmarshal(it.items[it.index], v)
+}
+
+// Err returns the error which stopped the iteration
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+func (it *Iterator) fetch(ctx context.Context) error {
+	query := url.Values{}
+	for k, v := range it.query {
+		query[k] = v
+	}
+	query.Set("page", strconv.Itoa(it.page))
+	query.Set("count", strconv.Itoa(it.opts.Count))
+	if it.opts.Sort != "" {
+		query.Set("sort", it.opts.Sort)
+	}
+	res, err := it.sd.request(ctx, http.MethodGet, it.path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return fmt.Errorf("%s is %w", it.path, ErrNotFound)
+	default:
+		return fmt.Errorf("GET %s status code is not %d: %d", it.path, http.StatusOK, res.StatusCode)
+	}
+
+	var items []json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&items); err != nil {
+		return err
+	}
+	it.page++
+	// a page starting with the first item of the previous page means the API ignores paging and returns the same items again
+	if len(items) > 0 && it.first != nil && bytes.Equal(items[0], it.first) {
+		it.items = nil
+		it.last = true
+		return nil
+	}
+	it.items = items
+	if len(items) > 0 {
+		it.first = items[0]
+	}
+	// a short page is the last one. a page longer than count means the API ignores paging.
+	it.last = len(items) != it.opts.Count
+	return nil
+}
+
+// ListAll decodes items of the list API at path into v, a pointer to a slice, following pages up to the limit
+func (sd *SDAPI) ListAll(ctx context.Context, path string, query url.Values, opts ListOptions, v interface{}) error {
+	it := sd.Iterate(path, query, opts)
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; it.Next(ctx); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(it.items[it.index])
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	buf.WriteByte(']')
+	return json.Unmarshal(buf.Bytes(), v)
+}
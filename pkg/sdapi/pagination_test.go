@@ -0,0 +1,141 @@
+package sdapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newPagedServer serves items 1 to total with page and count, or all items at once when paged is false
+func newPagedServer(t *testing.T, total int, paged bool, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		q := r.URL.Query()
+		if q.Get("search") != "org" || q.Has("token") {
+			t.Errorf("query should keep search without token: %v", q)
+		}
+		if r.Header.Get("Authorization") != "Bearer "+mockSDJWT {
+			t.Errorf("JWT should be sent in the header: %v", r.Header.Get("Authorization"))
+		}
+		page, _ := strconv.Atoi(q.Get("page"))
+		count, _ := strconv.Atoi(q.Get("count"))
+		start, end := (page-1)*count, page*count
+		if !paged {
+			start, end = 0, total
+		}
+		var items []Event
+		for i := start; i < end && i < total; i++ {
+			items = append(items, Event{ID: i + 1})
+		}
+		b, _ := json.Marshal(items)
+		w.Write(b)
+	}))
+}
+
+func TestListAll(t *testing.T) {
+	cases := map[string]struct {
+		total            int
+		paged            bool
+		opts             ListOptions
+		expectedCount    int
+		expectedRequests int
+	}{
+		"Follow pages":                         {5, true, ListOptions{Count: 2}, 5, 3},
+		"Stop at an empty last page":           {4, true, ListOptions{Count: 2}, 4, 3},
+		"Stop at the limit":                    {5, true, ListOptions{Count: 2, Limit: 3}, 3, 2},
+		"Limit smaller than a page":            {5, true, ListOptions{Limit: 1}, 1, 1},
+		"Start from a page":                    {5, true, ListOptions{Count: 2, Page: 3}, 1, 1},
+		"API ignoring paging":                  {5, false, ListOptions{Count: 2}, 5, 1},
+		"API ignoring paging with a full page": {2, false, ListOptions{Count: 2}, 2, 2},
+		"No items":                             {0, true, ListOptions{}, 0, 1},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			requests := 0
+			testAPIServer := newPagedServer(t, v.total, v.paged, &requests)
+			defer testAPIServer.Close()
+
+			mockSDContext.APIURL = testAPIServer.URL
+			sdapi, err := New(mockSDContext)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+			// the context stops endless paging if it comes back
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			var events []Event
+			if err := sdapi.ListAll(ctx, "/v4/pipelines", map[string][]string{"search": {"org"}}, v.opts, &events); err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != v.expectedCount {
+				t.Errorf("items should be %d, but actual is %d", v.expectedCount, len(events))
+			}
+			if requests != v.expectedRequests {
+				t.Errorf("requests should be %d, but actual is %d", v.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestIterator(t *testing.T) {
+	requests := 0
+	testAPIServer := newPagedServer(t, 10, true, &requests)
+	defer testAPIServer.Close()
+
+	mockSDContext.APIURL = testAPIServer.URL
+	sdapi, err := New(mockSDContext)
+	if err != nil {
+		t.Fatal("should not cause error")
+	}
+
+	var ids []int
+	it := sdapi.Iterate("/v4/pipelines", map[string][]string{"search": {"org"}}, ListOptions{Count: 2})
+	for it.Next(context.Background()) {
+		var e Event
+		if err := it.Scan(&e); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, e.ID)
+		if e.ID == 3 {
+			break
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]int{1, 2, 3}, ids); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if requests != 2 {
+		t.Errorf("pages after the early exit should not be fetched: %d requests", requests)
+	}
+}
+
+func TestIteratorError(t *testing.T) {
+	testAPIServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer testAPIServer.Close()
+
+	mockSDContext.APIURL = testAPIServer.URL
+	sdapi, err := New(mockSDContext)
+	if err != nil {
+		t.Fatal("should not cause error")
+	}
+	it := sdapi.PipelineEvents(1, ListOptions{})
+	if it.Next(context.Background()) {
+		t.Error("Next should be false on an error")
+	}
+	if it.Err() == nil {
+		t.Error("Err should return the error")
+	}
+}
@@ -0,0 +1,232 @@
+package sdapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// build statuses
+const (
+	StatusSuccess  = "SUCCESS"
+	StatusFailure  = "FAILURE"
+	StatusAborted  = "ABORTED"
+	StatusRunning  = "RUNNING"
+	StatusQueued   = "QUEUED"
+	StatusBlocked  = "BLOCKED"
+	StatusCreated  = "CREATED"
+	StatusFrozen   = "FROZEN"
+	StatusUnstable = "UNSTABLE"
+	StatusUnknown  = "UNKNOWN"
+)
+
+// Pipeline represents Pipeline API response schema
+type Pipeline struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	SCMRepo struct {
+		Name   string `json:"name"`
+		Branch string `json:"branch"`
+		URL    string `json:"url"`
+	} `json:"scmRepo"`
+}
+
+// Event represents Event API response schema
+type Event struct {
+	ID           int    `json:"id"`
+	PipelineID   int    `json:"pipelineId"`
+	SHA          string `json:"sha"`
+	CauseMessage string `json:"causeMessage"`
+	CreateTime   string `json:"createTime"`
+	StartFrom    string `json:"startFrom"`
+}
+
+// Build represents Build API response schema
+type Build struct {
+	ID        int    `json:"id"`
+	JobID     int    `json:"jobId"`
+	EventID   int    `json:"eventId"`
+	Status    string `json:"status"`
+	StartTime string `json:"startTime,omitempty"`
+	EndTime   string `json:"endTime,omitempty"`
+}
+
+// EventStatus summarizes statuses of builds in an event
+func EventStatus(builds []Build) string {
+	if len(builds) == 0 {
+		return StatusUnknown
+	}
+	status := StatusSuccess
+	for _, b := range builds {
+		switch b.Status {
+		case StatusFailure, StatusAborted:
+			return b.Status
+		case StatusRunning, StatusQueued, StatusBlocked, StatusCreated:
+			status = StatusRunning
+		}
+	}
+	return status
+}
+
+// ResolvePipelineID returns a pipeline ID from an ID or a repository name such as "org/repo"
+func (sd *SDAPI) ResolvePipelineID(ctx context.Context, nameOrID string) (int, error) {
+	if id, err := strconv.Atoi(nameOrID); err == nil {
+		return id, nil
+	}
+
+	pipelines, err := sd.ListPipelines(ctx, nameOrID, ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	var matched []Pipeline
+	for _, p := range pipelines {
+		if p.Name == nameOrID || p.SCMRepo.Name == nameOrID {
+			matched = append(matched, p)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return 0, fmt.Errorf("pipeline %s is %w", nameOrID, ErrNotFound)
+	case 1:
+		return matched[0].ID, nil
+	default:
+		var ids []string
+		for _, p := range matched {
+			ids = append(ids, fmt.Sprintf("%d (%s)", p.ID, p.SCMRepo.Branch))
+		}
+		return 0, fmt.Errorf("pipeline %s is ambiguous, specify one of IDs: %s", nameOrID, strings.Join(ids, ", "))
+	}
+}
+
+// ListPipelines returns pipelines matching the search such as a repository name, following pages up to the limit
+func (sd *SDAPI) ListPipelines(ctx context.Context, search string, opts ListOptions) ([]Pipeline, error) {
+	var query url.Values
+	if search != "" {
+		query = url.Values{"search": {search}}
+	}
+	var pipelines []Pipeline
+	if err := sd.ListAll(ctx, "/v4/pipelines", query, opts, &pipelines); err != nil {
+		return nil, err
+	}
+	return pipelines, nil
+}
+
+// GetPipelineEvents returns the latest events of a pipeline
+func (sd *SDAPI) GetPipelineEvents(ctx context.Context, pipelineID, count int) ([]Event, error) {
+	var events []Event
+	if err := sd.ListAll(ctx, fmt.Sprintf("/v4/pipelines/%d/events", pipelineID), nil, ListOptions{Limit: count}, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// PipelineEvents returns an iterator over events of a pipeline from the latest
+func (sd *SDAPI) PipelineEvents(pipelineID int, opts ListOptions) *Iterator {
+	return sd.Iterate(fmt.Sprintf("/v4/pipelines/%d/events", pipelineID), nil, opts)
+}
+
+// GetEventBuilds returns builds of an event
+func (sd *SDAPI) GetEventBuilds(ctx context.Context, eventID int) ([]Build, error) {
+	var builds []Build
+	if err := sd.ListAll(ctx, fmt.Sprintf("/v4/events/%d/builds", eventID), nil, ListOptions{}, &builds); err != nil {
+		return nil, err
+	}
+	return builds, nil
+}
+
+// JobBuilds returns an iterator over builds of a job from the latest
+func (sd *SDAPI) JobBuilds(jobID int, opts ListOptions) *Iterator {
+	return sd.Iterate(fmt.Sprintf("/v4/jobs/%d/builds", jobID), nil, opts)
+}
+
+// Job represents Job API response schema
+type Job struct {
+	ID         int    `json:"id"`
+	PipelineID int    `json:"pipelineId"`
+	Name       string `json:"name"`
+	State      string `json:"state"`
+	Archived   bool   `json:"archived"`
+}
+
+// GetPipeline returns a pipeline
+func (sd *SDAPI) GetPipeline(ctx context.Context, pipelineID int) (*Pipeline, error) {
+	path := fmt.Sprintf("/v4/pipelines/%d", pipelineID)
+	res, err := sd.request(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("pipeline %d is %w", pipelineID, ErrNotFound)
+	default:
+		return nil, fmt.Errorf("GET /v4/pipelines/%d status code is not %d: %d", pipelineID, http.StatusOK, res.StatusCode)
+	}
+
+	pipeline := new(Pipeline)
+	if err := json.NewDecoder(res.Body).Decode(pipeline); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+// GetJob returns a job
+func (sd *SDAPI) GetJob(ctx context.Context, jobID int) (*Job, error) {
+	path := fmt.Sprintf("/v4/jobs/%d", jobID)
+	res, err := sd.request(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("job %d is %w", jobID, ErrNotFound)
+	default:
+		return nil, fmt.Errorf("GET /v4/jobs/%d status code is not %d: %d", jobID, http.StatusOK, res.StatusCode)
+	}
+
+	job := new(Job)
+	if err := json.NewDecoder(res.Body).Decode(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetEvent returns an event
+func (sd *SDAPI) GetEvent(ctx context.Context, eventID int) (*Event, error) {
+	path := fmt.Sprintf("/v4/events/%d", eventID)
+	res, err := sd.request(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("event %d is %w", eventID, ErrNotFound)
+	default:
+		return nil, fmt.Errorf("GET /v4/events/%d status code is not %d: %d", eventID, http.StatusOK, res.StatusCode)
+	}
+
+	event := new(Event)
+	if err := json.NewDecoder(res.Body).Decode(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// GetPipelineJobs returns jobs of a pipeline which are not archived
+func (sd *SDAPI) GetPipelineJobs(ctx context.Context, pipelineID int) ([]Job, error) {
+	var jobs []Job
+	query := url.Values{"archived": {"false"}}
+	if err := sd.ListAll(ctx, fmt.Sprintf("/v4/pipelines/%d/jobs", pipelineID), query, ListOptions{}, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
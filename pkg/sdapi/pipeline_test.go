@@ -0,0 +1,127 @@
+package sdapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolvePipelineID(t *testing.T) {
+	pipelines := []map[string]interface{}{
+		{"id": 1, "name": "org/repo", "scmRepo": map[string]string{"name": "org/repo", "branch": "main"}},
+		{"id": 2, "name": "org/repo-two", "scmRepo": map[string]string{"name": "org/repo-two", "branch": "main"}},
+		{"id": 3, "name": "org/dup", "scmRepo": map[string]string{"name": "org/dup", "branch": "main"}},
+		{"id": 4, "name": "org/dup", "scmRepo": map[string]string{"name": "org/dup", "branch": "dev"}},
+	}
+
+	cases := map[string]struct {
+		nameOrID    string
+		expectedID  int
+		expectedErr bool
+		notFound    bool
+	}{
+		"pipeline ID": {"1234", 1234, false, false},
+		"repo name":   {"org/repo", 1, false, false},
+		"not found":   {"org/none", 0, true, true},
+		"ambiguous":   {"org/dup", 0, true, false},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			muxAPI := http.NewServeMux()
+			testAPIServer := httptest.NewServer(muxAPI)
+			defer testAPIServer.Close()
+
+			muxAPI.HandleFunc("/v4/pipelines", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("search") != v.nameOrID {
+					t.Errorf("search should be %s, but actual is %s", v.nameOrID, r.URL.Query().Get("search"))
+				}
+				b, _ := json.Marshal(pipelines)
+				w.Write(b)
+			})
+
+			mockSDContext.APIURL = testAPIServer.URL
+			sdapi, err := New(mockSDContext)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+			id, err := sdapi.ResolvePipelineID(context.Background(), v.nameOrID)
+			if (err != nil) != v.expectedErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if errors.Is(err, ErrNotFound) != v.notFound {
+				t.Errorf("err should be ErrNotFound: %v", err)
+			}
+			if id != v.expectedID {
+				t.Errorf("id should be %d, but actual is %d", v.expectedID, id)
+			}
+		})
+	}
+}
+
+func TestEventStatus(t *testing.T) {
+	cases := map[string]struct {
+		builds   []Build
+		expected string
+	}{
+		"no builds":   {nil, StatusUnknown},
+		"all success": {[]Build{{Status: StatusSuccess}, {Status: StatusSuccess}}, StatusSuccess},
+		"running":     {[]Build{{Status: StatusSuccess}, {Status: StatusQueued}}, StatusRunning},
+		"failure":     {[]Build{{Status: StatusRunning}, {Status: StatusFailure}}, StatusFailure},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			if actual := EventStatus(v.builds); actual != v.expected {
+				t.Errorf("status should be %s, but actual is %s", v.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGetPipelineEventsAndBuilds(t *testing.T) {
+	muxAPI := http.NewServeMux()
+	testAPIServer := httptest.NewServer(muxAPI)
+	defer testAPIServer.Close()
+
+	muxAPI.HandleFunc("/v4/pipelines/1/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("count") != "1" {
+			t.Errorf("count should be 1: %v", r.URL.Query())
+		}
+		fmt.Fprint(w, `[{"id":10,"pipelineId":1,"sha":"abc","causeMessage":"Merged"}]`)
+	})
+	muxAPI.HandleFunc("/v4/events/10/builds", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":100,"jobId":5,"eventId":10,"status":"SUCCESS"}]`)
+	})
+
+	mockSDContext.APIURL = testAPIServer.URL
+	sdapi, err := New(mockSDContext)
+	if err != nil {
+		t.Fatal("should not cause error")
+	}
+
+	events, err := sdapi.GetPipelineEvents(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]Event{{ID: 10, PipelineID: 1, SHA: "abc", CauseMessage: "Merged"}}, events); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	builds, err := sdapi.GetEventBuilds(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]Build{{ID: 100, JobID: 5, EventID: 10, Status: StatusSuccess}}, builds); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,166 @@
+package sdapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+func TestRetryTransport(t *testing.T) {
+	cases := map[string]struct {
+		method           string
+		idempotent       bool
+		statuses         []int
+		expectedAttempts int
+		expectedStatus   int
+	}{
+		"Retry GET on 503":                    {http.MethodGet, false, []int{503, 502, 200}, 3, 200},
+		"Give up after max attempts":          {http.MethodGet, false, []int{504, 504, 504, 200}, 3, 504},
+		"Retry GET on 429":                    {http.MethodGet, false, []int{429, 200}, 2, 200},
+		"Do not retry GET on 500":             {http.MethodGet, false, []int{500, 200}, 1, 500},
+		"Do not retry POST":                   {http.MethodPost, false, []int{503, 201}, 1, 503},
+		"Retry PUT safe to repeat":            {http.MethodPut, true, []int{503, 200}, 2, 200},
+		"Do not retry PUT not safe to repeat": {http.MethodPut, false, []int{503, 200}, 1, 503},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			attempts := 0
+			testAPIServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				if v.method != http.MethodGet && string(body) != `{"status":"ABORTED"}` {
+					t.Errorf("body should be sent in every attempt: %s", body)
+				}
+				if v.statuses[attempts] == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "120")
+				}
+				w.WriteHeader(v.statuses[attempts])
+				attempts++
+			}))
+			defer testAPIServer.Close()
+
+			ctx := mockSDContext
+			ctx.APIURL = testAPIServer.URL
+			// Retry-After is capped by max delay
+			ctx.Retry = &sdctl_context.RetryConfig{MaxDelay: "1ms"}
+			sdapi, err := New(ctx)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+
+			reqCtx := context.Background()
+			if v.idempotent {
+				reqCtx = withIdempotent(reqCtx)
+			}
+			var body io.Reader
+			if v.method != http.MethodGet {
+				body = strings.NewReader(`{"status":"ABORTED"}`)
+			}
+			res, err := sdapi.request(reqCtx, v.method, "/v4/builds/1", nil, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if attempts != v.expectedAttempts {
+				t.Errorf("attempts should be %d, but actual is %d", v.expectedAttempts, attempts)
+			}
+			if res.StatusCode != v.expectedStatus {
+				t.Errorf("status should be %d, but actual is %d", v.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRetryTransportConnectionReset(t *testing.T) {
+	attempts := 0
+	testAPIServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// close the connection without a response
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testAPIServer.Close()
+
+	transport := newRetryTransport(nil, RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	res, err := (&http.Client{Transport: transport}).Get(testAPIServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if attempts != 2 {
+		t.Errorf("attempts should be 2, but actual is %d", attempts)
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	testAPIServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer testAPIServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	transport := newRetryTransport(nil, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour})
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleepContext(ctx, d)
+	}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, testAPIServer.URL, nil)
+	if _, err := (&http.Client{Transport: transport}).Do(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("error should be context.Canceled: %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	transport := newRetryTransport(nil, RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 5: time.Second, 40: time.Second} {
+		for i := 0; i < 20; i++ {
+			if d := transport.backoff(attempt); d < 0 || d > max {
+				t.Errorf("backoff of attempt %d should be in [0, %v]: %v", attempt, max, d)
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	cases := map[string]struct {
+		header   string
+		expected bool
+	}{
+		"seconds":   {"3", true},
+		"HTTP date": {time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), true},
+		"invalid":   {"soon", false},
+		"none":      {"", false},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			if v.header != "" {
+				res.Header.Set("Retry-After", v.header)
+			}
+			d, ok := retryAfter(res)
+			if ok != v.expected {
+				t.Errorf("ok should be %v: %v", v.expected, d)
+			}
+			if ok && (d <= 0 || d > time.Minute) {
+				t.Errorf("unexpected delay: %v", d)
+			}
+		})
+	}
+}
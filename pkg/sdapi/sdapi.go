@@ -1,3 +1,10 @@
+// Package sdapi is a client of Screwdriver.cd APIs.
+//
+//	api, err := sdapi.New(sdctx, sdapi.WithUserAgent("my-tool/1.0"))
+//	if err != nil {
+//		return err
+//	}
+//	banners, err := api.GetBanners(ctx)
 package sdapi
 
 import (
@@ -7,70 +14,135 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
-	"os"
-	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
-	"gopkg.in/yaml.v2"
 )
 
+// ErrNotFound is returned when the resource does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrAlreadyExists is returned when a resource of the same name exists
+var ErrAlreadyExists = errors.New("already exists")
+
+// DefaultTimeout is the timeout of a request when New is called without a HTTP client
+const DefaultTimeout = 30 * time.Second
+
 // Client wraps HTTPClient
 type Client struct {
 	URL        *url.URL
 	HTTPClient *http.Client
 }
 
-// SDAPI has methods for control Screwdriver.cd APIs
+// SDAPI has methods for control Screwdriver.cd APIs. it is safe for concurrent use.
 type SDAPI struct {
-	client *Client
-	sdctx  sdctl_context.SdctlContext
+	client    *Client
+	sdctx     sdctl_context.SdctlContext
+	userAgent string
+	tokens    TokenSource
 }
 
-type validatorResponse map[string]interface{}
+// RawValidatorResponse represents Validator API response as is
+type RawValidatorResponse map[string]interface{}
 
-type templateValidatorResponse struct {
-	Template interface{}             `json:"template"`
-	Errors   []templateValidateError `json:"errors"`
+// Job returns the configs of a job as is, keeping fields which JobConfig does not have. a job has multiple configs only when it uses matrix.
+func (vr RawValidatorResponse) Job(name string) ([]interface{}, bool) {
+	jobs, _ := vr["jobs"].(map[string]interface{})
+	configs, _ := jobs[name].([]interface{})
+	return configs, len(configs) != 0
 }
 
-type templateValidateError struct {
-	Message string      `json:"message"`
-	Path    []string    `json:"path"`
-	Type    string      `json:"type"`
-	Context interface{} `json:"context"`
+// Pipeline decodes the response into ValidatorResponse
+func (vr RawValidatorResponse) Pipeline() (*ValidatorResponse, error) {
+	b, err := json.Marshal(vr)
+	if err != nil {
+		return nil, err
+	}
+	pipeline := new(ValidatorResponse)
+	if err := json.Unmarshal(b, pipeline); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
 }
 
-type tokenResponse struct {
-	JWT string `json:"token"`
+// ValidatorResponse represents Validator API response schema
+type ValidatorResponse struct {
+	Annotations   map[string]interface{} `json:"annotations" yaml:"annotations,omitempty"`
+	Jobs          map[string][]JobConfig `json:"jobs" yaml:"jobs"`
+	WorkflowGraph WorkflowGraph          `json:"workflowGraph" yaml:"workflowGraph"`
+	Errors        []string               `json:"errors" yaml:"errors,omitempty"`
 }
 
-type pipelineResponse struct {
-	Name    string `json:"name"`
-	SCMRepo struct {
-		Name   string `json:"name"`
-		Branch string `json:"branch"`
-		URL    string `json:"url"`
-	} `json:"scmRepo"`
+// JobConfig represents a job definition merged with templates and shared settings
+type JobConfig struct {
+	Image         string                 `json:"image" yaml:"image"`
+	Commands      []JobCommand           `json:"commands" yaml:"commands"`
+	Environment   map[string]string      `json:"environment" yaml:"environment,omitempty"`
+	Secrets       []string               `json:"secrets" yaml:"secrets,omitempty"`
+	Settings      map[string]interface{} `json:"settings" yaml:"settings,omitempty"`
+	Annotations   map[string]interface{} `json:"annotations" yaml:"annotations,omitempty"`
+	Requires      []string               `json:"requires" yaml:"requires,omitempty"`
+	SourcePaths   []string               `json:"sourcePaths" yaml:"sourcePaths,omitempty"`
+	BlockedBy     []string               `json:"blockedBy" yaml:"blockedBy,omitempty"`
+	FreezeWindows []string               `json:"freezeWindows" yaml:"freezeWindows,omitempty"`
+	TemplateID    int                    `json:"templateId" yaml:"templateId,omitempty"`
+	Description   string                 `json:"description" yaml:"description,omitempty"`
 }
 
-type buildResponse struct {
-	EventID int `json:"eventId"`
-	JobID   int `json:"jobId"`
+// JobCommand represents a step of a job
+type JobCommand struct {
+	Name    string `json:"name" yaml:"name"`
+	Command string `json:"command" yaml:"command"`
 }
 
-type jobResponse struct {
-	PipelineID int    `json:"pipelineId"`
-	Name       string `json:"name"`
+// WorkflowGraph represents a workflow graph of a pipeline
+type WorkflowGraph struct {
+	Nodes []WorkflowNode `json:"nodes" yaml:"nodes"`
+	Edges []WorkflowEdge `json:"edges" yaml:"edges"`
+}
+
+// WorkflowNode represents a job or a trigger in a workflow graph
+type WorkflowNode struct {
+	Name string `json:"name" yaml:"name"`
+	ID   int    `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// WorkflowEdge represents a dependency between nodes in a workflow graph
+type WorkflowEdge struct {
+	Src  string `json:"src" yaml:"src"`
+	Dest string `json:"dest" yaml:"dest"`
+	Join bool   `json:"join,omitempty" yaml:"join,omitempty"`
+}
+
+// TemplateValidatorResponse represents Template Validator API response schema
+type TemplateValidatorResponse struct {
+	Template interface{}             `json:"template"`
+	Errors   []TemplateValidateError `json:"errors"`
 }
 
-type eventResponse struct {
-	PipelineID int `json:"pipelineId"`
+// TemplateValidateError represents an error of a template
+type TemplateValidateError struct {
+	Message string      `json:"message"`
+	Path    []string    `json:"path"`
+	Type    string      `json:"type"`
+	Context interface{} `json:"context"`
+}
+
+type tokenResponse struct {
+	JWT string `json:"token"`
 }
 
+// banner scopes
+const (
+	BannerScopeGlobal   = "GLOBAL"
+	BannerScopePipeline = "PIPELINE"
+	BannerScopeBuild    = "BUILD"
+)
+
 // BannerResponse represents Banner API response schema
 type BannerResponse struct {
 	ID         int    `json:"id"`
@@ -79,60 +151,165 @@ type BannerResponse struct {
 	CreateTime string `json:"createTime"`
 	CreatedBy  string `json:"createdBy"`
 	Type       string `json:"type"`
+	Scope      string `json:"scope,omitempty"`
+	ScopeID    int    `json:"scopeId,omitempty"`
+}
+
+// BannerRequest represents Banner API request schema. zero values are not sent.
+type BannerRequest struct {
+	Message  string `json:"message,omitempty"`
+	Type     string `json:"type,omitempty"`
+	IsActive *bool  `json:"isActive,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	ScopeID  int    `json:"scopeId,omitempty"`
 }
 
-// New creates a SDAPI
-func New(sdctx sdctl_context.SdctlContext, httpClient *http.Client) (SDAPI, error) {
+// New creates a SDAPI for the API of sdctx configured by opts
+func New(sdctx sdctl_context.SdctlContext, opts ...Option) (*SDAPI, error) {
+	o := options{userAgent: DefaultUserAgent}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	u, err := url.Parse(sdctx.APIURL)
 	if err != nil {
-		return SDAPI{}, err
+		return nil, err
+	}
+	policy, err := NewRetryPolicy(sdctx.Retry)
+	if err != nil {
+		return nil, err
 	}
 
+	hc := &http.Client{Timeout: DefaultTimeout}
+	if o.httpClient != nil {
+		c := *o.httpClient
+		hc = &c
+	} else {
+		t, err := NewTransport(sdctx.Network)
+		if err != nil {
+			// requests fail instead of New so that commands can fix the config
+			hc.Transport = errTransport{fmt.Errorf("invalid network config: %w", err)}
+		} else {
+			hc.Transport = t
+		}
+	}
+	hc.Transport = newRetryTransport(hc.Transport, policy)
+
 	c := &Client{
 		URL:        u,
-		HTTPClient: http.DefaultClient,
-	}
-	if httpClient != nil {
-		c.HTTPClient = httpClient
+		HTTPClient: hc,
 	}
 
-	s := SDAPI{
-		client: c,
-		sdctx:  sdctx,
+	s := &SDAPI{
+		client:    c,
+		sdctx:     sdctx,
+		userAgent: o.userAgent,
+		tokens:    o.tokenSource,
+	}
+	if s.tokens == nil {
+		s.tokens = &contextTokenSource{sd: s, jwt: sdctx.SDJWT}
 	}
 	return s, nil
 }
 
-func (sd *SDAPI) request(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
-	url, err := sd.client.URL.Parse(path)
-
-	if err != nil {
-		return nil, err
+// NewRetryPolicy returns the policy of config, filling unset fields with DefaultRetryPolicy
+func NewRetryPolicy(config *sdctl_context.RetryConfig) (RetryPolicy, error) {
+	policy := DefaultRetryPolicy
+	if config == nil {
+		return policy, nil
+	}
+	if config.MaxAttempts > 0 {
+		policy.MaxAttempts = config.MaxAttempts
+	}
+	if config.MaxDelay != "" {
+		d, err := time.ParseDuration(config.MaxDelay)
+		if err != nil {
+			return policy, fmt.Errorf("invalid max delay of retries: %v", err)
+		}
+		policy.MaxDelay = d
 	}
+	return policy, nil
+}
+
+// SetTimeout sets the timeout of each request. zero means no timeout. call it before sending requests.
+func (sd *SDAPI) SetTimeout(timeout time.Duration) {
+	sd.client.HTTPClient.Timeout = timeout
+}
+
+// anonymousEndpoints are GET endpoints called without a JWT. every other request sends the JWT in the Authorization header.
+var anonymousEndpoints = map[string]bool{
+	// exchanges an API token for a JWT
+	"/v4/auth/token": true,
+	"/v4/banners":    true,
+}
+
+// anonymous returns whether a request is sent without a JWT
+func anonymous(method, path string) bool {
+	return method == http.MethodGet && anonymousEndpoints[path]
+}
 
-	req, err := http.NewRequest(method, url.String(), body)
+// request sends a request to path with query. path must be escaped and must not have a query.
+// when the API rejects the JWT with 401, the JWT is refreshed by the token source and the request is sent once more.
+func (sd *SDAPI) request(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	u, err := sd.client.URL.Parse(path)
 	if err != nil {
 		return nil, err
 	}
+	u.RawQuery = query.Encode()
 
-	switch method {
-	case http.MethodGet:
-		{
-			req.Header.Add("Accept", "application/json")
+	// the body is kept to be sent again with a refreshed JWT
+	var b []byte
+	if body != nil {
+		if b, err = ioutil.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+	send := func(jwt string) (*http.Response, error) {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(b)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+		if err != nil {
+			return nil, err
+		}
+		if sd.userAgent != "" {
+			req.Header.Set("User-Agent", sd.userAgent)
+		}
+		if method == http.MethodGet {
+			req.Header.Set("Accept", "application/json")
+		} else {
+			req.Header.Set("Content-Type", "application/json")
 		}
-	case http.MethodPost, http.MethodPut, http.MethodDelete:
-		{
-			req.Header.Add("Content-Type", "application/json")
-			req.Header.Add("Authorization", "Bearer "+sd.sdctx.SDJWT)
+		if jwt != "" {
+			req.Header.Set("Authorization", "Bearer "+jwt)
 		}
+		return sd.client.HTTPClient.Do(req)
 	}
 
-	return sd.client.HTTPClient.Do(req)
+	if anonymous(method, u.Path) {
+		return send("")
+	}
+	jwt, err := sd.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := send(jwt)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	res.Body.Close()
+	if jwt, err = sd.tokens.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return send(jwt)
 }
 
-func (sd *SDAPI) GetJWT() (string, error) {
-	path := "/v4/auth/token?api_token=" + sd.sdctx.UserToken
-	res, err := sd.request(context.TODO(), http.MethodGet, path, nil)
+// GetJWT exchanges the API token of the context for a JWT
+func (sd *SDAPI) GetJWT(ctx context.Context) (string, error) {
+	// the API accepts API tokens only in the query
+	query := url.Values{"api_token": {sd.sdctx.APIToken()}}
+	res, err := sd.request(ctx, http.MethodGet, "/v4/auth/token", query, nil)
 	if err != nil {
 		return "", err
 	}
@@ -147,46 +324,49 @@ func (sd *SDAPI) GetJWT() (string, error) {
 	return tokenResponse.JWT, err
 }
 
-func (sd *SDAPI) GetBanners() ([]BannerResponse, error) {
-	path := "/v4/banners"
-	res, err := sd.request(context.TODO(), http.MethodGet, path, nil)
-	if err != nil {
-		return []BannerResponse{}, err
+// GetBanners returns all banners
+func (sd *SDAPI) GetBanners(ctx context.Context) ([]BannerResponse, error) {
+	return sd.ListBanners(ctx, ListOptions{})
+}
+
+// ListBanners returns banners following pages up to the limit
+func (sd *SDAPI) ListBanners(ctx context.Context, opts ListOptions) ([]BannerResponse, error) {
+	var banners []BannerResponse
+	if err := sd.ListAll(ctx, "/v4/banners", nil, opts, &banners); err != nil {
+		return nil, err
 	}
-	defer res.Body.Close()
+	return banners, nil
+}
 
-	banners := new([]BannerResponse)
-	err = json.NewDecoder(res.Body).Decode(banners)
+// CreateBanner creates a banner
+func (sd *SDAPI) CreateBanner(ctx context.Context, banner BannerRequest) (BannerResponse, error) {
+	return sd.requestBanner(ctx, http.MethodPost, "/v4/banners", banner)
+}
 
-	return *banners, err
+// UpdateBanner updates fields of a banner set in the request
+func (sd *SDAPI) UpdateBanner(ctx context.Context, id int, banner BannerRequest) (BannerResponse, error) {
+	return sd.requestBanner(withIdempotent(ctx), http.MethodPut, fmt.Sprintf("/v4/banners/%d", id), banner)
 }
 
-func (sd *SDAPI) UpdateBanner(id, message, bannerType, isActive string, delete, retried bool) (BannerResponse, error) {
-	path := "/v4/banners"
-	method := http.MethodPost
+// DeleteBanner deletes a banner
+func (sd *SDAPI) DeleteBanner(ctx context.Context, id int) error {
+	_, err := sd.requestBanner(ctx, http.MethodDelete, fmt.Sprintf("/v4/banners/%d", id), nil)
+	return err
+}
+
+func (sd *SDAPI) requestBanner(ctx context.Context, method, path string, body interface{}) (BannerResponse, error) {
 	banner := new(BannerResponse)
 
-	body := map[string]string{
-		"type":     bannerType,
-		"isActive": isActive,
-	}
-	if message != "" {
-		body["message"] = message
-	}
-	if id != "" {
-		method = http.MethodPut
-		path = path + "/" + id
-		if delete {
-			method = http.MethodDelete
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return *banner, err
 		}
+		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return *banner, err
-	}
-
-	res, err := sd.request(context.TODO(), method, path, bytes.NewBuffer([]byte(jsonBody)))
+	res, err := sd.request(ctx, method, path, nil, reqBody)
 	if err != nil {
 		return *banner, err
 	}
@@ -195,232 +375,97 @@ func (sd *SDAPI) UpdateBanner(id, message, bannerType, isActive string, delete,
 	switch res.StatusCode {
 	case http.StatusCreated, http.StatusOK:
 		err = json.NewDecoder(res.Body).Decode(banner)
-		fmt.Fprintf(os.Stdout, "Successfully %v a banner ID %v\n", method, banner.ID)
 	case http.StatusNoContent:
-		fmt.Fprintf(os.Stdout, "Successfully %v a banner ID %v\n", method, id)
 	case http.StatusNotFound:
-		err = fmt.Errorf("banner of ID %v is not found", id)
+		err = fmt.Errorf("banner %s is %w", strings.TrimPrefix(path, "/v4/banners/"), ErrNotFound)
 	default:
-		if retried {
-			err = fmt.Errorf("status code should be %d or %d, but actual is %d", http.StatusCreated, http.StatusOK, res.StatusCode)
-			break
-		}
-		sd.sdctx.SDJWT, err = sd.GetJWT()
-		if err != nil {
-			return *banner, err
-		}
-		return sd.UpdateBanner(id, message, bannerType, isActive, delete, true)
+		err = fmt.Errorf("%s %s status code should be %d or %d, but actual is %d", method, path, http.StatusCreated, http.StatusOK, res.StatusCode)
 	}
 
 	return *banner, err
 }
 
-func (sd *SDAPI) PostEvent(pipelineID string, startFrom string, retried bool) error {
+// PostEvent starts an event of a pipeline from a job or a trigger such as ~commit
+func (sd *SDAPI) PostEvent(ctx context.Context, pipelineID int, startFrom string) (*Event, error) {
 	path := "/v4/events"
-	body := map[string]string{
+	body := map[string]interface{}{
 		"pipelineId": pipelineID,
 		"startFrom":  startFrom,
 	}
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	res, err := sd.request(context.TODO(), http.MethodPost, path, bytes.NewBuffer([]byte(jsonBody)))
+	res, err := sd.request(ctx, http.MethodPost, path, nil, bytes.NewBuffer([]byte(jsonBody)))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer res.Body.Close()
 	if res.StatusCode != http.StatusCreated { // 201 is expected as a result of POST /events
-		if retried {
-			return fmt.Errorf("status code should be %d, but actual is %d", http.StatusCreated, res.StatusCode)
-		}
-		sd.sdctx.SDJWT, err = sd.GetJWT()
-		if err != nil {
-			return err
-		}
-		return sd.PostEvent(pipelineID, startFrom, true)
+		return nil, fmt.Errorf("status code should be %d, but actual is %d", http.StatusCreated, res.StatusCode)
 	}
-	defer res.Body.Close()
 
-	return nil
-}
-
-func (sd *SDAPI) Validator(yamlStr string, retried bool, output bool) error {
-	path := "/v4/validator"
-	body := `{"yaml":` + yamlStr + `}`
-
-	res, err := sd.request(context.TODO(), http.MethodPost, path, bytes.NewBuffer([]byte(body)))
-	if err != nil {
-		return err
-	}
-	if res.StatusCode != http.StatusOK {
-		if retried {
-			return fmt.Errorf("status code should be %d, but actual is %d", http.StatusOK, res.StatusCode)
-		}
-		sd.sdctx.SDJWT, err = sd.GetJWT()
-		if err != nil {
-			return err
-		}
-		return sd.Validator(yamlStr, true, output)
+	event := new(Event)
+	if err := json.NewDecoder(res.Body).Decode(event); err != nil {
+		return nil, err
 	}
-	defer res.Body.Close()
+	return event, nil
+}
 
-	var vr validatorResponse
-	if err := json.NewDecoder(res.Body).Decode(&vr); err != nil {
-		return err
+// ValidatePipelineRaw validates a screwdriver.yaml and returns the response as is
+func (sd *SDAPI) ValidatePipelineRaw(ctx context.Context, yamlStr string) (RawValidatorResponse, error) {
+	var vr RawValidatorResponse
+	if err := sd.validate(ctx, "/v4/validator", yamlStr, &vr); err != nil {
+		return nil, err
 	}
 	if vr["errors"] != nil {
-		return fmt.Errorf("%v", vr["errors"])
-	}
-
-	if output {
-		if err := yaml.NewEncoder(os.Stdout).Encode(vr); err != nil {
-			return err
-		}
-	} else {
-		fmt.Fprintln(os.Stdout, "Your screwdriver.yaml is valid🙆")
+		return vr, fmt.Errorf("%v", vr["errors"])
 	}
-	return nil
+	return vr, nil
 }
 
-func (sd *SDAPI) ValidatorTemplate(yaml string, retried bool) error {
-	path := "/v4/validator/template"
-	body := `{"yaml":` + yaml + `}`
-
-	res, err := sd.request(context.TODO(), http.MethodPost, path, bytes.NewBuffer([]byte(body)))
-	if err != nil {
-		return err
-	}
-	if res.StatusCode != http.StatusOK {
-		if retried {
-			return fmt.Errorf("status code should be %d, but actual is %d", http.StatusOK, res.StatusCode)
-		}
-		sd.sdctx.SDJWT, err = sd.GetJWT()
-		if err != nil {
-			return err
-		}
-		return sd.ValidatorTemplate(yaml, true)
-	}
-	defer res.Body.Close()
-
-	tvr := new(templateValidatorResponse)
-	err = json.NewDecoder(res.Body).Decode(tvr)
-	if err != nil {
-		return err
-	}
-	if len(tvr.Errors) != 0 {
-		for i := 0; i < len(tvr.Errors); i++ {
-			fmt.Printf("%v\n", tvr.Errors[i].Message)
-		}
-		return errors.New("invalid template of Screwdriver.cd")
+// ValidatePipeline validates a screwdriver.yaml and returns the expanded pipeline
+func (sd *SDAPI) ValidatePipeline(ctx context.Context, yamlStr string) (*ValidatorResponse, error) {
+	vr := new(ValidatorResponse)
+	if err := sd.validate(ctx, "/v4/validator", yamlStr, vr); err != nil {
+		return nil, err
 	}
-
-	println("Your template is valid🙆")
-
-	return nil
-}
-
-func (sd *SDAPI) GetPipelinePageFromBuildID(buildID string) error {
-	buildIDList := strings.Split(strings.Replace(strings.TrimSpace(buildID), "\n", " ", -1), " ")
-	buildIDLength := len(buildIDList)
-	basePipelineURL := strings.Replace(sd.sdctx.APIURL, "api-cd", "cd", 1) + "/pipelines/"
-
-	var wg sync.WaitGroup
-	wg.Add(buildIDLength)
-
-	exit := make(chan error, buildIDLength)
-	fmt.Fprintf(os.Stdout, "%-80v%-15v\n", "BuildURL", "Repo (Job)")
-	for _, b := range buildIDList {
-		go func(b string) {
-			defer wg.Done()
-
-			br, err := sd.getBuild(b)
-			if err != nil {
-				exit <- err
-				return
-			}
-			jr, err := sd.getJob(br.JobID)
-			if err != nil {
-				exit <- err
-				return
-			}
-			pr, err := sd.getPipeline(jr.PipelineID)
-			if err != nil {
-				exit <- err
-				return
-			}
-			buildURL := fmt.Sprintf("%s%d/builds/%s", basePipelineURL, jr.PipelineID, b)
-			repoJob := fmt.Sprintf("%s (%s)", pr.SCMRepo.Name, jr.Name)
-			fmt.Fprintf(os.Stdout, "%-80v%-15v\n", buildURL, repoJob)
-		}(b)
-	}
-
-	wg.Wait()
-
-	select {
-	case err := <-exit:
-		return err
-	default:
-		return nil
+	if len(vr.Errors) != 0 {
+		return vr, fmt.Errorf("%v", vr.Errors)
 	}
+	return vr, nil
 }
 
-func (sd *SDAPI) getPipeline(pipelineID int) (*pipelineResponse, error) {
-	path := "/v4/pipelines/" + strconv.Itoa(pipelineID) + "?token=" + sd.sdctx.SDJWT
-	res, err := sd.request(context.TODO(), http.MethodGet, path, nil)
-	if err != nil {
+// ValidateTemplate validates a sd-template.yaml. the response is returned with an error when the template is invalid.
+func (sd *SDAPI) ValidateTemplate(ctx context.Context, yamlStr string) (*TemplateValidatorResponse, error) {
+	tvr := new(TemplateValidatorResponse)
+	if err := sd.validate(ctx, "/v4/validator/template", yamlStr, tvr); err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
-
-	pipelineResponse := new(pipelineResponse)
-	err = json.NewDecoder(res.Body).Decode(pipelineResponse)
-
-	return pipelineResponse, err
-}
-
-func (sd *SDAPI) getBuild(buildID string) (*buildResponse, error) {
-	path := "/v4/builds/" + buildID + "?token=" + sd.sdctx.SDJWT
-	res, err := sd.request(context.TODO(), http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
+	if len(tvr.Errors) != 0 {
+		return tvr, errors.New("invalid template of Screwdriver.cd")
 	}
-	defer res.Body.Close()
-
-	buildResponse := new(buildResponse)
-	err = json.NewDecoder(res.Body).Decode(buildResponse)
-
-	return buildResponse, err
+	return tvr, nil
 }
 
-func (sd *SDAPI) getJob(jobID int) (*jobResponse, error) {
-	path := "/v4/jobs/" + strconv.Itoa(jobID) + "?token=" + sd.sdctx.SDJWT
-	res, err := sd.request(context.TODO(), http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	jobResponse := new(jobResponse)
-	err = json.NewDecoder(res.Body).Decode(jobResponse)
-
-	return jobResponse, err
-}
+// validate posts a quoted yaml string to the validator API at path and decodes the response into v
+func (sd *SDAPI) validate(ctx context.Context, path, yamlStr string, v interface{}) error {
+	body := `{"yaml":` + yamlStr + `}`
 
-func (sd *SDAPI) getEvent(eventID int) (*eventResponse, error) {
-	path := "/v4/events/" + strconv.Itoa(eventID) + "?token=" + sd.sdctx.SDJWT
-	res, err := sd.request(context.TODO(), http.MethodGet, path, nil)
+	res, err := sd.request(ctx, http.MethodPost, path, nil, bytes.NewBuffer([]byte(body)))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code should be %d, but actual is %d", http.StatusOK, res.StatusCode)
+	}
 
-	eventResponse := new(eventResponse)
-	err = json.NewDecoder(res.Body).Decode(eventResponse)
-
-	return eventResponse, err
+	return json.NewDecoder(res.Body).Decode(v)
 }
 
+// Secret represents Secret API response schema. values are never returned.
 type Secret struct {
 	ID         int    `json:"id"`
 	PipelineID int    `json:"pipelineId"`
@@ -428,9 +473,18 @@ type Secret struct {
 	AllowInPR  bool   `json:"allowInPR"`
 }
 
-func (sd *SDAPI) SetSecret(pipelineID int, key, value string, allowInPR bool) error {
+// SecretRequest represents Secret API request schema
+type SecretRequest struct {
+	PipelineID int    `json:"pipelineId,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Value      string `json:"value"`
+	AllowInPR  bool   `json:"allowInPR"`
+}
+
+// SetSecret creates a secret of a pipeline, or updates it when the key exists
+func (sd *SDAPI) SetSecret(ctx context.Context, pipelineID int, key, value string, allowInPR bool) error {
 
-	secrets, err := sd.getPipelineSecrets(pipelineID)
+	secrets, err := sd.GetPipelineSecrets(ctx, pipelineID)
 	if err != nil {
 		return err
 	}
@@ -438,27 +492,18 @@ func (sd *SDAPI) SetSecret(pipelineID int, key, value string, allowInPR bool) er
 	duplicatedKeyID, exist := sd.checkKey(secrets, key)
 
 	if !exist {
-		return sd.createSecret(pipelineID, key, value, allowInPR)
+		return sd.createSecret(ctx, pipelineID, key, value, allowInPR)
 	}
 
-	return sd.updateSecret(duplicatedKeyID, value, allowInPR)
+	return sd.updateSecret(ctx, duplicatedKeyID, value, allowInPR)
 }
 
-func (sd *SDAPI) getPipelineSecrets(pipelineID int) ([]Secret, error) {
-	path := fmt.Sprintf("/v4/pipelines/%d/secrets?token=%s", pipelineID, sd.sdctx.SDJWT)
-	res, err := sd.request(context.TODO(), http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GET %s status code is not %d: %d", path, http.StatusOK, res.StatusCode)
-	}
+// GetPipelineSecrets returns secrets of a pipeline
+func (sd *SDAPI) GetPipelineSecrets(ctx context.Context, pipelineID int) ([]Secret, error) {
 	var secrets []Secret
-	if err := json.NewDecoder(res.Body).Decode(&secrets); err != nil {
+	if err := sd.ListAll(ctx, fmt.Sprintf("/v4/pipelines/%d/secrets", pipelineID), nil, ListOptions{}, &secrets); err != nil {
 		return nil, err
 	}
-
 	return secrets, nil
 }
 
@@ -471,18 +516,19 @@ func (sd *SDAPI) checkKey(secrets []Secret, key string) (int, bool) {
 	return 0, false
 }
 
-func (sd *SDAPI) createSecret(pipelineID int, key, value string, allowInPR bool) error {
+func (sd *SDAPI) createSecret(ctx context.Context, pipelineID int, key, value string, allowInPR bool) error {
 	path := "/v4/secrets"
-	body := make(map[string]interface{})
-	body["pipelineId"] = pipelineID
-	body["name"] = key
-	body["value"] = value
-	body["allowInPR"] = allowInPR
+	body := SecretRequest{
+		PipelineID: pipelineID,
+		Name:       key,
+		Value:      value,
+		AllowInPR:  allowInPR,
+	}
 	bodyJSON, err := json.Marshal(&body)
 	if err != nil {
 		return err
 	}
-	res, err := sd.request(context.TODO(), http.MethodPost, path, bytes.NewBuffer(bodyJSON))
+	res, err := sd.request(ctx, http.MethodPost, path, nil, bytes.NewBuffer(bodyJSON))
 	if err != nil {
 		return err
 	}
@@ -494,16 +540,17 @@ func (sd *SDAPI) createSecret(pipelineID int, key, value string, allowInPR bool)
 	return nil
 }
 
-func (sd *SDAPI) updateSecret(secretID int, value string, allowInPR bool) error {
+func (sd *SDAPI) updateSecret(ctx context.Context, secretID int, value string, allowInPR bool) error {
 	path := fmt.Sprintf("/v4/secrets/%d", secretID)
-	body := make(map[string]interface{})
-	body["value"] = value
-	body["allowInPR"] = allowInPR
+	body := SecretRequest{
+		Value:     value,
+		AllowInPR: allowInPR,
+	}
 	bodyJSON, err := json.Marshal(&body)
 	if err != nil {
 		return err
 	}
-	res, err := sd.request(context.TODO(), http.MethodPut, path, bytes.NewBuffer(bodyJSON))
+	res, err := sd.request(withIdempotent(ctx), http.MethodPut, path, nil, bytes.NewBuffer(bodyJSON))
 	if err != nil {
 		return err
 	}
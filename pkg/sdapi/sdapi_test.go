@@ -1,13 +1,16 @@
 package sdapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
@@ -22,7 +25,7 @@ var (
 		APIURL:    mockAPIURL,
 		SDJWT:     mockSDJWT,
 	}
-	mockPipelineID             = "1234"
+	mockPipelineID             = 1234
 	mockStartFrom              = "~commit"
 	mockYaml                   = "jobs:\r\n  main:\r\n    image: node:10\r\n    steps:\r\n      - echo: echo hoge"
 	mockSDJWTResponse          = "testdata/jwt.json"
@@ -48,6 +51,10 @@ func TestNew(t *testing.T) {
 			invalidSDContext,
 			false,
 		},
+		"Failure with an invalid retry config": {
+			sdctl_context.SdctlContext{Retry: &sdctl_context.RetryConfig{MaxDelay: "10"}},
+			false,
+		},
 	}
 
 	for k, v := range cases {
@@ -55,7 +62,7 @@ func TestNew(t *testing.T) {
 		v := v
 
 		t.Run(k, func(t *testing.T) {
-			_, err := New(v.sdctx, nil)
+			_, err := New(v.sdctx)
 			switch v.expectedResult {
 			case true:
 				if err != nil {
@@ -72,6 +79,287 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewWithOptions(t *testing.T) {
+	muxAPI := http.NewServeMux()
+	testAPIServer := httptest.NewServer(muxAPI)
+	defer testAPIServer.Close()
+
+	var userAgent, authorization string
+	muxAPI.HandleFunc("/v4/banners/1", func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := mockSDContext
+	ctx.APIURL = testAPIServer.URL
+	hc := &http.Client{Timeout: time.Second}
+	sdapi, err := New(ctx, WithHTTPClient(hc), WithUserAgent("sdctl-test/1.0"), WithTokenSource(StaticTokenSource("static_jwt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sdapi.DeleteBanner(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if userAgent != "sdctl-test/1.0" {
+		t.Errorf("User-Agent should be set, but actual is '%v'", userAgent)
+	}
+	if authorization != "Bearer static_jwt" {
+		t.Errorf("JWT of the token source should be sent, but actual is '%v'", authorization)
+	}
+	if sdapi.client.HTTPClient == hc || sdapi.client.HTTPClient.Timeout != time.Second || hc.Transport != nil {
+		t.Errorf("HTTP client should be copied with its timeout")
+	}
+}
+
+func TestRequestAuthorization(t *testing.T) {
+	cases := map[string]struct {
+		call          func(ctx context.Context, sdapi *SDAPI) error
+		path          string
+		expectedJWT   bool
+		expectedQuery string
+	}{
+		"GET with JWT": {
+			func(ctx context.Context, sdapi *SDAPI) error { _, err := sdapi.GetBuild(ctx, 1); return err },
+			"/v4/builds/1", true, "",
+		},
+		"GET with query": {
+			func(ctx context.Context, sdapi *SDAPI) error {
+				_, err := sdapi.GetStepLogs(ctx, 1, "a b", true)
+				return err
+			},
+			"/v4/builds/1/steps/a b/logs", true, "sort=descending",
+		},
+		"DELETE with JWT": {
+			func(ctx context.Context, sdapi *SDAPI) error { return sdapi.DeleteBanner(ctx, 1) },
+			"/v4/banners/1", true, "",
+		},
+		"anonymous banners": {
+			func(ctx context.Context, sdapi *SDAPI) error { _, err := sdapi.GetBanners(ctx); return err },
+			"/v4/banners", false, "count=50&page=1",
+		},
+		"anonymous JWT exchange": {
+			func(ctx context.Context, sdapi *SDAPI) error { _, err := sdapi.GetJWT(ctx); return err },
+			"/v4/auth/token", false, "api_token=t%26k",
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			var path, query, authorization string
+			testAPIServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path, query, authorization = r.URL.Path, r.URL.RawQuery, r.Header.Get("Authorization")
+				switch r.Method {
+				case http.MethodDelete:
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					if strings.HasSuffix(r.URL.Path, "/logs") || r.URL.Path == "/v4/banners" {
+						w.Write([]byte("[]"))
+						return
+					}
+					w.Write([]byte("{}"))
+				}
+			}))
+			defer testAPIServer.Close()
+
+			ctx := mockSDContext
+			ctx.APIURL = testAPIServer.URL
+			ctx.UserToken = "t&k"
+			sdapi, err := New(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.call(context.Background(), sdapi); err != nil {
+				t.Fatal(err)
+			}
+			if path != v.path || query != v.expectedQuery {
+				t.Errorf("expected '%v?%v', actual '%v?%v'", v.path, v.expectedQuery, path, query)
+			}
+			if (authorization == "Bearer "+mockSDJWT) != v.expectedJWT {
+				t.Errorf("expectedJWT='%v', actual Authorization='%v'", v.expectedJWT, authorization)
+			}
+		})
+	}
+}
+
+func TestRequestRefreshesExpiredJWT(t *testing.T) {
+	cases := map[string]struct {
+		call func(ctx context.Context, sdapi *SDAPI) error
+		path string
+	}{
+		"GET": {
+			func(ctx context.Context, sdapi *SDAPI) error { _, err := sdapi.GetBuild(ctx, 1); return err },
+			"/v4/builds/1",
+		},
+		"GET of pages": {
+			func(ctx context.Context, sdapi *SDAPI) error {
+				_, err := sdapi.ListCollections(ctx, ListOptions{})
+				return err
+			},
+			"/v4/collections",
+		},
+		"POST": {
+			func(ctx context.Context, sdapi *SDAPI) error {
+				_, err := sdapi.CreateBanner(ctx, BannerRequest{Message: "hello"})
+				return err
+			},
+			"/v4/banners",
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			muxAPI := http.NewServeMux()
+			testAPIServer := httptest.NewServer(muxAPI)
+			defer testAPIServer.Close()
+
+			exchanges := 0
+			muxAPI.HandleFunc("/v4/auth/token", func(w http.ResponseWriter, r *http.Request) {
+				exchanges++
+				http.ServeFile(w, r, mockSDJWTResponse)
+			})
+			var bodies []string
+			muxAPI.HandleFunc(v.path, func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				bodies = append(bodies, string(b))
+				if r.Header.Get("Authorization") != "Bearer thisissdjwttoken" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if r.Method == http.MethodGet && r.URL.Path == "/v4/collections" {
+					w.Write([]byte("[]"))
+					return
+				}
+				w.Write([]byte("{}"))
+			})
+
+			ctx := mockSDContext
+			ctx.APIURL = testAPIServer.URL
+			sdapi, err := New(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.call(context.Background(), sdapi); err != nil {
+				t.Fatalf("request should succeed with the refreshed JWT: %v", err)
+			}
+			if exchanges != 1 || len(bodies) != 2 {
+				t.Errorf("JWT should be refreshed once and the request sent twice, but exchanges=%d requests=%d", exchanges, len(bodies))
+			}
+			if len(bodies) == 2 && bodies[0] != bodies[1] {
+				t.Errorf("body should be sent again as is: '%v' and '%v'", bodies[0], bodies[1])
+			}
+
+			// the refreshed JWT is kept for later requests
+			if err := v.call(context.Background(), sdapi); err != nil {
+				t.Fatal(err)
+			}
+			if exchanges != 1 {
+				t.Errorf("refreshed JWT should be reused, but exchanges=%d", exchanges)
+			}
+		})
+	}
+
+	t.Run("rejected refreshed JWT", func(t *testing.T) {
+		muxAPI := http.NewServeMux()
+		testAPIServer := httptest.NewServer(muxAPI)
+		defer testAPIServer.Close()
+
+		exchanges, requests := 0, 0
+		muxAPI.HandleFunc("/v4/auth/token", func(w http.ResponseWriter, r *http.Request) {
+			exchanges++
+			http.ServeFile(w, r, mockSDJWTResponse)
+		})
+		muxAPI.HandleFunc("/v4/builds/1", func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+
+		ctx := mockSDContext
+		ctx.APIURL = testAPIServer.URL
+		sdapi, err := New(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sdapi.GetBuild(context.Background(), 1); err == nil {
+			t.Errorf("error should not be nil but nil")
+		}
+		if exchanges != 1 || requests != 2 {
+			t.Errorf("JWT should be refreshed only once, but exchanges=%d requests=%d", exchanges, requests)
+		}
+	})
+}
+
+func TestResendOnlyOnUnauthorized(t *testing.T) {
+	calls := map[string]struct {
+		path string
+		call func(ctx context.Context, sdapi *SDAPI) error
+	}{
+		"banner": {"/v4/banners", func(ctx context.Context, sdapi *SDAPI) error {
+			_, err := sdapi.CreateBanner(ctx, BannerRequest{Message: "hello"})
+			return err
+		}},
+		"event": {"/v4/events", func(ctx context.Context, sdapi *SDAPI) error {
+			_, err := sdapi.PostEvent(ctx, mockPipelineID, mockStartFrom)
+			return err
+		}},
+		"validator": {"/v4/validator", func(ctx context.Context, sdapi *SDAPI) error {
+			_, err := sdapi.ValidatePipelineRaw(ctx, `"jobs: {}"`)
+			return err
+		}},
+		"stop": {"/v4/builds/1", func(ctx context.Context, sdapi *SDAPI) error {
+			return sdapi.StopBuild(ctx, 1)
+		}},
+	}
+	statuses := map[int]int{
+		http.StatusUnauthorized:        2,
+		http.StatusBadRequest:          1,
+		http.StatusForbidden:           1,
+		http.StatusConflict:            1,
+		http.StatusInternalServerError: 1,
+	}
+
+	for name, c := range calls {
+		for status, expectedRequests := range statuses {
+			c := c
+			status := status
+			expectedRequests := expectedRequests
+			t.Run(fmt.Sprintf("%s %d", name, status), func(t *testing.T) {
+				muxAPI := http.NewServeMux()
+				testAPIServer := httptest.NewServer(muxAPI)
+				defer testAPIServer.Close()
+
+				requests, exchanges := 0, 0
+				muxAPI.HandleFunc(c.path, func(w http.ResponseWriter, r *http.Request) {
+					requests++
+					w.WriteHeader(status)
+					w.Write([]byte("{}"))
+				})
+				muxAPI.HandleFunc("/v4/auth/token", func(w http.ResponseWriter, r *http.Request) {
+					exchanges++
+					http.ServeFile(w, r, mockSDJWTResponse)
+				})
+
+				ctx := mockSDContext
+				ctx.APIURL = testAPIServer.URL
+				sdapi, err := New(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := c.call(context.Background(), sdapi); err == nil {
+					t.Errorf("error should not be nil but nil")
+				}
+				if requests != expectedRequests || exchanges != expectedRequests-1 {
+					t.Errorf("request should be sent %d times, but requests=%d exchanges=%d", expectedRequests, requests, exchanges)
+				}
+			})
+		}
+	}
+}
+
 func TestGetJWT(t *testing.T) {
 	cases := map[string]struct {
 		expectedResult   bool
@@ -105,12 +393,12 @@ func TestGetJWT(t *testing.T) {
 				http.ServeFile(w, r, v.expectedResponse)
 			})
 
-			sdapi, err := New(mockSDContext, nil)
+			sdapi, err := New(mockSDContext)
 			if err != nil {
 				t.Fatal("should not cause error")
 			}
 
-			jwt, err := sdapi.GetJWT()
+			jwt, err := sdapi.GetJWT(context.Background())
 			switch v.expectedResult {
 			case true:
 				if err != nil {
@@ -164,12 +452,12 @@ func TestGetBanners(t *testing.T) {
 				http.ServeFile(w, r, v.expectedResponse)
 			})
 
-			sdapi, err := New(mockSDContext, nil)
+			sdapi, err := New(mockSDContext)
 			if err != nil {
 				t.Fatal("should not cause error")
 			}
 
-			banners, err := sdapi.GetBanners()
+			banners, err := sdapi.GetBanners(context.Background())
 			switch v.expectedResult {
 			case true:
 				if err != nil {
@@ -191,53 +479,59 @@ func TestGetBanners(t *testing.T) {
 	}
 }
 
-func TestUpdateBanner(t *testing.T) {
-	dummyID := "13"
+func TestRequestBanner(t *testing.T) {
+	dummyID := 13
 	dummyMessage := "Due to planned upgrade of Kubernetes, Screwdriver will be down"
-	dummyIsActive := "false"
-	dummyType := "info"
+	dummyIsActive := false
+	dummyRequest := BannerRequest{
+		Message:  dummyMessage,
+		Type:     "info",
+		IsActive: &dummyIsActive,
+		Scope:    BannerScopePipeline,
+		ScopeID:  1234,
+	}
 
 	cases := map[string]struct {
-		id               string
+		method           string
 		expectedResult   bool
 		expectedResponse string
-		delete           bool
+		expectedBody     string
 	}{
 		"Create a banner successfully": {
-			"",
+			http.MethodPost,
 			true,
 			"testdata/banner_creation.json",
-			false,
+			`{"message":"Due to planned upgrade of Kubernetes, Screwdriver will be down","type":"info","isActive":false,"scope":"PIPELINE","scopeId":1234}`,
 		},
 		"Failed to create a banner": {
-			"",
+			http.MethodPost,
 			false,
 			"testdata/bad_banner_creation.json",
-			false,
+			"",
 		},
 		"Update a banner successfully": {
-			dummyID,
+			http.MethodPut,
 			true,
 			"testdata/banner_patch.json",
-			false,
+			`{"message":"Due to planned upgrade of Kubernetes, Screwdriver will be down","type":"info","isActive":false,"scope":"PIPELINE","scopeId":1234}`,
 		},
 		"Failed to update a banner": {
-			dummyID,
+			http.MethodPut,
 			false,
 			"testdata/banner_not_found.json",
-			false,
+			"",
 		},
 		"Delete a banner successfully": {
-			dummyID,
+			http.MethodDelete,
 			true,
 			"",
-			true,
+			"",
 		},
 		"Failed to delete a banner": {
-			dummyID,
+			http.MethodDelete,
 			false,
 			"testdata/banner_not_found.json",
-			true,
+			"",
 		},
 	}
 
@@ -250,40 +544,65 @@ func TestUpdateBanner(t *testing.T) {
 			testAPIServer := httptest.NewServer(muxAPI)
 			defer testAPIServer.Close()
 
-			path := "/v4/banners/" + v.id
+			path := "/v4/banners"
+			if v.method != http.MethodPost {
+				path = fmt.Sprintf("%s/%d", path, dummyID)
+			}
 			mockSDContext.APIURL = testAPIServer.URL
 			muxAPI.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
-				if !v.expectedResult {
-					if v.id != "" {
-						w.WriteHeader(http.StatusNotFound)
-					} else {
-						w.WriteHeader(http.StatusBadRequest)
+				if r.Method != v.method {
+					t.Errorf("method should be %s, but actual is %s", v.method, r.Method)
+				}
+				if v.expectedBody != "" {
+					body, _ := ioutil.ReadAll(r.Body)
+					if string(body) != v.expectedBody {
+						t.Errorf("body should be %s, but actual is %s", v.expectedBody, body)
 					}
 				}
-				if v.delete {
+				switch {
+				case !v.expectedResult && v.method != http.MethodPost:
+					w.WriteHeader(http.StatusNotFound)
+				case !v.expectedResult:
+					w.WriteHeader(http.StatusBadRequest)
+				case v.method == http.MethodPost:
+					w.WriteHeader(http.StatusCreated)
+				case v.method == http.MethodDelete:
 					w.WriteHeader(http.StatusNoContent)
+					return
 				}
 				http.ServeFile(w, r, v.expectedResponse)
 			})
 
-			sdapi, err := New(mockSDContext, nil)
+			sdapi, err := New(mockSDContext)
 			if err != nil {
 				t.Fatal("should not cause error")
 			}
 
-			banner, err := sdapi.UpdateBanner(dummyID, dummyMessage, dummyType, dummyIsActive, v.delete, false)
+			var banner BannerResponse
+			switch v.method {
+			case http.MethodPost:
+				banner, err = sdapi.CreateBanner(context.Background(), dummyRequest)
+			case http.MethodPut:
+				banner, err = sdapi.UpdateBanner(context.Background(), dummyID, dummyRequest)
+			case http.MethodDelete:
+				err = sdapi.DeleteBanner(context.Background(), dummyID)
+			}
 			switch v.expectedResult {
 			case true:
 				if err != nil {
 					t.Errorf("error should be nil but: '%v'", err)
 				}
 
-				expctedResponseJSONFile, err := ioutil.ReadFile(v.expectedResponse)
-				if err != nil && !v.delete {
-					t.Fatal("should not cause error")
-				}
 				expectedResponseJSON := new(BannerResponse)
-				err = json.Unmarshal(expctedResponseJSONFile, expectedResponseJSON)
+				if v.expectedResponse != "" {
+					expctedResponseJSONFile, err := ioutil.ReadFile(v.expectedResponse)
+					if err != nil {
+						t.Fatal("should not cause error")
+					}
+					if err := json.Unmarshal(expctedResponseJSONFile, expectedResponseJSON); err != nil {
+						t.Fatal(err)
+					}
+				}
 
 				if diff := cmp.Diff(expectedResponseJSON, &banner); diff != "" {
 					t.Errorf("mismatch (-want +got):\n%s", diff)
@@ -372,17 +691,20 @@ func TestPostEvent(t *testing.T) {
 				http.ServeFile(w, r, mockSDJWTResponse)
 			})
 
-			sdapi, err := New(mockSDContext, nil)
+			sdapi, err := New(mockSDContext)
 			if err != nil {
 				t.Fatal("should not cause error")
 			}
 
-			err = sdapi.PostEvent(mockPipelineID, mockStartFrom, false)
+			event, err := sdapi.PostEvent(context.Background(), mockPipelineID, mockStartFrom)
 			switch v.expectedResult {
 			case true:
 				if err != nil {
 					t.Errorf("error should be nil but: '%v'", err)
 				}
+				if event == nil || event.ID != 2127883 {
+					t.Errorf("'%v' is not expected", event)
+				}
 			case false:
 				if err == nil {
 					t.Errorf("error should not be nil but nil")
@@ -394,25 +716,15 @@ func TestPostEvent(t *testing.T) {
 	}
 }
 
-func TestValidator(t *testing.T) {
+func TestValidatePipelineRaw(t *testing.T) {
 	cases := map[string]struct {
 		expectedHTTPResult     bool
-		output                 bool
 		expectedResponse       string
 		expectedRetry          bool
 		expectedRetryResponse  string
 		expectedValidateResult bool
 	}{
 		"POST validate successfully": {
-			true,
-			false,
-			"testdata/validate.json",
-			false,
-			"",
-			true,
-		},
-		"POST validate successfully wich output": {
-			true,
 			true,
 			"testdata/validate.json",
 			false,
@@ -421,14 +733,12 @@ func TestValidator(t *testing.T) {
 		},
 		"Retry successfully after authorization": {
 			true,
-			false,
 			mockSDUnauthorizedResponse,
 			true,
 			"testdata/validate.json",
 			true,
 		},
 		"Failure with bad request": {
-			false,
 			false,
 			mockSDBadRequestResponse,
 			false,
@@ -437,14 +747,12 @@ func TestValidator(t *testing.T) {
 		},
 		"Failure with invalid yaml": {
 			true,
-			false,
 			"testdata/config_parse_error.json",
 			false,
 			"",
 			false,
 		},
 		"Failure with bad request after retrying": {
-			false,
 			false,
 			mockSDUnauthorizedResponse,
 			true,
@@ -489,12 +797,12 @@ func TestValidator(t *testing.T) {
 				http.ServeFile(w, r, mockSDJWTResponse)
 			})
 
-			sdapi, err := New(mockSDContext, nil)
+			sdapi, err := New(mockSDContext)
 			if err != nil {
 				t.Fatal("should not cause error")
 			}
 
-			err = sdapi.Validator(mockYaml, false, v.output)
+			_, err = sdapi.ValidatePipelineRaw(context.Background(), mockYaml)
 			switch v.expectedValidateResult {
 			case true:
 				if err != nil {
@@ -511,7 +819,121 @@ func TestValidator(t *testing.T) {
 	}
 }
 
-func TestValidatorTemplate(t *testing.T) {
+func TestRawValidatorResponse(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/validate_unmodeled.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vr RawValidatorResponse
+	if err := json.Unmarshal(b, &vr); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, ok := vr.Job("main")
+	if !ok || len(jobs) != 1 {
+		t.Fatalf("job main should have a config: %v", jobs)
+	}
+	main, _ := jobs[0].(map[string]interface{})
+	for _, field := range []string{"cache", "provider", "parameters", "annotations"} {
+		if main[field] == nil {
+			t.Errorf("field %s should be kept: %v", field, main)
+		}
+	}
+	if jobs, _ := vr.Job("test"); len(jobs) != 2 {
+		t.Errorf("matrix job should have 2 configs: %v", jobs)
+	}
+	if _, ok := vr.Job("missing"); ok {
+		t.Errorf("missing job should not be found")
+	}
+
+	pipeline, err := vr.Pipeline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pipeline.Jobs["test"]) != 2 || pipeline.Jobs["main"][0].Image != "node:14" || len(pipeline.WorkflowGraph.Edges) != 2 {
+		t.Errorf("response should be decoded: %+v", pipeline)
+	}
+}
+
+func TestValidatePipeline(t *testing.T) {
+	cases := map[string]struct {
+		expectedHTTPResult bool
+		expectedResponse   string
+		expectedJobs       []string
+		expectedEdges      int
+	}{
+		"POST validate successfully": {
+			true,
+			"testdata/validate.json",
+			[]string{"main"},
+			0,
+		},
+		"Failure with invalid yaml": {
+			true,
+			"testdata/config_parse_error.json",
+			[]string{"main"},
+			2,
+		},
+		"Failure with bad request": {
+			false,
+			mockSDBadRequestResponse,
+			nil,
+			0,
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+
+		t.Run(k, func(t *testing.T) {
+			muxAPI := http.NewServeMux()
+			testAPIServer := httptest.NewServer(muxAPI)
+			defer testAPIServer.Close()
+
+			path := "/v4/validator"
+			mockSDContext.APIURL = testAPIServer.URL
+			muxAPI.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+				if !v.expectedHTTPResult {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+				http.ServeFile(w, r, v.expectedResponse)
+			})
+			tokenPath := "/v4/auth/token"
+			muxAPI.HandleFunc(tokenPath, func(w http.ResponseWriter, r *http.Request) {
+				http.ServeFile(w, r, mockSDJWTResponse)
+			})
+
+			sdapi, err := New(mockSDContext)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+
+			vr, err := sdapi.ValidatePipeline(context.Background(), mockYaml)
+			if !v.expectedHTTPResult {
+				if err == nil {
+					t.Errorf("error should not be nil but nil")
+				}
+				return
+			}
+			var jobs []string
+			for name := range vr.Jobs {
+				jobs = append(jobs, name)
+			}
+			if diff := cmp.Diff(v.expectedJobs, jobs); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+			if len(vr.WorkflowGraph.Edges) != v.expectedEdges {
+				t.Errorf("edges should be %d, but actual is %d", v.expectedEdges, len(vr.WorkflowGraph.Edges))
+			}
+			if (len(vr.Errors) != 0) != (err != nil) {
+				t.Errorf("err should be returned with validation errors: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
 	cases := map[string]struct {
 		expectedHTTPResult     bool
 		expectedResponse       string
@@ -592,12 +1014,15 @@ func TestValidatorTemplate(t *testing.T) {
 				http.ServeFile(w, r, mockSDJWTResponse)
 			})
 
-			sdapi, err := New(mockSDContext, nil)
+			sdapi, err := New(mockSDContext)
 			if err != nil {
 				t.Fatal("should not cause error")
 			}
 
-			err = sdapi.ValidatorTemplate(mockYaml, false)
+			tvr, err := sdapi.ValidateTemplate(context.Background(), mockYaml)
+			if v.expectedHTTPResult && tvr == nil {
+				t.Errorf("response should be returned")
+			}
 			switch v.expectedValidateResult {
 			case true:
 				if err != nil {
@@ -641,9 +1066,9 @@ func TestGetPipelineSecrets(t *testing.T) {
 			nil,
 		},
 		"Failed to get secrets because of invalid status code": {
-			http.StatusUnauthorized,
+			http.StatusForbidden,
 			nil,
-			fmt.Errorf("GET /v4/pipelines/%d/secrets?token=invalid_jwt status code is not %d: %d", pipelineID, http.StatusOK, http.StatusUnauthorized),
+			fmt.Errorf("GET /v4/pipelines/%d/secrets status code is not %d: %d", pipelineID, http.StatusOK, http.StatusForbidden),
 		},
 	}
 
@@ -664,11 +1089,11 @@ func TestGetPipelineSecrets(t *testing.T) {
 			})
 
 			mockSDContext.APIURL = testAPIServer.URL
-			sdapi, err := New(mockSDContext, nil)
+			sdapi, err := New(mockSDContext)
 			if err != nil {
 				t.Fatal("should not cause error")
 			}
-			secrets, err := sdapi.getPipelineSecrets(pipelineID)
+			secrets, err := sdapi.GetPipelineSecrets(context.Background(), pipelineID)
 			if !reflect.DeepEqual(err, v.expectErr) {
 				t.Errorf("err should be %#v, but actual is %#v", v.expectErr, err)
 			}
@@ -694,8 +1119,8 @@ func TestCreateSecret(t *testing.T) {
 			nil,
 		},
 		"Failed to create a secrets because of invalid status code": {
-			http.StatusUnauthorized,
-			fmt.Errorf("POST /v4/secrets status code is not %d: %d", http.StatusCreated, http.StatusUnauthorized),
+			http.StatusForbidden,
+			fmt.Errorf("POST /v4/secrets status code is not %d: %d", http.StatusCreated, http.StatusForbidden),
 		},
 	}
 
@@ -713,11 +1138,11 @@ func TestCreateSecret(t *testing.T) {
 			})
 
 			mockSDContext.APIURL = testAPIServer.URL
-			sdapi, err := New(mockSDContext, nil)
+			sdapi, err := New(mockSDContext)
 			if err != nil {
 				t.Fatal("should not cause error")
 			}
-			actual := sdapi.createSecret(pipelineID, key, value, allowInPR)
+			actual := sdapi.createSecret(context.Background(), pipelineID, key, value, allowInPR)
 			if !reflect.DeepEqual(actual, v.expectErr) {
 				t.Errorf("err should be %#v, but actual is %#v", v.expectErr, actual)
 			}
@@ -739,8 +1164,8 @@ func TestUpdateSecret(t *testing.T) {
 			nil,
 		},
 		"Failed to update a secret because of invalid status code": {
-			http.StatusUnauthorized,
-			fmt.Errorf("PUT /v4/secrets/%d status code is not %d: %d", secretID, http.StatusOK, http.StatusUnauthorized),
+			http.StatusForbidden,
+			fmt.Errorf("PUT /v4/secrets/%d status code is not %d: %d", secretID, http.StatusOK, http.StatusForbidden),
 		},
 	}
 
@@ -758,11 +1183,11 @@ func TestUpdateSecret(t *testing.T) {
 			})
 
 			mockSDContext.APIURL = testAPIServer.URL
-			sdapi, err := New(mockSDContext, nil)
+			sdapi, err := New(mockSDContext)
 			if err != nil {
 				t.Fatal("should not cause error")
 			}
-			actual := sdapi.updateSecret(secretID, value, allowInPR)
+			actual := sdapi.updateSecret(context.Background(), secretID, value, allowInPR)
 			if !reflect.DeepEqual(actual, v.expectErr) {
 				t.Errorf("err should be %#v, but actual is %#v", v.expectErr, actual)
 			}
@@ -872,11 +1297,11 @@ func TestSetSecret(t *testing.T) {
 			}
 
 			mockSDContext.APIURL = testAPIServer.URL
-			sdapi, err := New(mockSDContext, nil)
+			sdapi, err := New(mockSDContext)
 			if err != nil {
 				t.Fatal("should not cause error")
 			}
-			actual := sdapi.SetSecret(pipelineID, v.key, value, allowInPR)
+			actual := sdapi.SetSecret(context.Background(), pipelineID, v.key, value, allowInPR)
 			if !reflect.DeepEqual(err, v.expectErr) {
 				t.Errorf("err should be %#v, but actual is %#v", v.expectErr, actual)
 			}
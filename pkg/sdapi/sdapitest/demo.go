@@ -0,0 +1,48 @@
+package sdapitest
+
+import (
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+// NewDemoServer returns a Server with pipelines in a collection, their events and builds in various statuses, a banner and a template
+func NewDemoServer() *Server {
+	s := NewServer()
+
+	web := s.AddPipeline("demo/web")
+	s.AddJob(web.ID, "main", "~commit", "~pr")
+	s.AddJob(web.ID, "publish", "main")
+	api := s.AddPipeline("demo/api")
+	s.AddJob(api.ID, "test", "~commit", "~pr")
+	s.AddJob(api.ID, "deploy", "test")
+	s.AddCollection("demo", web.ID, api.ID)
+
+	s.demoEvent(web.ID, "~commit", sdapi.StatusSuccess)
+	s.demoEvent(web.ID, "~commit", sdapi.StatusRunning)
+	s.demoEvent(api.ID, "~commit", sdapi.StatusFailure)
+	s.demoEvent(api.ID, "deploy", sdapi.StatusQueued)
+
+	s.AddBanner("Welcome to the fake Screwdriver.cd", true)
+	s.AddTemplate(Template{
+		Namespace:   "demo",
+		Name:        "node",
+		Version:     "1.0.0",
+		Description: "run npm test",
+		Maintainer:  "demo@example.com",
+		Config: map[string]interface{}{
+			"image": "node:16",
+			"steps": []interface{}{map[string]interface{}{"test": "npm test"}},
+		},
+	})
+	return s
+}
+
+// demoEvent starts an event and sets the status of its builds
+func (s *Server) demoEvent(pipelineID int, startFrom, status string) {
+	_, builds, err := s.StartEvent(pipelineID, startFrom)
+	if err != nil {
+		panic(err)
+	}
+	for _, b := range builds {
+		s.SetBuildStatus(b.ID, status)
+	}
+}
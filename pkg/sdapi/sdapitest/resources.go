@@ -0,0 +1,540 @@
+package sdapitest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+func (s *Server) serveAuth(w http.ResponseWriter, r *http.Request, rest []string) {
+	if len(rest) != 1 || rest[0] != "token" || r.Method != http.MethodGet {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if !s.apiTokens[r.URL.Query().Get("api_token")] {
+		writeError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"token": JWT})
+}
+
+func (s *Server) serveBanners(w http.ResponseWriter, r *http.Request, id int, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		var ids []int
+		for id := range s.banners {
+			ids = append(ids, id)
+		}
+		writeList(w, r, ids, false, func(id int) interface{} { return s.banners[id] })
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		var req sdapi.BannerRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if req.Message == "" {
+			writeError(w, http.StatusBadRequest, `"message" is required`)
+			return
+		}
+		banner := &sdapi.BannerResponse{
+			ID:         s.newID(),
+			CreateTime: s.now(),
+			CreatedBy:  "fake-user",
+			Type:       "info",
+			Scope:      sdapi.BannerScopeGlobal,
+		}
+		applyBanner(banner, req)
+		s.banners[banner.ID] = banner
+		writeJSON(w, http.StatusCreated, banner)
+	case len(rest) == 1:
+		banner, ok := s.banners[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Banner does not exist")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, banner)
+		case http.MethodPut:
+			var req sdapi.BannerRequest
+			if !decode(w, r, &req) {
+				return
+			}
+			applyBanner(banner, req)
+			writeJSON(w, http.StatusOK, banner)
+		case http.MethodDelete:
+			delete(s.banners, id)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusNotFound, "Not Found")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// applyBanner sets fields of the request which are not zero values
+func applyBanner(banner *sdapi.BannerResponse, req sdapi.BannerRequest) {
+	if req.Message != "" {
+		banner.Message = req.Message
+	}
+	if req.Type != "" {
+		banner.Type = req.Type
+	}
+	if req.IsActive != nil {
+		banner.IsActive = *req.IsActive
+	}
+	if req.Scope != "" {
+		banner.Scope = req.Scope
+	}
+	if req.ScopeID != 0 {
+		banner.ScopeID = req.ScopeID
+	}
+}
+
+func (s *Server) servePipelines(w http.ResponseWriter, r *http.Request, id int, rest []string) {
+	if len(rest) == 0 {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		search := r.URL.Query().Get("search")
+		var ids []int
+		for id, p := range s.pipelines {
+			if strings.Contains(p.Name, search) {
+				ids = append(ids, id)
+			}
+		}
+		writeList(w, r, ids, false, func(id int) interface{} { return s.pipelines[id] })
+		return
+	}
+
+	pipeline, ok := s.pipelines[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Pipeline does not exist")
+		return
+	}
+	if len(rest) > 1 && rest[1] == "tokens" {
+		s.serveTokens(w, r, id, rest[2:])
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if len(rest) == 1 {
+		writeJSON(w, http.StatusOK, pipeline)
+		return
+	}
+
+	var ids []int
+	switch rest[1] {
+	case "jobs":
+		archived := r.URL.Query().Get("archived")
+		for id, j := range s.jobs {
+			if j.PipelineID == pipeline.ID && (archived == "" || archived == fmt.Sprint(j.Archived)) {
+				ids = append(ids, id)
+			}
+		}
+		writeList(w, r, ids, false, func(id int) interface{} { return s.jobs[id].Job })
+	case "events":
+		for id, e := range s.events {
+			if e.PipelineID == pipeline.ID {
+				ids = append(ids, id)
+			}
+		}
+		writeList(w, r, ids, true, func(id int) interface{} { return s.events[id] })
+	case "secrets":
+		for id, sec := range s.secrets {
+			if sec.PipelineID == pipeline.ID {
+				ids = append(ids, id)
+			}
+		}
+		writeList(w, r, ids, false, func(id int) interface{} { return s.secrets[id].Secret })
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) serveJobs(w http.ResponseWriter, r *http.Request, id int, rest []string) {
+	j, ok := s.jobs[id]
+	if len(rest) == 0 || r.Method != http.MethodGet {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "Job does not exist")
+		return
+	}
+	switch {
+	case len(rest) == 1:
+		writeJSON(w, http.StatusOK, j.Job)
+	case len(rest) == 2 && rest[1] == "builds":
+		var ids []int
+		for id, b := range s.builds {
+			if b.JobID == j.ID {
+				ids = append(ids, id)
+			}
+		}
+		writeList(w, r, ids, true, func(id int) interface{} { return s.builds[id] })
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request, id int, rest []string) {
+	if len(rest) == 0 {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		var req struct {
+			PipelineID int    `json:"pipelineId"`
+			StartFrom  string `json:"startFrom"`
+		}
+		if !decode(w, r, &req) {
+			return
+		}
+		if _, ok := s.pipelines[req.PipelineID]; !ok {
+			writeError(w, http.StatusNotFound, "Pipeline does not exist")
+			return
+		}
+		event, err := s.startEvent(req.PipelineID, req.StartFrom)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, event)
+		return
+	}
+
+	event, ok := s.events[id]
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "Event does not exist")
+		return
+	}
+	switch {
+	case len(rest) == 1:
+		writeJSON(w, http.StatusOK, event)
+	case len(rest) == 2 && rest[1] == "builds":
+		var ids []int
+		for id, b := range s.builds {
+			if b.EventID == event.ID {
+				ids = append(ids, id)
+			}
+		}
+		writeList(w, r, ids, false, func(id int) interface{} { return s.builds[id] })
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) serveBuilds(w http.ResponseWriter, r *http.Request, id int, rest []string) {
+	if len(rest) == 0 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	build, ok := s.builds[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Build does not exist")
+		return
+	}
+	switch {
+	case len(rest) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, build)
+	case len(rest) == 1 && r.Method == http.MethodPut:
+		var req struct {
+			Status string `json:"status"`
+		}
+		if !decode(w, r, &req) {
+			return
+		}
+		if req.Status == "" {
+			writeError(w, http.StatusBadRequest, `"status" is required`)
+			return
+		}
+		s.setBuildStatus(build, req.Status)
+		writeJSON(w, http.StatusOK, build)
+	case len(rest) == 2 && rest[1] == "steps" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.steps(build))
+	case len(rest) == 4 && rest[1] == "steps" && rest[3] == "logs" && r.Method == http.MethodGet:
+		for _, step := range s.steps(build) {
+			if step.Name == rest[2] {
+				writeJSON(w, http.StatusOK, logs(step, r.URL.Query().Get("sort") == sdapi.SortDescending))
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Step does not exist")
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) serveSecrets(w http.ResponseWriter, r *http.Request, id int, rest []string) {
+	if len(rest) == 0 {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		var req sdapi.SecretRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if req.Name == "" {
+			writeError(w, http.StatusBadRequest, `"name" is required`)
+			return
+		}
+		if _, ok := s.pipelines[req.PipelineID]; !ok {
+			writeError(w, http.StatusNotFound, "Pipeline does not exist")
+			return
+		}
+		for _, sec := range s.secrets {
+			if sec.PipelineID == req.PipelineID && sec.Name == req.Name {
+				writeError(w, http.StatusConflict, fmt.Sprintf("Secret already exists with the ID: %d", sec.ID))
+				return
+			}
+		}
+		sec := &secret{
+			Secret: sdapi.Secret{ID: s.newID(), PipelineID: req.PipelineID, Name: req.Name, AllowInPR: req.AllowInPR},
+			value:  req.Value,
+		}
+		s.secrets[sec.ID] = sec
+		writeJSON(w, http.StatusCreated, sec.Secret)
+		return
+	}
+
+	sec, ok := s.secrets[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Secret does not exist")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, sec.Secret)
+	case http.MethodPut:
+		var req sdapi.SecretRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		sec.value = req.Value
+		sec.AllowInPR = req.AllowInPR
+		writeJSON(w, http.StatusOK, sec.Secret)
+	case http.MethodDelete:
+		delete(s.secrets, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) serveCollections(w http.ResponseWriter, r *http.Request, id int, rest []string) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			var ids []int
+			for id := range s.collections {
+				ids = append(ids, id)
+			}
+			writeList(w, r, ids, false, func(id int) interface{} { return s.collections[id] })
+		case http.MethodPost:
+			var req sdapi.CollectionRequest
+			if !decode(w, r, &req) {
+				return
+			}
+			if req.Name == "" {
+				writeError(w, http.StatusBadRequest, `"name" is required`)
+				return
+			}
+			for _, c := range s.collections {
+				if c.Name == req.Name {
+					writeError(w, http.StatusConflict, fmt.Sprintf("Collection already exists with the ID: %d", c.ID))
+					return
+				}
+			}
+			c := &sdapi.Collection{ID: s.newID(), Name: req.Name, Description: req.Description, Type: "normal", PipelineIDs: req.PipelineIDs}
+			if c.PipelineIDs == nil {
+				c.PipelineIDs = []int{}
+			}
+			s.collections[c.ID] = c
+			writeJSON(w, http.StatusCreated, c)
+		default:
+			writeError(w, http.StatusNotFound, "Not Found")
+		}
+		return
+	}
+
+	c, ok := s.collections[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Collection does not exist")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		withPipelines := *c
+		withPipelines.Pipelines = []sdapi.Pipeline{}
+		for _, pid := range c.PipelineIDs {
+			if p, ok := s.pipelines[pid]; ok {
+				withPipelines.Pipelines = append(withPipelines.Pipelines, *p)
+			}
+		}
+		writeJSON(w, http.StatusOK, withPipelines)
+	case http.MethodPut:
+		var req sdapi.CollectionRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if req.Name != "" {
+			c.Name = req.Name
+		}
+		if req.Description != "" {
+			c.Description = req.Description
+		}
+		if req.PipelineIDs != nil {
+			c.PipelineIDs = req.PipelineIDs
+		}
+		writeJSON(w, http.StatusOK, c)
+	case http.MethodDelete:
+		delete(s.collections, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// serveTokens serves tokens of a pipeline, or of the user when pipelineID is 0
+func (s *Server) serveTokens(w http.ResponseWriter, r *http.Request, pipelineID int, rest []string) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			var ids []int
+			for id, t := range s.tokens {
+				if t.PipelineID == pipelineID {
+					ids = append(ids, id)
+				}
+			}
+			writeList(w, r, ids, false, func(id int) interface{} {
+				t := *s.tokens[id]
+				t.Value = ""
+				return t
+			})
+		case http.MethodPost:
+			var req sdapi.TokenRequest
+			if !decode(w, r, &req) {
+				return
+			}
+			if req.Name == "" {
+				writeError(w, http.StatusBadRequest, `"name" is required`)
+				return
+			}
+			for _, t := range s.tokens {
+				if t.PipelineID == pipelineID && t.Name == req.Name {
+					writeError(w, http.StatusConflict, fmt.Sprintf("Token %s already exists", req.Name))
+					return
+				}
+			}
+			t := &sdapi.Token{ID: s.newID(), Name: req.Name, Description: req.Description, PipelineID: pipelineID}
+			s.setTokenValue(t)
+			s.tokens[t.ID] = t
+			writeJSON(w, http.StatusCreated, t)
+		default:
+			writeError(w, http.StatusNotFound, "Not Found")
+		}
+		return
+	}
+
+	var id int
+	if _, err := fmt.Sscan(rest[0], &id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id "+rest[0])
+		return
+	}
+	t, ok := s.tokens[id]
+	if !ok || t.PipelineID != pipelineID {
+		writeError(w, http.StatusNotFound, "Token does not exist")
+		return
+	}
+	switch {
+	case len(rest) == 2 && rest[1] == "refresh" && r.Method == http.MethodPut:
+		delete(s.apiTokens, t.Value)
+		s.setTokenValue(t)
+		writeJSON(w, http.StatusOK, t)
+	case len(rest) == 1 && r.Method == http.MethodDelete:
+		delete(s.apiTokens, t.Value)
+		delete(s.tokens, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// setTokenValue generates a new value of a token which is exchanged for JWT
+func (s *Server) setTokenValue(t *sdapi.Token) {
+	t.Value = fmt.Sprintf("fake-token-%d-%d", t.ID, s.newID())
+	s.apiTokens[t.Value] = true
+}
+
+// serveTemplates serves templates. names including a namespace such as "sd/node" are escaped in the path.
+func (s *Server) serveTemplates(w http.ResponseWriter, r *http.Request, rest []string) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			var ids []int
+			byID := make(map[int]*Template)
+			for _, versions := range s.templates {
+				for _, t := range versions {
+					ids = append(ids, t.ID)
+					byID[t.ID] = t
+				}
+			}
+			writeList(w, r, ids, false, func(id int) interface{} { return byID[id] })
+		case http.MethodPost:
+			var req Template
+			if !decode(w, r, &req) {
+				return
+			}
+			if req.Name == "" || req.Version == "" {
+				writeError(w, http.StatusBadRequest, `"name" and "version" are required`)
+				return
+			}
+			t := s.addTemplate(req)
+			writeJSON(w, http.StatusCreated, t)
+		default:
+			writeError(w, http.StatusNotFound, "Not Found")
+		}
+		return
+	}
+
+	// the path is split after unescaping, so split the escaped one again
+	escaped := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/v4/templates/"), "/")
+	name, err := url.PathUnescape(escaped[0])
+	if err != nil || r.Method != http.MethodGet || len(escaped) > 2 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	versions, ok := s.templates[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Template does not exist")
+		return
+	}
+	if len(escaped) == 1 {
+		writeJSON(w, http.StatusOK, versions)
+		return
+	}
+	version := escaped[1]
+	if version == "latest" {
+		writeJSON(w, http.StatusOK, versions[len(versions)-1])
+		return
+	}
+	for _, t := range versions {
+		if t.Version == version {
+			writeJSON(w, http.StatusOK, t)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Template does not exist")
+}
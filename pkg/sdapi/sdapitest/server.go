@@ -0,0 +1,351 @@
+// Package sdapitest provides an in-memory fake of Screwdriver.cd API for tests and demos.
+//
+//	fake := sdapitest.NewServer()
+//	p := fake.AddPipeline("org/repo")
+//	fake.AddJob(p.ID, "main", "~commit")
+//	ts := httptest.NewServer(fake)
+//	defer ts.Close()
+//	api, err := sdapi.New(fake.Context(ts.URL))
+package sdapitest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+// credentials accepted by a Server
+const (
+	APIToken = "fake-api-token"
+	JWT      = "fake-jwt"
+)
+
+// Server is an http.Handler serving Screwdriver.cd API from resources kept in memory. it is safe for concurrent use.
+type Server struct {
+	// Now returns the time of created resources, default to time.Now
+	Now func() time.Time
+
+	mu          sync.Mutex
+	nextID      int
+	apiTokens   map[string]bool
+	banners     map[int]*sdapi.BannerResponse
+	pipelines   map[int]*sdapi.Pipeline
+	jobs        map[int]*job
+	events      map[int]*sdapi.Event
+	builds      map[int]*sdapi.Build
+	secrets     map[int]*secret
+	collections map[int]*sdapi.Collection
+	tokens      map[int]*sdapi.Token
+	templates   map[string][]*Template
+	failures    []*failure
+}
+
+type job struct {
+	sdapi.Job
+	requires []string
+}
+
+type secret struct {
+	sdapi.Secret
+	value string
+}
+
+// Template represents Template API response schema
+type Template struct {
+	ID          int                    `json:"id"`
+	Name        string                 `json:"name"`
+	Namespace   string                 `json:"namespace,omitempty"`
+	Version     string                 `json:"version"`
+	Description string                 `json:"description"`
+	Maintainer  string                 `json:"maintainer"`
+	Config      map[string]interface{} `json:"config"`
+	CreateTime  string                 `json:"createTime"`
+}
+
+type failure struct {
+	method    string
+	pattern   string
+	status    int
+	remaining int
+}
+
+// NewServer returns a Server without resources. APIToken is exchanged for JWT.
+func NewServer() *Server {
+	return &Server{
+		Now:         time.Now,
+		apiTokens:   map[string]bool{APIToken: true},
+		banners:     make(map[int]*sdapi.BannerResponse),
+		pipelines:   make(map[int]*sdapi.Pipeline),
+		jobs:        make(map[int]*job),
+		events:      make(map[int]*sdapi.Event),
+		builds:      make(map[int]*sdapi.Build),
+		secrets:     make(map[int]*secret),
+		collections: make(map[int]*sdapi.Collection),
+		tokens:      make(map[int]*sdapi.Token),
+		templates:   make(map[string][]*Template),
+	}
+}
+
+// Context returns a context of sdctl to call the server at url
+func (s *Server) Context(url string) sdctl_context.SdctlContext {
+	return sdctl_context.SdctlContext{
+		UserToken: APIToken,
+		APIURL:    url,
+		SDJWT:     JWT,
+	}
+}
+
+// Fail makes the next n requests matching method and pattern fail with status.
+// pattern is matched with path.Match such as "/v4/builds/*", an empty method matches any method and n < 1 means every request.
+func (s *Server) Fail(method, pattern string, status, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &failure{method: method, pattern: pattern, status: status, remaining: n})
+}
+
+// ClearFailures removes failures set by Fail
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// ServeHTTP serves Screwdriver.cd API
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if status, ok := s.injectedFailure(r); ok {
+		writeError(w, status, "injected failure")
+		return
+	}
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "v4" {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if !s.anonymous(r) && !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "Missing authentication")
+		return
+	}
+	if r.Method != http.MethodGet {
+		s.route(w, r, segments[1:])
+		return
+	}
+	ew := &etagWriter{header: http.Header{}}
+	s.route(ew, r, segments[1:])
+	ew.flush(w, r)
+}
+
+// etagWriter buffers a response to send it with an ETag, and 304 Not Modified when If-None-Match matches
+type etagWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (ew *etagWriter) Header() http.Header {
+	return ew.header
+}
+
+func (ew *etagWriter) Write(b []byte) (int, error) {
+	if ew.status == 0 {
+		ew.status = http.StatusOK
+	}
+	return ew.body.Write(b)
+}
+
+func (ew *etagWriter) WriteHeader(status int) {
+	if ew.status == 0 {
+		ew.status = status
+	}
+}
+
+func (ew *etagWriter) flush(w http.ResponseWriter, r *http.Request) {
+	for k, v := range ew.header {
+		w.Header()[k] = v
+	}
+	if ew.status == 0 {
+		ew.status = http.StatusOK
+	}
+	if ew.status == http.StatusOK {
+		sum := sha256.Sum256(ew.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.WriteHeader(ew.status)
+	w.Write(ew.body.Bytes())
+}
+
+func (s *Server) injectedFailure(r *http.Request) (int, bool) {
+	for i, f := range s.failures {
+		if f.method != "" && f.method != r.Method {
+			continue
+		}
+		if ok, _ := path.Match(f.pattern, r.URL.Path); !ok {
+			continue
+		}
+		if f.remaining > 0 {
+			f.remaining--
+			if f.remaining == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return f.status, true
+	}
+	return 0, false
+}
+
+// anonymous returns whether the request needs no JWT like the real API
+func (s *Server) anonymous(r *http.Request) bool {
+	return r.Method == http.MethodGet && (r.URL.Path == "/v4/auth/token" || r.URL.Path == "/v4/banners")
+}
+
+// authorized returns whether the request has JWT in the Authorization header. JWTs in the query are rejected to catch leaks into logs.
+func (s *Server) authorized(r *http.Request) bool {
+	return r.Header.Get("Authorization") == "Bearer "+JWT
+}
+
+// numericIDs are resources identified by numbers in paths
+var numericIDs = map[string]bool{
+	"banners":     true,
+	"pipelines":   true,
+	"jobs":        true,
+	"events":      true,
+	"builds":      true,
+	"secrets":     true,
+	"collections": true,
+	"tokens":      true,
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request, segments []string) {
+	resource, rest := segments[0], segments[1:]
+	var id int
+	if len(rest) > 0 && numericIDs[resource] {
+		n, err := strconv.Atoi(rest[0])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid id "+rest[0])
+			return
+		}
+		id = n
+	}
+
+	switch resource {
+	case "auth":
+		s.serveAuth(w, r, rest)
+	case "banners":
+		s.serveBanners(w, r, id, rest)
+	case "pipelines":
+		s.servePipelines(w, r, id, rest)
+	case "jobs":
+		s.serveJobs(w, r, id, rest)
+	case "events":
+		s.serveEvents(w, r, id, rest)
+	case "builds":
+		s.serveBuilds(w, r, id, rest)
+	case "secrets":
+		s.serveSecrets(w, r, id, rest)
+	case "collections":
+		s.serveCollections(w, r, id, rest)
+	case "tokens":
+		s.serveTokens(w, r, 0, rest)
+	case "templates":
+		s.serveTemplates(w, r, rest)
+	case "validator":
+		s.serveValidator(w, r, rest)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) now() string {
+	return s.Now().UTC().Format(time.RFC3339)
+}
+
+// writeJSON writes v with status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the format of the real API
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"statusCode": status,
+		"error":      http.StatusText(status),
+		"message":    message,
+	})
+}
+
+// writeList writes a page of items of ids following page, count and sort query parameters.
+// items are in ascending order of IDs, or descending when defaultDescending is true and sort is not set.
+func writeList(w http.ResponseWriter, r *http.Request, ids []int, defaultDescending bool, item func(id int) interface{}) {
+	sort.Ints(ids)
+	descending := defaultDescending
+	switch r.URL.Query().Get("sort") {
+	case sdapi.SortAscending:
+		descending = false
+	case sdapi.SortDescending:
+		descending = true
+	}
+	if descending {
+		sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+	}
+
+	q := r.URL.Query()
+	if q.Get("page") != "" || q.Get("count") != "" {
+		page, _ := strconv.Atoi(q.Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		count, _ := strconv.Atoi(q.Get("count"))
+		if count < 1 {
+			count = sdapi.DefaultPageCount
+		}
+		start := (page - 1) * count
+		if start > len(ids) {
+			start = len(ids)
+		}
+		end := start + count
+		if end > len(ids) {
+			end = len(ids)
+		}
+		ids = ids[start:end]
+	}
+
+	items := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, item(id))
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// decode decodes the request body into v, writing 400 on an error
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload JSON format")
+		return false
+	}
+	return true
+}
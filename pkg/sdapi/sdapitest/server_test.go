@@ -0,0 +1,379 @@
+package sdapitest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+func newTestAPI(t *testing.T, fake *Server) *sdapi.SDAPI {
+	t.Helper()
+	ts := httptest.NewServer(fake)
+	t.Cleanup(ts.Close)
+	api, err := sdapi.New(fake.Context(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+func TestBanners(t *testing.T) {
+	fake := NewServer()
+	api := newTestAPI(t, fake)
+	ctx := context.Background()
+
+	created, err := api.CreateBanner(ctx, sdapi.BannerRequest{Message: "maintenance"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	active := true
+	if _, err := api.UpdateBanner(ctx, created.ID, sdapi.BannerRequest{IsActive: &active}); err != nil {
+		t.Fatal(err)
+	}
+	banners, err := api.GetBanners(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(banners) != 1 || banners[0].Message != "maintenance" || !banners[0].IsActive || banners[0].Scope != sdapi.BannerScopeGlobal {
+		t.Errorf("unexpected banners: %+v", banners)
+	}
+
+	if err := api.DeleteBanner(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.DeleteBanner(ctx, created.ID); !errors.Is(err, sdapi.ErrNotFound) {
+		t.Errorf("err should be ErrNotFound, but actual is %v", err)
+	}
+	if _, err := api.CreateBanner(ctx, sdapi.BannerRequest{}); err == nil {
+		t.Errorf("banner without a message should not be created")
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	fake := NewServer()
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+	ctx := context.Background()
+
+	cases := map[string]struct {
+		userToken string
+		jwt       string
+		expectErr bool
+	}{
+		"valid JWT":                       {APIToken, JWT, false},
+		"stale JWT is refreshed":          {APIToken, "stale_jwt", false},
+		"invalid API token and stale JWT": {"invalid_token", "stale_jwt", true},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			sdctx := fake.Context(ts.URL)
+			sdctx.UserToken, sdctx.SDJWT = v.userToken, v.jwt
+			api, err := sdapi.New(sdctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = api.CreateBanner(ctx, sdapi.BannerRequest{Message: k})
+			if (err != nil) != v.expectErr {
+				t.Errorf("expectErr='%v', actual='%v'", v.expectErr, err)
+			}
+		})
+	}
+
+	res, err := http.Get(ts.URL + "/v4/pipelines")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status code should be %d, but actual is %d", http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestEventsAndBuilds(t *testing.T) {
+	fake := NewServer()
+	p := fake.AddPipeline("org/repo")
+	main := fake.AddJob(p.ID, "main", "~commit")
+	fake.AddJob(p.ID, "publish", "main")
+	api := newTestAPI(t, fake)
+	ctx := context.Background()
+
+	event, err := api.PostEvent(ctx, p.ID, "~commit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builds, err := api.GetEventBuilds(ctx, event.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builds) != 1 || builds[0].JobID != main.ID || builds[0].Status != sdapi.StatusQueued {
+		t.Fatalf("only main should be queued: %+v", builds)
+	}
+	if _, err := api.PostEvent(ctx, p.ID, "~pr"); err == nil {
+		t.Errorf("event without jobs to start should fail")
+	}
+
+	build := builds[0]
+	if err := fake.SetBuildStatus(build.ID, sdapi.StatusRunning); err != nil {
+		t.Fatal(err)
+	}
+	steps, err := api.GetBuildSteps(ctx, build.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 3 || steps[1].Name != "main" || steps[1].StartTime == "" || steps[1].Code != nil {
+		t.Errorf("main step should be running: %+v", steps)
+	}
+	lines, err := api.GetStepLogs(ctx, build.ID, "main", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0].Message != "$ main" {
+		t.Errorf("unexpected logs: %+v", lines)
+	}
+
+	if err := api.StopBuild(ctx, build.ID); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := fake.Build(build.ID); b.Status != sdapi.StatusAborted || b.EndTime == "" {
+		t.Errorf("build should be aborted: %+v", b)
+	}
+
+	page, err := api.GetBuildPage(ctx, build.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Repo != "org/repo" || page.Job != "main" {
+		t.Errorf("unexpected build page: %+v", page)
+	}
+	events, err := api.GetPipelineEvents(ctx, p.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].ID != event.ID {
+		t.Errorf("the latest event should be returned: %+v", events)
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	fake := NewServer()
+	p := fake.AddPipeline("org/repo")
+	api := newTestAPI(t, fake)
+	ctx := context.Background()
+
+	for _, value := range []string{"first", "second"} {
+		if err := api.SetSecret(ctx, p.ID, "FOO", value, false); err != nil {
+			t.Fatal(err)
+		}
+		if actual, _ := fake.SecretValue(p.ID, "FOO"); actual != value {
+			t.Errorf("expect='%v', actual='%v'", value, actual)
+		}
+	}
+	secrets, err := api.GetPipelineSecrets(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 1 {
+		t.Errorf("secret should be updated instead of created: %+v", secrets)
+	}
+	if err := api.SetSecret(ctx, p.ID+100, "FOO", "bar", false); err == nil {
+		t.Errorf("secret of unknown pipeline should not be set")
+	}
+}
+
+func TestFail(t *testing.T) {
+	fake := NewServer()
+	p := fake.AddPipeline("org/repo")
+	api := newTestAPI(t, fake)
+	ctx := context.Background()
+
+	fake.Fail(http.MethodGet, "/v4/pipelines/*", http.StatusInternalServerError, 1)
+	if _, err := api.GetPipeline(ctx, p.ID); err == nil {
+		t.Errorf("injected failure should be returned")
+	}
+	if _, err := api.GetPipeline(ctx, p.ID); err != nil {
+		t.Errorf("failure should be injected only once: %v", err)
+	}
+
+	fake.Fail("", "/v4/banners", http.StatusServiceUnavailable, 0)
+	if _, err := api.GetBanners(ctx); err == nil {
+		t.Errorf("injected failure should be returned")
+	}
+	fake.ClearFailures()
+	if _, err := api.GetBanners(ctx); err != nil {
+		t.Errorf("failures should be cleared: %v", err)
+	}
+}
+
+func TestListPipelines(t *testing.T) {
+	fake := NewServer()
+	var expected []string
+	for i := 0; i < 5; i++ {
+		name := "org/repo" + strconv.Itoa(i)
+		fake.AddPipeline(name)
+		expected = append(expected, name)
+	}
+	fake.AddPipeline("other/repo")
+	api := newTestAPI(t, fake)
+
+	pipelines, err := api.ListPipelines(context.Background(), "org/", sdapi.ListOptions{Count: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, p := range pipelines {
+		actual = append(actual, p.Name)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if id, err := api.ResolvePipelineID(context.Background(), "other/repo"); err != nil || id != 6 {
+		t.Errorf("pipeline should be resolved: %v, %v", id, err)
+	}
+}
+
+func TestListTemplates(t *testing.T) {
+	fake := NewServer()
+	fake.AddTemplate(Template{Namespace: "sd", Name: "node", Version: "1.0.0"})
+	fake.AddTemplate(Template{Name: "python", Version: "2.0.0"})
+	api := newTestAPI(t, fake)
+
+	templates, err := api.ListTemplates(context.Background(), sdapi.ListOptions{Count: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, tmpl := range templates {
+		actual = append(actual, tmpl.FullName()+"@"+tmpl.Version)
+	}
+	if diff := cmp.Diff([]string{"sd/node@1.0.0", "python@2.0.0"}, actual); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectionsAndTokens(t *testing.T) {
+	fake := NewServer()
+	p := fake.AddPipeline("org/repo")
+	api := newTestAPI(t, fake)
+	ctx := context.Background()
+
+	c, err := api.CreateCollection(ctx, sdapi.CollectionRequest{Name: "team", PipelineIDs: []int{p.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := api.FindCollection(ctx, "team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != c.ID || len(found.Pipelines) != 1 || found.Pipelines[0].Name != "org/repo" {
+		t.Errorf("collection should have the pipeline: %+v", found)
+	}
+
+	token, err := api.CreateToken(ctx, p.ID, sdapi.TokenRequest{Name: "bot"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdctx := fake.Context("")
+	sdctx.UserToken, sdctx.SDJWT = "", ""
+	sdctx.PipelineToken = token.Value
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+	sdctx.APIURL = ts.URL
+	bot, err := sdapi.New(sdctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jwt, err := bot.GetJWT(ctx); err != nil || jwt != JWT {
+		t.Errorf("pipeline token should be exchanged for JWT: %v, %v", jwt, err)
+	}
+	if err := api.RevokeToken(ctx, p.ID, token.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.GetJWT(ctx); err == nil {
+		t.Errorf("revoked token should not be exchanged")
+	}
+}
+
+func TestValidator(t *testing.T) {
+	api := newTestAPI(t, NewServer())
+	ctx := context.Background()
+
+	cases := map[string]struct {
+		yaml      string
+		expectErr bool
+	}{
+		"valid":            {`"shared:\n  image: node:16\njobs:\n  main:\n    requires: [~commit]\n    steps:\n      - test: npm test\n"`, false},
+		"without image":    {`"jobs:\n  main:\n    steps:\n      - test: npm test\n"`, true},
+		"without jobs":     {`"shared:\n  image: node:16\n"`, true},
+		"invalid template": {`"jobs: {}\n"`, true},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			if k == "invalid template" {
+				tvr, err := api.ValidateTemplate(ctx, v.yaml)
+				if err == nil || len(tvr.Errors) != 6 {
+					t.Errorf("required and unknown keys should be errors: %+v", tvr)
+				}
+				return
+			}
+			vr, err := api.ValidatePipeline(ctx, v.yaml)
+			if (err != nil) != v.expectErr {
+				t.Errorf("expectErr='%v', actual='%v'", v.expectErr, err)
+			}
+			if !v.expectErr {
+				expected := []sdapi.WorkflowEdge{{Src: "~commit", Dest: "main"}}
+				if diff := cmp.Diff(expected, vr.WorkflowGraph.Edges); diff != "" {
+					t.Errorf("mismatch (-want +got):\n%s", diff)
+				}
+				if vr.Jobs["main"][0].Image != "node:16" {
+					t.Errorf("shared image should be merged: %+v", vr.Jobs["main"])
+				}
+			}
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	fake := NewServer()
+	p := fake.AddPipeline("org/repo")
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	get := func(etag string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v4/pipelines/"+strconv.Itoa(p.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+JWT)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res
+	}
+
+	res := get("")
+	etag := res.Header.Get("ETag")
+	if res.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("response should have an ETag: %d %v", res.StatusCode, res.Header)
+	}
+	if res := get(etag); res.StatusCode != http.StatusNotModified {
+		t.Errorf("status code should be 304, but actual is %d", res.StatusCode)
+	}
+	if res := get(`"other"`); res.StatusCode != http.StatusOK {
+		t.Errorf("status code should be 200, but actual is %d", res.StatusCode)
+	}
+}
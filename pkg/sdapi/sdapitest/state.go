@@ -0,0 +1,266 @@
+package sdapitest
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+// AddPipeline adds a pipeline of a repository such as "org/repo"
+func (s *Server) AddPipeline(name string) sdapi.Pipeline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := &sdapi.Pipeline{ID: s.newID(), Name: name}
+	p.SCMRepo.Name = name
+	p.SCMRepo.Branch = "main"
+	p.SCMRepo.URL = "https://github.com/" + name + "/tree/main"
+	s.pipelines[p.ID] = p
+	return *p
+}
+
+// AddJob adds a job to a pipeline. requires are triggers such as "~commit" or names of upstream jobs.
+func (s *Server) AddJob(pipelineID int, name string, requires ...string) sdapi.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := &job{
+		Job:      sdapi.Job{ID: s.newID(), PipelineID: pipelineID, Name: name, State: "ENABLED"},
+		requires: requires,
+	}
+	s.jobs[j.ID] = j
+	return j.Job
+}
+
+// AddBanner adds a global banner
+func (s *Server) AddBanner(message string, isActive bool) sdapi.BannerResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := &sdapi.BannerResponse{
+		ID:         s.newID(),
+		Message:    message,
+		IsActive:   isActive,
+		CreateTime: s.now(),
+		CreatedBy:  "fake-user",
+		Type:       "info",
+		Scope:      sdapi.BannerScopeGlobal,
+	}
+	s.banners[b.ID] = b
+	return *b
+}
+
+// AddCollection adds a collection of pipelines
+func (s *Server) AddCollection(name string, pipelineIDs ...int) sdapi.Collection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pipelineIDs == nil {
+		pipelineIDs = []int{}
+	}
+	c := &sdapi.Collection{ID: s.newID(), Name: name, Type: "normal", PipelineIDs: pipelineIDs}
+	s.collections[c.ID] = c
+	return *c
+}
+
+// AddTemplate publishes a version of a template
+func (s *Server) AddTemplate(t Template) Template {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.addTemplate(t)
+}
+
+func (s *Server) addTemplate(t Template) *Template {
+	t.ID = s.newID()
+	t.CreateTime = s.now()
+	name := t.Name
+	if t.Namespace != "" {
+		name = t.Namespace + "/" + t.Name
+	}
+	s.templates[name] = append(s.templates[name], &t)
+	return &t
+}
+
+// StartEvent starts an event like POST /v4/events, creating QUEUED builds of jobs started from startFrom
+func (s *Server) StartEvent(pipelineID int, startFrom string) (sdapi.Event, []sdapi.Build, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pipelines[pipelineID]; !ok {
+		return sdapi.Event{}, nil, fmt.Errorf("pipeline %d is %w", pipelineID, sdapi.ErrNotFound)
+	}
+	e, err := s.startEvent(pipelineID, startFrom)
+	if err != nil {
+		return sdapi.Event{}, nil, err
+	}
+	var builds []sdapi.Build
+	for _, b := range s.eventBuilds(e.ID) {
+		builds = append(builds, *b)
+	}
+	return *e, builds, nil
+}
+
+// startEvent creates an event with builds of jobs requiring startFrom if it is a trigger, or the job named startFrom
+func (s *Server) startEvent(pipelineID int, startFrom string) (*sdapi.Event, error) {
+	var started []*job
+	for _, j := range s.jobs {
+		if j.PipelineID != pipelineID || j.Archived {
+			continue
+		}
+		if j.Name == startFrom {
+			started = append(started, j)
+			continue
+		}
+		for _, r := range j.requires {
+			if strings.HasPrefix(startFrom, "~") && r == startFrom {
+				started = append(started, j)
+				break
+			}
+		}
+	}
+	if len(started) == 0 {
+		return nil, fmt.Errorf("No jobs to start from %s", startFrom)
+	}
+	sort.Slice(started, func(i, k int) bool { return started[i].ID < started[k].ID })
+
+	id := s.newID()
+	e := &sdapi.Event{
+		ID:           id,
+		PipelineID:   pipelineID,
+		SHA:          fmt.Sprintf("%x", sha1.Sum([]byte(strconv.Itoa(id)))),
+		CauseMessage: "Started by fake-user",
+		CreateTime:   s.now(),
+		StartFrom:    startFrom,
+	}
+	s.events[e.ID] = e
+	for _, j := range started {
+		b := &sdapi.Build{ID: s.newID(), JobID: j.ID, EventID: e.ID, Status: sdapi.StatusQueued}
+		s.builds[b.ID] = b
+	}
+	return e, nil
+}
+
+func (s *Server) eventBuilds(eventID int) []*sdapi.Build {
+	var builds []*sdapi.Build
+	for _, b := range s.builds {
+		if b.EventID == eventID {
+			builds = append(builds, b)
+		}
+	}
+	sort.Slice(builds, func(i, k int) bool { return builds[i].ID < builds[k].ID })
+	return builds
+}
+
+// SetBuildStatus changes the status of a build, setting its start and end time
+func (s *Server) SetBuildStatus(buildID int, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.builds[buildID]
+	if !ok {
+		return fmt.Errorf("build %d is %w", buildID, sdapi.ErrNotFound)
+	}
+	s.setBuildStatus(b, status)
+	return nil
+}
+
+func (s *Server) setBuildStatus(b *sdapi.Build, status string) {
+	b.Status = status
+	switch status {
+	case sdapi.StatusRunning:
+		if b.StartTime == "" {
+			b.StartTime = s.now()
+		}
+	case sdapi.StatusSuccess, sdapi.StatusFailure, sdapi.StatusAborted, sdapi.StatusUnstable:
+		if b.StartTime == "" {
+			b.StartTime = s.now()
+		}
+		b.EndTime = s.now()
+	}
+}
+
+// Build returns a build
+func (s *Server) Build(buildID int) (sdapi.Build, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.builds[buildID]
+	if !ok {
+		return sdapi.Build{}, false
+	}
+	return *b, true
+}
+
+// Banners returns banners in the order of IDs
+func (s *Server) Banners() []sdapi.BannerResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var banners []sdapi.BannerResponse
+	for _, b := range s.banners {
+		banners = append(banners, *b)
+	}
+	sort.Slice(banners, func(i, k int) bool { return banners[i].ID < banners[k].ID })
+	return banners
+}
+
+// SecretValue returns the value of a secret of a pipeline, which the API never returns
+func (s *Server) SecretValue(pipelineID int, name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sec := range s.secrets {
+		if sec.PipelineID == pipelineID && sec.Name == name {
+			return sec.value, true
+		}
+	}
+	return "", false
+}
+
+// steps returns steps of a build derived from its status. the step named after the job fails in failed builds.
+func (s *Server) steps(b *sdapi.Build) []sdapi.Step {
+	name := "main"
+	if j, ok := s.jobs[b.JobID]; ok {
+		name = j.Name
+	}
+	steps := []sdapi.Step{{Name: "sd-setup-init"}, {Name: name}, {Name: "sd-teardown-init"}}
+	if b.StartTime == "" {
+		return steps
+	}
+	code := func(c int) *int { return &c }
+
+	steps[0].StartTime, steps[0].EndTime, steps[0].Code = b.StartTime, b.StartTime, code(0)
+	steps[1].StartTime = b.StartTime
+	if b.EndTime == "" {
+		return steps
+	}
+	steps[1].EndTime = b.EndTime
+	switch b.Status {
+	case sdapi.StatusFailure:
+		steps[1].Code = code(1)
+	case sdapi.StatusAborted:
+		steps[1].Code = code(130)
+	default:
+		steps[1].Code = code(0)
+	}
+	steps[2].StartTime, steps[2].EndTime, steps[2].Code = b.EndTime, b.EndTime, code(0)
+	return steps
+}
+
+// logs returns log lines of a step which started
+func logs(step sdapi.Step, descending bool) []sdapi.LogLine {
+	lines := []sdapi.LogLine{}
+	if step.StartTime == "" {
+		return lines
+	}
+	t, _ := time.Parse(time.RFC3339, step.StartTime)
+	messages := []string{"$ " + step.Name}
+	if step.Code != nil {
+		messages = append(messages, fmt.Sprintf("%s exited with %d", step.Name, *step.Code))
+	}
+	for i, m := range messages {
+		lines = append(lines, sdapi.LogLine{Time: t.UnixNano() / int64(time.Millisecond), Message: m, Line: i})
+	}
+	if descending {
+		for i, k := 0, len(lines)-1; i < k; i, k = i+1, k-1 {
+			lines[i], lines[k] = lines[k], lines[i]
+		}
+	}
+	return lines
+}
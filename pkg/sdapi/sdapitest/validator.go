@@ -0,0 +1,217 @@
+package sdapitest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"gopkg.in/yaml.v2"
+)
+
+// pipelineConfig is a subset of screwdriver.yaml understood by the fake validator
+type pipelineConfig struct {
+	Annotations map[string]interface{} `yaml:"annotations"`
+	Shared      jobConfig              `yaml:"shared"`
+	Jobs        map[string]jobConfig   `yaml:"jobs"`
+}
+
+type jobConfig struct {
+	Image       string                 `yaml:"image"`
+	Template    string                 `yaml:"template"`
+	Steps       []yaml.MapSlice        `yaml:"steps"`
+	Requires    interface{}            `yaml:"requires"`
+	Environment map[string]string      `yaml:"environment"`
+	Secrets     []string               `yaml:"secrets"`
+	Settings    map[string]interface{} `yaml:"settings"`
+	Annotations map[string]interface{} `yaml:"annotations"`
+	Description string                 `yaml:"description"`
+}
+
+func (s *Server) serveValidator(w http.ResponseWriter, r *http.Request, rest []string) {
+	if r.Method != http.MethodPost || len(rest) > 1 || (len(rest) == 1 && rest[0] != "template") {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	var req struct {
+		YAML string `json:"yaml"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	if len(rest) == 1 {
+		writeJSON(w, http.StatusOK, validateTemplate(req.YAML))
+		return
+	}
+	writeJSON(w, http.StatusOK, validatePipeline(req.YAML))
+}
+
+// validatePipeline expands jobs with shared settings and builds the workflow graph
+func validatePipeline(yamlStr string) sdapi.ValidatorResponse {
+	vr := sdapi.ValidatorResponse{
+		Annotations: map[string]interface{}{},
+		Jobs:        map[string][]sdapi.JobConfig{},
+		WorkflowGraph: sdapi.WorkflowGraph{
+			Nodes: []sdapi.WorkflowNode{{Name: "~pr"}, {Name: "~commit"}},
+			Edges: []sdapi.WorkflowEdge{},
+		},
+	}
+	var config pipelineConfig
+	if err := yaml.Unmarshal([]byte(yamlStr), &config); err != nil {
+		vr.Errors = []string{"YAMLException: " + err.Error()}
+		return vr
+	}
+	if len(config.Jobs) == 0 {
+		vr.Errors = []string{`ValidationError: child "jobs" fails because ["jobs" is required]`}
+		return vr
+	}
+	if config.Annotations != nil {
+		vr.Annotations = jsonMap(config.Annotations)
+	}
+
+	var names []string
+	for name := range config.Jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		job := config.Jobs[name]
+		jc := sdapi.JobConfig{
+			Image:       job.Image,
+			Environment: map[string]string{},
+			Secrets:     append(append([]string{}, config.Shared.Secrets...), job.Secrets...),
+			Settings:    jsonMap(job.Settings),
+			Annotations: jsonMap(job.Annotations),
+			Requires:    requires(job.Requires),
+			Description: job.Description,
+		}
+		if jc.Image == "" {
+			jc.Image = config.Shared.Image
+		}
+		for k, v := range config.Shared.Environment {
+			jc.Environment[k] = v
+		}
+		for k, v := range job.Environment {
+			jc.Environment[k] = v
+		}
+		steps := job.Steps
+		if steps == nil {
+			steps = config.Shared.Steps
+		}
+		for _, step := range steps {
+			for _, item := range step {
+				jc.Commands = append(jc.Commands, sdapi.JobCommand{Name: fmt.Sprint(item.Key), Command: fmt.Sprint(item.Value)})
+			}
+		}
+
+		switch {
+		case job.Template != "":
+		case jc.Image == "":
+			vr.Errors = append(vr.Errors, fmt.Sprintf(`ValidationError: child "jobs" fails because [child "%s" fails because [child "image" fails because ["image" is required]]]`, name))
+		case len(jc.Commands) == 0:
+			vr.Errors = append(vr.Errors, fmt.Sprintf(`ValidationError: child "jobs" fails because [child "%s" fails because [child "steps" fails because ["steps" is required]]]`, name))
+		}
+
+		vr.Jobs[name] = []sdapi.JobConfig{jc}
+		vr.WorkflowGraph.Nodes = append(vr.WorkflowGraph.Nodes, sdapi.WorkflowNode{Name: name})
+		for _, src := range jc.Requires {
+			vr.WorkflowGraph.Edges = append(vr.WorkflowGraph.Edges, sdapi.WorkflowEdge{Src: src, Dest: name})
+		}
+	}
+	return vr
+}
+
+// jsonMap converts maps decoded from YAML, whose nested maps have keys of interface{}, to be encoded in JSON
+func jsonMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	converted := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		converted[k] = jsonValue(v)
+	}
+	return converted
+}
+
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case map[string]interface{}:
+		return jsonMap(v)
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = jsonValue(e)
+		}
+		return l
+	}
+	return v
+}
+
+// requires normalizes requires of a job, a string or a list of strings
+func requires(v interface{}) []string {
+	switch r := v.(type) {
+	case string:
+		return []string{r}
+	case []interface{}:
+		var names []string
+		for _, n := range r {
+			names = append(names, fmt.Sprint(n))
+		}
+		return names
+	}
+	return nil
+}
+
+// templateKeys are keys of a sd-template.yaml
+var templateKeys = []string{"namespace", "name", "version", "description", "maintainer", "images", "config"}
+
+// requiredTemplateKeys are keys without which a template is invalid
+var requiredTemplateKeys = []string{"name", "version", "description", "maintainer", "config"}
+
+// validateTemplate checks required and unknown keys of a template
+func validateTemplate(yamlStr string) sdapi.TemplateValidatorResponse {
+	tvr := sdapi.TemplateValidatorResponse{Errors: []sdapi.TemplateValidateError{}}
+	var template map[string]interface{}
+	if err := yaml.Unmarshal([]byte(yamlStr), &template); err != nil {
+		tvr.Errors = append(tvr.Errors, sdapi.TemplateValidateError{Message: "YAMLException: " + err.Error(), Type: "yaml"})
+		return tvr
+	}
+	tvr.Template = jsonMap(template)
+
+	for _, key := range requiredTemplateKeys {
+		if _, ok := template[key]; !ok {
+			tvr.Errors = append(tvr.Errors, sdapi.TemplateValidateError{
+				Message: fmt.Sprintf("%q is required", key),
+				Path:    []string{key},
+				Type:    "any.required",
+				Context: map[string]string{"key": key, "label": key},
+			})
+		}
+	}
+	var unknown []string
+	for key := range template {
+		known := false
+		for _, k := range templateKeys {
+			known = known || k == key
+		}
+		if !known {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		tvr.Errors = append(tvr.Errors, sdapi.TemplateValidateError{
+			Message: fmt.Sprintf("%q is not allowed", key),
+			Path:    []string{key},
+			Type:    "object.allowUnknown",
+			Context: map[string]string{"child": key, "key": key, "label": key},
+		})
+	}
+	return tvr
+}
@@ -0,0 +1,31 @@
+package sdapi
+
+import (
+	"context"
+)
+
+// Template represents Template API response schema of a published version of a template
+type Template struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// FullName returns the name with the namespace such as screwdriver/node, or the name without a namespace
+func (t Template) FullName() string {
+	if t.Namespace == "" {
+		return t.Name
+	}
+	return t.Namespace + "/" + t.Name
+}
+
+// ListTemplates returns published templates following pages up to the limit
+func (sd *SDAPI) ListTemplates(ctx context.Context, opts ListOptions) ([]Template, error) {
+	var templates []Template
+	if err := sd.ListAll(ctx, "/v4/templates", nil, opts, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
@@ -0,0 +1,98 @@
+package sdapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Token represents Token API response schema. Value is returned only when the token is created or refreshed.
+type Token struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	LastUsed    string `json:"lastUsed"`
+	PipelineID  int    `json:"pipelineId,omitempty"`
+	Value       string `json:"value,omitempty"`
+}
+
+// TokenRequest represents Token API request schema
+type TokenRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// tokensPath returns the path of pipeline tokens, or user tokens when pipelineID is 0
+func tokensPath(pipelineID int) string {
+	if pipelineID == 0 {
+		return "/v4/tokens"
+	}
+	return fmt.Sprintf("/v4/pipelines/%d/tokens", pipelineID)
+}
+
+// GetTokens returns all tokens of a pipeline, or of the user when pipelineID is 0
+func (sd *SDAPI) GetTokens(ctx context.Context, pipelineID int) ([]Token, error) {
+	return sd.ListTokens(ctx, pipelineID, ListOptions{})
+}
+
+// ListTokens returns tokens of a pipeline, or of the user when pipelineID is 0, following pages up to the limit
+func (sd *SDAPI) ListTokens(ctx context.Context, pipelineID int, opts ListOptions) ([]Token, error) {
+	var tokens []Token
+	if err := sd.ListAll(ctx, tokensPath(pipelineID), nil, opts, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// CreateToken creates a token of a pipeline, or of the user when pipelineID is 0
+func (sd *SDAPI) CreateToken(ctx context.Context, pipelineID int, token TokenRequest) (*Token, error) {
+	return sd.requestToken(ctx, http.MethodPost, tokensPath(pipelineID), token, http.StatusCreated)
+}
+
+// RefreshToken regenerates the value of a token of a pipeline, or of the user when pipelineID is 0
+func (sd *SDAPI) RefreshToken(ctx context.Context, pipelineID, tokenID int) (*Token, error) {
+	return sd.requestToken(ctx, http.MethodPut, fmt.Sprintf("%s/%d/refresh", tokensPath(pipelineID), tokenID), nil, http.StatusOK)
+}
+
+// RevokeToken deletes a token of a pipeline, or of the user when pipelineID is 0
+func (sd *SDAPI) RevokeToken(ctx context.Context, pipelineID, tokenID int) error {
+	_, err := sd.requestToken(ctx, http.MethodDelete, fmt.Sprintf("%s/%d", tokensPath(pipelineID), tokenID), nil, http.StatusNoContent)
+	return err
+}
+
+func (sd *SDAPI) requestToken(ctx context.Context, method, path string, body interface{}, expectedStatus int) (*Token, error) {
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewBuffer(jsonBody)
+	}
+
+	res, err := sd.request(ctx, method, path, nil, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case expectedStatus:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s is %w", path, ErrNotFound)
+	default:
+		return nil, fmt.Errorf("%s %s status code is not %d: %d", method, path, expectedStatus, res.StatusCode)
+	}
+
+	if expectedStatus == http.StatusNoContent {
+		return nil, nil
+	}
+	token := new(Token)
+	if err := json.NewDecoder(res.Body).Decode(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
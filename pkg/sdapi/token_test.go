@@ -0,0 +1,208 @@
+package sdapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetJWTWithPipelineToken(t *testing.T) {
+	muxAPI := http.NewServeMux()
+	testAPIServer := httptest.NewServer(muxAPI)
+	defer testAPIServer.Close()
+
+	muxAPI.HandleFunc("/v4/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_token") != "pipeline_token" {
+			t.Errorf("pipeline token should be exchanged: %v", r.URL.Query())
+		}
+		http.ServeFile(w, r, mockSDJWTResponse)
+	})
+
+	ctx := mockSDContext
+	ctx.APIURL = testAPIServer.URL
+	ctx.PipelineToken = "pipeline_token"
+	sdapi, err := New(ctx)
+	if err != nil {
+		t.Fatal("should not cause error")
+	}
+	if _, err := sdapi.GetJWT(context.Background()); err != nil {
+		t.Errorf("error should be nil but: '%v'", err)
+	}
+}
+
+func TestGetTokens(t *testing.T) {
+	cases := map[string]struct {
+		pipelineID int
+		path       string
+	}{
+		"user tokens":     {0, "/v4/tokens"},
+		"pipeline tokens": {1, "/v4/pipelines/1/tokens"},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			muxAPI := http.NewServeMux()
+			testAPIServer := httptest.NewServer(muxAPI)
+			defer testAPIServer.Close()
+
+			muxAPI.HandleFunc(v.path, func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"id":2,"name":"bot","description":"for bots","lastUsed":""}]`)
+			})
+
+			mockSDContext.APIURL = testAPIServer.URL
+			sdapi, err := New(mockSDContext)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+			tokens, err := sdapi.GetTokens(context.Background(), v.pipelineID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff([]Token{{ID: 2, Name: "bot", Description: "for bots"}}, tokens); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRequestToken(t *testing.T) {
+	cases := map[string]struct {
+		method       string
+		pipelineID   int
+		path         string
+		statusCode   int
+		expectedBody string
+		expectedErr  bool
+	}{
+		"Create a user token successfully":      {http.MethodPost, 0, "/v4/tokens", http.StatusCreated, `{"name":"bot"}`, false},
+		"Create a pipeline token successfully":  {http.MethodPost, 1, "/v4/pipelines/1/tokens", http.StatusCreated, `{"name":"bot"}`, false},
+		"Refresh a pipeline token successfully": {http.MethodPut, 1, "/v4/pipelines/1/tokens/2/refresh", http.StatusOK, "", false},
+		"Revoke a user token successfully":      {http.MethodDelete, 0, "/v4/tokens/2", http.StatusNoContent, "", false},
+		"Failed to refresh a token not found":   {http.MethodPut, 0, "/v4/tokens/2/refresh", http.StatusNotFound, "", true},
+		"Failed to create a forbidden token":    {http.MethodPost, 1, "/v4/pipelines/1/tokens", http.StatusForbidden, `{"name":"bot"}`, true},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			muxAPI := http.NewServeMux()
+			testAPIServer := httptest.NewServer(muxAPI)
+			defer testAPIServer.Close()
+
+			muxAPI.HandleFunc(v.path, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != v.method {
+					t.Errorf("method should be %s, but actual is %s", v.method, r.Method)
+				}
+				body, _ := ioutil.ReadAll(r.Body)
+				if string(body) != v.expectedBody {
+					t.Errorf("body should be %s, but actual is %s", v.expectedBody, body)
+				}
+				w.WriteHeader(v.statusCode)
+				if v.statusCode != http.StatusNoContent {
+					b, _ := json.Marshal(Token{ID: 2, Name: "bot", Value: "secret"})
+					w.Write(b)
+				}
+			})
+			muxAPI.HandleFunc("/v4/auth/token", func(w http.ResponseWriter, r *http.Request) {
+				http.ServeFile(w, r, mockSDJWTResponse)
+			})
+
+			mockSDContext.APIURL = testAPIServer.URL
+			sdapi, err := New(mockSDContext)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+
+			var token *Token
+			switch v.method {
+			case http.MethodPost:
+				token, err = sdapi.CreateToken(context.Background(), v.pipelineID, TokenRequest{Name: "bot"})
+			case http.MethodPut:
+				token, err = sdapi.RefreshToken(context.Background(), v.pipelineID, 2)
+			case http.MethodDelete:
+				err = sdapi.RevokeToken(context.Background(), v.pipelineID, 2)
+			}
+			if (err != nil) != v.expectedErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil || v.method == http.MethodDelete {
+				return
+			}
+			if token.Value != "secret" {
+				t.Errorf("value should be returned: %#v", token)
+			}
+		})
+	}
+}
+
+func TestRequestTokenResendsOnlyOnUnauthorized(t *testing.T) {
+	cases := map[string]struct {
+		method           string
+		path             string
+		statusCode       int
+		expectedRequests int
+		expectedErr      bool
+	}{
+		"Create a token with a refreshed JWT":   {http.MethodPost, "/v4/tokens", http.StatusUnauthorized, 2, false},
+		"Refresh a token with a refreshed JWT":  {http.MethodPut, "/v4/tokens/2/refresh", http.StatusUnauthorized, 2, false},
+		"Failed to create a conflicting token":  {http.MethodPost, "/v4/tokens", http.StatusConflict, 1, true},
+		"Failed to create a token by bad input": {http.MethodPost, "/v4/tokens", http.StatusBadRequest, 1, true},
+		"Failed to refresh a token by an error": {http.MethodPut, "/v4/tokens/2/refresh", http.StatusInternalServerError, 1, true},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			muxAPI := http.NewServeMux()
+			testAPIServer := httptest.NewServer(muxAPI)
+			defer testAPIServer.Close()
+
+			requests := 0
+			muxAPI.HandleFunc(v.path, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				// only the status code of the stored JWT is given, and the refreshed JWT succeeds
+				if r.Header.Get("Authorization") == "Bearer "+mockSDJWT {
+					w.WriteHeader(v.statusCode)
+					return
+				}
+				if r.Method == http.MethodPost {
+					w.WriteHeader(http.StatusCreated)
+				}
+				b, _ := json.Marshal(Token{ID: 2, Name: "bot", Value: "secret"})
+				w.Write(b)
+			})
+			muxAPI.HandleFunc("/v4/auth/token", func(w http.ResponseWriter, r *http.Request) {
+				http.ServeFile(w, r, mockSDJWTResponse)
+			})
+
+			ctx := mockSDContext
+			ctx.APIURL = testAPIServer.URL
+			sdapi, err := New(ctx)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+
+			if v.method == http.MethodPost {
+				_, err = sdapi.CreateToken(context.Background(), 0, TokenRequest{Name: "bot"})
+			} else {
+				_, err = sdapi.RefreshToken(context.Background(), 0, 2)
+			}
+			if (err != nil) != v.expectedErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if requests != v.expectedRequests {
+				t.Errorf("request should be sent %d times, but actual is %d", v.expectedRequests, requests)
+			}
+		})
+	}
+}
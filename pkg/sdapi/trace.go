@@ -0,0 +1,158 @@
+package sdapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTraceBody is the max length of bodies in traces
+const maxTraceBody = 4096
+
+// Trace is an attempt of a request with credentials redacted. Latency is encoded in milliseconds as latencyMs.
+type Trace struct {
+	Time         time.Time     `json:"time"`
+	Method       string        `json:"method"`
+	URL          string        `json:"url"`
+	Status       int           `json:"status,omitempty"`
+	Latency      time.Duration `json:"-"`
+	Error        string        `json:"error,omitempty"`
+	RequestBody  string        `json:"requestBody,omitempty"`
+	ResponseBody string        `json:"responseBody,omitempty"`
+}
+
+// TraceHandler handles traces of requests. it is called concurrently.
+type TraceHandler func(Trace)
+
+// traceTransport passes a Trace of each request through base to handle
+type traceTransport struct {
+	base   http.RoundTripper
+	bodies bool
+	handle TraceHandler
+}
+
+// NewTraceTransport returns a RoundTripper tracing requests through base with handle.
+// bodies are traced with bodies true. JWTs, tokens and values of secrets are redacted in the same way as NewRecorder.
+func NewTraceTransport(base http.RoundTripper, bodies bool, handle TraceHandler) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &traceTransport{base: base, bodies: bodies, handle: handle}
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tr := Trace{
+		Time:   time.Now(),
+		Method: req.Method,
+		URL:    redactURL(req.URL),
+	}
+	if t.bodies {
+		body, err := readBody(&req.Body)
+		if err != nil {
+			return nil, err
+		}
+		tr.RequestBody = traceBody(req.URL.Path, body)
+	}
+
+	res, err := t.base.RoundTrip(req)
+	tr.Latency = time.Since(tr.Time)
+	if err != nil {
+		tr.Error = strings.ReplaceAll(err.Error(), req.URL.String(), tr.URL)
+		t.handle(tr)
+		return nil, err
+	}
+	tr.Status = res.StatusCode
+	if t.bodies {
+		body, err := readBody(&res.Body)
+		if err != nil {
+			return nil, err
+		}
+		tr.ResponseBody = traceBody(req.URL.Path, body)
+	}
+	t.handle(tr)
+	return res, nil
+}
+
+// redactURL returns u with credentials in the query redacted
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = ""
+	redacted.Path, redacted.RawPath = "", ""
+	return redacted.String() + scrubURL(u)
+}
+
+func traceBody(path, body string) string {
+	body = strings.TrimSpace(scrubBody(path, body))
+	if len(body) > maxTraceBody {
+		return body[:maxTraceBody] + "...(truncated)"
+	}
+	return body
+}
+
+// LogfmtTraceHandler writes traces into w as lines of key=value pairs
+func LogfmtTraceHandler(w io.Writer) TraceHandler {
+	var mu sync.Mutex
+	return func(tr Trace) {
+		pairs := []string{
+			"time=" + tr.Time.Format(time.RFC3339Nano),
+			"method=" + tr.Method,
+			"url=" + logfmtValue(tr.URL),
+		}
+		if tr.Status != 0 {
+			pairs = append(pairs, "status="+strconv.Itoa(tr.Status))
+		}
+		pairs = append(pairs, "latency="+tr.Latency.Round(time.Microsecond).String())
+		if tr.Error != "" {
+			pairs = append(pairs, "error="+logfmtValue(tr.Error))
+		}
+		if tr.RequestBody != "" {
+			pairs = append(pairs, "request_body="+logfmtValue(tr.RequestBody))
+		}
+		if tr.ResponseBody != "" {
+			pairs = append(pairs, "response_body="+logfmtValue(tr.ResponseBody))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintln(w, strings.Join(pairs, " "))
+	}
+}
+
+func logfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\t\r\n") {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// JSONTraceHandler writes traces into w as JSON lines
+func JSONTraceHandler(w io.Writer) TraceHandler {
+	var mu sync.Mutex
+	return func(tr Trace) {
+		b, err := json.Marshal(struct {
+			Trace
+			LatencyMS float64 `json:"latencyMs"`
+		}{tr, float64(tr.Latency) / float64(time.Millisecond)})
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write(append(b, '\n'))
+	}
+}
+
+// MultiTraceHandler passes traces to each of handlers
+func MultiTraceHandler(handlers ...TraceHandler) TraceHandler {
+	return func(tr Trace) {
+		for _, h := range handlers {
+			h(tr)
+		}
+	}
+}
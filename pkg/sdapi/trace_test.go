@@ -0,0 +1,84 @@
+package sdapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceTransport(t *testing.T) {
+	muxAPI := http.NewServeMux()
+	testAPIServer := httptest.NewServer(muxAPI)
+	defer testAPIServer.Close()
+
+	muxAPI.HandleFunc("/v4/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token":"secret_jwt"}`)
+	})
+	muxAPI.HandleFunc("/v4/secrets", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":1,"pipelineId":1234,"name":"FOO","allowInPR":false}`)
+	})
+
+	cases := map[string]struct {
+		bodies             bool
+		expectedRequestLog string
+	}{
+		"without bodies": {false, ""},
+		"with bodies":    {true, `{"allowInPR":false,"name":"FOO","pipelineId":1234,"value":"REDACTED"}`},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			var logs, jsonLines bytes.Buffer
+			ctx := context.Background()
+			sdctx := mockSDContext
+			sdctx.APIURL = testAPIServer.URL
+			sdctx.UserToken = "secret_api_token"
+			sdapi, err := New(sdctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sdapi.WrapTransport(func(base http.RoundTripper) http.RoundTripper {
+				return NewTraceTransport(base, v.bodies, MultiTraceHandler(LogfmtTraceHandler(&logs), JSONTraceHandler(&jsonLines)))
+			})
+
+			if _, err := sdapi.GetJWT(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if err := sdapi.createSecret(ctx, mockPipelineID, "FOO", "secret_value", false); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, secret := range []string{"secret_api_token", "secret_jwt", "secret_value"} {
+				if strings.Contains(logs.String(), secret) || strings.Contains(jsonLines.String(), secret) {
+					t.Errorf("%s should be redacted:\n%s\n%s", secret, logs.String(), jsonLines.String())
+				}
+			}
+			if !strings.Contains(logs.String(), `method=GET url="`+testAPIServer.URL+`/v4/auth/token?api_token=REDACTED" status=200`) {
+				t.Errorf("request should be logged:\n%s", logs.String())
+			}
+
+			var traces []Trace
+			for _, line := range strings.Split(strings.TrimSpace(jsonLines.String()), "\n") {
+				var tr Trace
+				if err := json.Unmarshal([]byte(line), &tr); err != nil {
+					t.Fatal(err)
+				}
+				traces = append(traces, tr)
+			}
+			if len(traces) != 2 {
+				t.Fatalf("2 requests should be traced: %+v", traces)
+			}
+			if traces[1].Method != http.MethodPost || traces[1].Status != http.StatusCreated || traces[1].RequestBody != v.expectedRequestLog {
+				t.Errorf("unexpected trace: %+v", traces[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package sdapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+// NewTransport returns a transport following the proxy and TLS settings of network.
+// a nil network is the same as http.DefaultTransport.
+func NewTransport(network *sdctl_context.NetworkConfig) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if network == nil {
+		return t, nil
+	}
+
+	proxy, err := proxyFunc(network)
+	if err != nil {
+		return nil, err
+	}
+	t.Proxy = proxy
+
+	if network.CAFile == "" && !network.InsecureSkipVerify && network.ClientCertFile == "" && network.ClientKeyFile == "" {
+		return t, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: network.InsecureSkipVerify,
+	}
+	if network.CAFile != "" {
+		pem, err := ioutil.ReadFile(network.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in CA file %s", network.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if network.ClientCertFile != "" || network.ClientKeyFile != "" {
+		if network.ClientCertFile == "" || network.ClientKeyFile == "" {
+			return nil, errors.New("both of client certificate and key are required")
+		}
+		cert, err := tls.LoadX509KeyPair(network.ClientCertFile, network.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	t.TLSClientConfig = tlsConfig
+	return t, nil
+}
+
+// errTransport fails every request with err
+type errTransport struct {
+	err error
+}
+
+func (t errTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
+
+// proxyFunc returns the proxy of network, or the one of the environment variables
+func proxyFunc(network *sdctl_context.NetworkConfig) (func(*http.Request) (*url.URL, error), error) {
+	if network == nil || network.Proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := url.Parse(network.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %v", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %s: scheme and host are required", network.Proxy)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// ProxyURL returns the proxy of requests to the API of sdctx, or nil for direct connections.
+// fromEnv is true when the proxy is of the environment variables.
+func ProxyURL(sdctx sdctl_context.SdctlContext) (proxy *url.URL, fromEnv bool, err error) {
+	proxyOf, err := proxyFunc(sdctx.Network)
+	if err != nil {
+		return nil, false, err
+	}
+	req, err := http.NewRequest(http.MethodGet, sdctx.APIURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	proxy, err = proxyOf(req)
+	fromEnv = sdctx.Network == nil || sdctx.Network.Proxy == ""
+	return proxy, fromEnv && proxy != nil, err
+}
@@ -0,0 +1,143 @@
+package sdapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+// writeClientCert writes a self-signed client certificate and its key into dir
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sdctl"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ = x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile, cert
+}
+
+func TestNewTransport(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, clientCert := writeClientCert(t, dir)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	testAPIServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1}`))
+	}))
+	testAPIServer.TLS = &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: clientCAs}
+	testAPIServer.StartTLS()
+	defer testAPIServer.Close()
+
+	mtlsServer := httptest.NewUnstartedServer(testAPIServer.Config.Handler)
+	mtlsServer.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	mtlsServer.StartTLS()
+	defer mtlsServer.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: testAPIServer.Certificate().Raw}), 0600)
+	emptyFile := filepath.Join(dir, "empty.pem")
+	ioutil.WriteFile(emptyFile, nil, 0600)
+
+	cases := map[string]struct {
+		network     *sdctl_context.NetworkConfig
+		mtls        bool
+		expectedErr string
+	}{
+		"default transport rejects unknown CA": {nil, false, "certificate"},
+		"CA file":                              {&sdctl_context.NetworkConfig{CAFile: caFile}, false, ""},
+		"insecure skip verify":                 {&sdctl_context.NetworkConfig{InsecureSkipVerify: true}, false, ""},
+		"client certificate":                   {&sdctl_context.NetworkConfig{CAFile: caFile, ClientCertFile: certFile, ClientKeyFile: keyFile}, true, ""},
+		"mTLS without client certificate":      {&sdctl_context.NetworkConfig{CAFile: caFile}, true, "certificate"},
+		"client certificate without key":       {&sdctl_context.NetworkConfig{ClientCertFile: certFile}, false, "invalid network config"},
+		"CA file without certificates":         {&sdctl_context.NetworkConfig{CAFile: emptyFile}, false, "invalid network config"},
+		"missing CA file":                      {&sdctl_context.NetworkConfig{CAFile: filepath.Join(dir, "none.pem")}, false, "invalid network config"},
+		"proxy without scheme":                 {&sdctl_context.NetworkConfig{Proxy: "proxy:8080"}, false, "invalid network config"},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			ctx := mockSDContext
+			ctx.APIURL = testAPIServer.URL
+			if v.mtls {
+				ctx.APIURL = mtlsServer.URL
+			}
+			ctx.Network = v.network
+			sdapi, err := New(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = sdapi.GetBuild(context.Background(), 1)
+			if (err != nil) != (v.expectedErr != "") || (err != nil && !strings.Contains(err.Error(), v.expectedErr)) {
+				t.Errorf("expectedErr='%v', actual='%v'", v.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestNewTransportWithProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer proxy.Close()
+
+	ctx := mockSDContext
+	ctx.APIURL = "http://sd.example.invalid"
+	ctx.Network = &sdctl_context.NetworkConfig{Proxy: proxy.URL}
+	sdapi, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sdapi.GetBuild(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if proxied != "http://sd.example.invalid/v4/builds/1" {
+		t.Errorf("request should be sent through the proxy, but actual is '%v'", proxied)
+	}
+
+	u, fromEnv, err := ProxyURL(ctx)
+	if err != nil || u.String() != proxy.URL || fromEnv {
+		t.Errorf("configured proxy should be effective: %v, %v, %v", u, fromEnv, err)
+	}
+}
@@ -0,0 +1,197 @@
+package sdapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// uiProbeTimeout is the timeout of asking the API, and of probing candidates of the UI URL
+const uiProbeTimeout = 5 * time.Second
+
+// uiAPIPaths are API endpoints which may report the UI URL of the API
+var uiAPIPaths = []string{"/v4/status", "/v4/auth/contexts"}
+
+// UIURL returns the URL of the UI of the context without the trailing slash.
+// without ui of the context, it is guessed from the API URL, e.g. api-cd.example.com to cd.example.com
+func (sd *SDAPI) UIURL() string {
+	if sd.sdctx.UIURL != "" {
+		return strings.TrimSuffix(sd.sdctx.UIURL, "/")
+	}
+	if candidates := uiCandidates(sd.sdctx.APIURL); len(candidates) > 0 {
+		return candidates[0]
+	}
+	return strings.TrimSuffix(sd.sdctx.APIURL, "/")
+}
+
+// DiscoverUIURL asks the API for its UI URL by UIURLFromAPI. when the API does not tell it, candidates derived from
+// the API URL, such as cd.example.com for api-cd.example.com and sd.example.com for sd-api.example.com, are probed
+// in parallel and the first candidate serving an HTML page is returned. credentials are not sent to candidates.
+func (sd *SDAPI) DiscoverUIURL(ctx context.Context) (string, error) {
+	u, apiErr := sd.UIURLFromAPI(ctx)
+	if apiErr == nil {
+		return u, nil
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	candidates := uiCandidates(sd.sdctx.APIURL)
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("%v, and the UI URL cannot be derived from the API URL %q", apiErr, sd.sdctx.APIURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uiProbeTimeout)
+	defer cancel()
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	for i, c := range candidates {
+		wg.Add(1)
+		go func(i int, c string) {
+			defer wg.Done()
+			errs[i] = sd.probeUI(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	// earlier candidates are more likely
+	msgs := []string{apiErr.Error()}
+	for i, c := range candidates {
+		if errs[i] == nil {
+			return c, nil
+		}
+		msgs = append(msgs, fmt.Sprintf("%s: %v", c, errs[i]))
+	}
+	return "", fmt.Errorf("the UI is not found: %s", strings.Join(msgs, ", "))
+}
+
+// UIURLFromAPI returns the UI URL which the API reports in its responses, such as ui or ecosystem.ui of /v4/status.
+// only the API is requested, so it is cheap enough on every login.
+func (sd *SDAPI) UIURLFromAPI(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, uiProbeTimeout)
+	defer cancel()
+	for _, path := range uiAPIPaths {
+		res, err := sd.request(ctx, http.MethodGet, path, nil, nil)
+		if err != nil {
+			return "", err
+		}
+		var v interface{}
+		err = json.NewDecoder(res.Body).Decode(&v)
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK || err != nil {
+			continue
+		}
+		if u := findUIURL(v); u != "" {
+			return u, nil
+		}
+	}
+	return "", errors.New("the API does not report the UI URL")
+}
+
+// findUIURL returns an absolute URL in ui, uiUrl or ecosystem.ui of v, or of the items when v is a list
+func findUIURL(v interface{}) string {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if u := findUIURL(item); u != "" {
+				return u
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"ui", "uiUrl", "uiURL"} {
+			if s, ok := v[key].(string); ok {
+				if u, err := url.Parse(s); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+					return strings.TrimSuffix(s, "/")
+				}
+			}
+		}
+		if ecosystem, ok := v["ecosystem"].(map[string]interface{}); ok {
+			return findUIURL(ecosystem)
+		}
+	}
+	return ""
+}
+
+// probeUI returns nil when u serves an HTML page
+func (sd *SDAPI) probeUI(ctx context.Context, u string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"/", nil)
+	if err != nil {
+		return err
+	}
+	if sd.userAgent != "" {
+		req.Header.Set("User-Agent", sd.userAgent)
+	}
+	req.Header.Set("Accept", "text/html")
+	res, err := sd.client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code is %d", res.StatusCode)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType != "text/html" {
+		return fmt.Errorf("content type is %q", res.Header.Get("Content-Type"))
+	}
+	return nil
+}
+
+// uiCandidates derives candidates of the UI URL from hosting conventions of the API URL
+func uiCandidates(apiURL string) []string {
+	u, err := url.Parse(strings.TrimSuffix(apiURL, "/"))
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	labels := strings.Split(u.Hostname(), ".")
+	first, rest := labels[0], strings.Join(labels[1:], ".")
+
+	var hosts []string
+	switch {
+	case first == "api" && rest != "":
+		hosts = []string{"cd." + rest, rest}
+	case strings.HasPrefix(first, "api-"):
+		hosts = []string{strings.TrimPrefix(first, "api-") + "." + rest}
+	case strings.HasSuffix(first, "-api"):
+		hosts = []string{strings.TrimSuffix(first, "-api") + "." + rest}
+	}
+
+	var candidates []string
+	for _, h := range hosts {
+		h = strings.TrimSuffix(h, ".")
+		if port := u.Port(); port != "" {
+			h += ":" + port
+		}
+		candidates = append(candidates, (&url.URL{Scheme: u.Scheme, Host: h}).String())
+	}
+	return candidates
+}
+
+// PipelinePageURL returns the URL of a pipeline page on the UI
+func (sd *SDAPI) PipelinePageURL(pipelineID int) string {
+	return fmt.Sprintf("%s/pipelines/%d", sd.UIURL(), pipelineID)
+}
+
+// EventPageURL returns the URL of an event page on the UI
+func (sd *SDAPI) EventPageURL(pipelineID, eventID int) string {
+	return fmt.Sprintf("%s/events/%d", sd.PipelinePageURL(pipelineID), eventID)
+}
+
+// JobPageURL returns the URL of the page of a job on the UI, which shows metrics of its builds
+func (sd *SDAPI) JobPageURL(pipelineID, jobID int) string {
+	return fmt.Sprintf("%s/jobs/%d/metrics", sd.PipelinePageURL(pipelineID), jobID)
+}
+
+// TemplatePageURL returns the URL of a template page on the UI. templates without namespaces are in the default namespace.
+func (sd *SDAPI) TemplatePageURL(fullName string) string {
+	namespace, name := "default", fullName
+	if i := strings.LastIndex(fullName, "/"); i >= 0 {
+		namespace, name = fullName[:i], fullName[i+1:]
+	}
+	return fmt.Sprintf("%s/templates/%s/%s", sd.UIURL(), url.PathEscape(namespace), url.PathEscape(name))
+}
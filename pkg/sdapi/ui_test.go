@@ -0,0 +1,182 @@
+package sdapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestUIURL(t *testing.T) {
+	cases := map[string]struct {
+		apiURL   string
+		uiURL    string
+		expected string
+	}{
+		"configured": {"https://api-cd.screwdriver.cd", "https://sd.example.com/", "https://sd.example.com"},
+		"api-cd":     {"https://api-cd.screwdriver.cd/", "", "https://cd.screwdriver.cd"},
+		"sd-api":     {"https://sd-api.example.com", "", "https://sd.example.com"},
+		"api":        {"https://api.sd.example.com:8443", "", "https://cd.sd.example.com:8443"},
+		"unknown":    {"http://127.0.0.1:9090/", "", "http://127.0.0.1:9090"},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			ctx := mockSDContext
+			ctx.APIURL = v.apiURL
+			ctx.UIURL = v.uiURL
+			sdapi, err := New(ctx)
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+			if actual := sdapi.UIURL(); actual != v.expected {
+				t.Errorf("url should be %s, but actual is %s", v.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPageURLs(t *testing.T) {
+	ctx := mockSDContext
+	ctx.UIURL = "https://cd.screwdriver.cd"
+	sdapi, err := New(ctx)
+	if err != nil {
+		t.Fatal("should not cause error")
+	}
+	actual := []string{
+		sdapi.PipelinePageURL(1),
+		sdapi.BuildPageURL(1, 2),
+		sdapi.EventPageURL(1, 3),
+		sdapi.JobPageURL(1, 4),
+		sdapi.TemplatePageURL("screwdriver/node"),
+		sdapi.TemplatePageURL("node"),
+	}
+	expected := []string{
+		"https://cd.screwdriver.cd/pipelines/1",
+		"https://cd.screwdriver.cd/pipelines/1/builds/2",
+		"https://cd.screwdriver.cd/pipelines/1/events/3",
+		"https://cd.screwdriver.cd/pipelines/1/jobs/4/metrics",
+		"https://cd.screwdriver.cd/templates/screwdriver/node",
+		"https://cd.screwdriver.cd/templates/default/node",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiscoverUIURL(t *testing.T) {
+	cases := map[string]struct {
+		apiURL      string
+		status      string
+		pages       map[string]string
+		expected    string
+		expectedErr string
+	}{
+		"reported by the API": {
+			"https://api.example.com", `{"ecosystem":{"ui":"https://sd.example.com/"}}`, map[string]string{"cd.example.com": "text/html"}, "https://sd.example.com", "",
+		},
+		"first candidate": {
+			"https://api.example.com", `"OK"`, map[string]string{"cd.example.com": "text/html; charset=utf-8", "example.com": "text/html"}, "https://cd.example.com", "",
+		},
+		"second candidate": {
+			"https://api.example.com", "", map[string]string{"example.com": "text/html"}, "https://example.com", "",
+		},
+		"not HTML": {
+			"https://sd-api.example.com", "", map[string]string{"sd.example.com": "application/json"}, "", "content type",
+		},
+		"not found": {
+			"https://api-cd.example.com", "", map[string]string{}, "", "status code is 404",
+		},
+		"no candidates": {
+			"http://127.0.0.1:9090", "", map[string]string{}, "", "cannot be derived",
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			apiHost := strings.TrimPrefix(strings.TrimPrefix(v.apiURL, "https://"), "http://")
+			client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				w := httptest.NewRecorder()
+				if req.URL.Host == apiHost {
+					if req.URL.Path == "/v4/status" && v.status != "" {
+						w.WriteString(v.status)
+					} else {
+						w.WriteHeader(http.StatusNotFound)
+					}
+					return w.Result(), nil
+				}
+				if req.Header.Get("Authorization") != "" {
+					t.Errorf("credentials should not be sent to %s", req.URL)
+				}
+				if contentType, ok := v.pages[req.URL.Host]; ok {
+					w.Header().Set("Content-Type", contentType)
+					w.WriteString("<html></html>")
+				} else {
+					w.WriteHeader(http.StatusNotFound)
+				}
+				return w.Result(), nil
+			})}
+			ctx := mockSDContext
+			ctx.APIURL = v.apiURL
+			sdapi, err := New(ctx, WithHTTPClient(client))
+			if err != nil {
+				t.Fatal("should not cause error")
+			}
+
+			actual, err := sdapi.DiscoverUIURL(context.Background())
+			if v.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), v.expectedErr) {
+					t.Errorf("error should contain '%v', but actual is '%v'", v.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != v.expected {
+				t.Errorf("url should be %s, but actual is %s", v.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFindUIURL(t *testing.T) {
+	cases := map[string]struct {
+		body     string
+		expected string
+	}{
+		"ui":           {`{"ui":"https://cd.example.com/"}`, "https://cd.example.com"},
+		"ecosystem.ui": {`{"ecosystem":{"ui":"https://cd.example.com"}}`, "https://cd.example.com"},
+		"in a list":    {`[{"context":"github:github.com"},{"uiUrl":"https://cd.example.com"}]`, "https://cd.example.com"},
+		"not a URL":    {`{"ui":"cd.example.com"}`, ""},
+		"no UI":        {`"OK"`, ""},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			var body interface{}
+			if err := json.Unmarshal([]byte(v.body), &body); err != nil {
+				t.Fatal(err)
+			}
+			if actual := findUIURL(body); actual != v.expected {
+				t.Errorf("url should be %q, but actual is %q", v.expected, actual)
+			}
+		})
+	}
+}
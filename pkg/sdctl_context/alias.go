@@ -0,0 +1,92 @@
+package sdctl_context
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxAliasDepth limits aliases expanding to other aliases
+const maxAliasDepth = 16
+
+// ExpandAlias replaces the alias in args[0] with its command line, in the style of git aliases.
+// aliases may expand to other aliases. commands of sdctl, for which isCommand returns true, are never expanded.
+func (sc SdctlConfig) ExpandAlias(args []string, isCommand func(name string) bool) ([]string, error) {
+	var expanded []string
+	for len(args) > 0 && !isCommand(args[0]) {
+		commandLine, ok := sc.Aliases[args[0]]
+		if !ok {
+			break
+		}
+		expanded = append(expanded, args[0])
+		if len(expanded) > maxAliasDepth || contains(expanded[:len(expanded)-1], args[0]) {
+			return nil, fmt.Errorf("alias loop: %s", strings.Join(expanded, " -> "))
+		}
+		words, err := SplitCommandLine(commandLine)
+		if err != nil {
+			return nil, fmt.Errorf("alias %s is invalid: %v", args[0], err)
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("alias %s is empty", args[0])
+		}
+		args = append(words, args[1:]...)
+	}
+	return args, nil
+}
+
+// SplitCommandLine splits a command line into words like a shell without expansions.
+// words are separated by spaces, and quoted by single or double quotes or escaped by backslashes.
+func SplitCommandLine(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote %c", quote)
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
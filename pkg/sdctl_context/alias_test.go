@@ -0,0 +1,120 @@
+package sdctl_context
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSdctlConfig_ExpandAlias(t *testing.T) {
+	config := SdctlConfig{Aliases: map[string]string{
+		"deploy-prod": "build org/app deploy-prod",
+		"dp":          "deploy-prod",
+		"build":       "build 1234",
+		"loop":        "loop2 -v",
+		"loop2":       "loop",
+		"broken":      `secret set --value "x`,
+	}}
+	isCommand := func(name string) bool {
+		return name == "build" || name == "secret"
+	}
+
+	cases := map[string]struct {
+		args        []string
+		expected    []string
+		expectedErr string
+	}{
+		"alias": {
+			[]string{"deploy-prod", "--timeout", "1m"}, []string{"build", "org/app", "deploy-prod", "--timeout", "1m"}, "",
+		},
+		"alias of alias": {
+			[]string{"dp"}, []string{"build", "org/app", "deploy-prod"}, "",
+		},
+		"command wins": {
+			[]string{"build", "1", "main"}, []string{"build", "1", "main"}, "",
+		},
+		"not alias": {
+			[]string{"unknown"}, []string{"unknown"}, "",
+		},
+		"no args": {
+			[]string{}, []string{}, "",
+		},
+		"loop": {
+			[]string{"loop"}, nil, "alias loop: loop -> loop2 -> loop",
+		},
+		"invalid": {
+			[]string{"broken"}, nil, "unterminated quote",
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			actual, err := config.ExpandAlias(v.args, isCommand)
+			if v.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), v.expectedErr) {
+					t.Errorf("error should contain '%v', but actual is '%v'", v.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(v.expected, actual); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	cases := map[string]struct {
+		commandLine string
+		expected    []string
+		expectedErr string
+	}{
+		"words": {
+			"build  org/app\tmain", []string{"build", "org/app", "main"}, "",
+		},
+		"quotes": {
+			`secret set -k KEY --value "a b" 'c "d"' e""f`, []string{"secret", "set", "-k", "KEY", "--value", "a b", `c "d"`, "ef"}, "",
+		},
+		"escapes": {
+			`a\ b "c\"d" 'e\f'`, []string{"a b", `c"d`, `e\f`}, "",
+		},
+		"empty quotes": {
+			`build ''`, []string{"build", ""}, "",
+		},
+		"empty": {
+			"  ", nil, "",
+		},
+		"unterminated quote": {
+			`build "main`, nil, "unterminated quote",
+		},
+		"trailing backslash": {
+			`build \`, nil, "trailing backslash",
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			actual, err := SplitCommandLine(v.commandLine)
+			if v.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), v.expectedErr) {
+					t.Errorf("error should contain '%v', but actual is '%v'", v.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(v.expected, actual); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,18 +1,19 @@
 package sdctl_context
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"sort"
+	"time"
 )
 
 var (
 	UserTokenKey      = "token"
+	PipelineTokenKey  = "pipeline_token"
 	APIURLKey         = "api"
 	SDJWTKey          = "jwt"
+	UIURLKey          = "ui"
 	CurrentContextKey = "current_context"
 	ContextsKey       = "contexts"
 )
@@ -22,30 +23,124 @@ type SdctlContext struct {
 	UserToken string `json:"token"`
 	APIURL    string `json:"api"`
 	SDJWT     string `json:"jwt"`
+	// PipelineToken makes the context pipeline-token-based, it is exchanged for a JWT instead of UserToken
+	PipelineToken string `json:"pipeline_token,omitempty"`
+	// Retry configures retries of idempotent API requests, the default policy is used when it is nil
+	Retry *RetryConfig `json:"retry,omitempty"`
+	// Network configures the proxy and TLS of API requests, the defaults of Go are used when it is nil
+	Network *NetworkConfig `json:"network,omitempty"`
+	// UIURL is the URL of the UI such as https://cd.screwdriver.cd, guessed from APIURL when it is empty
+	UIURL string `json:"ui,omitempty"`
+	// Defaults are used by commands when their pipeline, start_from or output format is omitted
+	Defaults *DefaultsConfig `json:"defaults,omitempty"`
+	// BannerExpirations is expiration times of banners by ID, enforced by "banner gc"
+	BannerExpirations map[int]time.Time `json:"banner_expirations,omitempty"`
+	// BannerSchedules is windows of banners by ID, enforced by "banner sync"
+	BannerSchedules map[int]BannerSchedule `json:"banner_schedules,omitempty"`
+}
+
+// APIToken returns the token exchanged for a JWT
+func (sdctx SdctlContext) APIToken() string {
+	if sdctx.PipelineToken != "" {
+		return sdctx.PipelineToken
+	}
+	return sdctx.UserToken
+}
+
+// RetryConfig configures retries of idempotent API requests on transient errors
+type RetryConfig struct {
+	// MaxAttempts includes the first attempt. 1 disables retries.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// MaxDelay caps the backoff between attempts, such as "10s"
+	MaxDelay string `json:"max_delay,omitempty"`
+}
+
+// NetworkConfig configures the proxy and TLS of API requests for networks such as behind a corporate proxy with an internal CA
+type NetworkConfig struct {
+	// Proxy is the URL of the proxy, HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used when it is empty
+	Proxy string `json:"proxy,omitempty"`
+	// CAFile is a PEM file of CA certificates trusted in addition to the system ones
+	CAFile string `json:"ca_file,omitempty"`
+	// InsecureSkipVerify disables verification of server certificates
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+	// ClientCertFile and ClientKeyFile are PEM files of the client certificate for mutual TLS
+	ClientCertFile string `json:"client_cert_file,omitempty"`
+	ClientKeyFile  string `json:"client_key_file,omitempty"`
+}
+
+// DefaultsConfig holds defaults of arguments and flags of commands in a context
+type DefaultsConfig struct {
+	// Pipeline is an ID or a repository name, such as org/app
+	Pipeline string `json:"pipeline,omitempty"`
+	// StartFrom is the job or the trigger a build starts from, such as ~commit
+	StartFrom string `json:"start_from,omitempty"`
+	// Output is the format of lists, table or json
+	Output string `json:"output,omitempty"`
+}
+
+// Output formats of lists
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// OutputFormats are the valid output formats
+var OutputFormats = []string{OutputTable, OutputJSON}
+
+// GetDefaults returns the defaults of the context, which are empty when they are not set
+func (sdctx SdctlContext) GetDefaults() DefaultsConfig {
+	if sdctx.Defaults == nil {
+		return DefaultsConfig{}
+	}
+	return *sdctx.Defaults
+}
+
+// BannerSchedule is the window in which a banner is active
+type BannerSchedule struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ActiveAt reports whether t is in the window
+func (bs BannerSchedule) ActiveAt(t time.Time) bool {
+	return !t.Before(bs.Start) && t.Before(bs.End)
 }
 
 // SdctlConfig represents the context of Screwdriver.cd
 type SdctlConfig struct {
+	// Version is the schema version of the config file, older files are migrated on load
+	Version        int                     `json:"version"`
 	CurrentContext string                  `json:"current_context"`
 	SdctlContexts  map[string]SdctlContext `json:"contexts"`
+	// BannerTemplates is text/template of banner messages by name, shared by all contexts
+	BannerTemplates map[string]string `json:"banner_templates,omitempty"`
+	// Aliases are command lines by name, such as "deploy-prod: build org/app deploy-prod", shared by all contexts
+	Aliases map[string]string `json:"aliases,omitempty"`
 }
 
+// LoadConfig reads the config file at configPath, creating it when it does not exist or force is true.
+// files of older versions are migrated and written back.
 func LoadConfig(configPath string, force bool) (SdctlConfig, error) {
-	if _, err := os.Stat(configPath); err != nil || force {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) || force {
 		return initConfigFile(configPath)
+	} else if err != nil {
+		return SdctlConfig{}, err
 	}
-
 	return getSdctlConfigFromFile(configPath)
 }
 
-func getSdctlConfigFromFile(path string) (sdctlConfig SdctlConfig, err error) {
-	confFile, err := ioutil.ReadFile(path)
+func getSdctlConfigFromFile(path string) (SdctlConfig, error) {
+	config, version, err := ReadConfig(path)
 	if err != nil {
-		return
+		return config, err
 	}
-
-	err = json.Unmarshal(confFile, &sdctlConfig)
-	return
+	if version < CurrentVersion {
+		if err := WriteConfig(path, config); err != nil {
+			return config, fmt.Errorf("failed to write config migrated from version %d: %v", version, err)
+		}
+	}
+	remember(path, config)
+	return config, nil
 }
 
 func initConfigFile(configPath string) (SdctlConfig, error) {
@@ -55,23 +150,37 @@ func initConfigFile(configPath string) (SdctlConfig, error) {
 		SDJWT:     "",
 	}
 	config := SdctlConfig{
+		Version:        CurrentVersion,
 		CurrentContext: "default",
 		SdctlContexts:  make(map[string]SdctlContext),
 	}
 	config.SdctlContexts["default"] = context
-
-	f, _ := json.Marshal(config)
-	err := ioutil.WriteFile(configPath, f, 0660)
-
+	err := WriteConfig(configPath, config)
 	return config, err
 }
 
+// Update writes the config to configPath. changes of other sdctl processes since it was loaded are kept,
+// unless they are of the same contexts or banner templates.
 func (sc *SdctlConfig) Update(configPath string) error {
-	f, err := json.Marshal(sc)
+	unlock, err := lockFile(configPath)
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(configPath, f, 0660)
+	defer unlock()
+
+	config := *sc
+	config.Version = CurrentVersion
+	if base, ok := loaded.Load(configPath); ok {
+		if theirs, _, err := ReadConfig(configPath); err == nil && !sameJSON(base, theirs) {
+			config = mergeConfig(base.(SdctlConfig), config, theirs)
+		}
+	}
+	if err := writeConfig(configPath, config); err != nil {
+		return err
+	}
+	remember(configPath, config)
+	*sc = config
+	return nil
 }
 
 func (sc *SdctlConfig) PrintParam(paramName string, w io.Writer) {
@@ -85,10 +194,14 @@ func (sc *SdctlConfig) PrintParam(paramName string, w io.Writer) {
 	switch {
 	case paramName == UserTokenKey:
 		s = sdctx.UserToken + "\n"
+	case paramName == PipelineTokenKey:
+		s = sdctx.PipelineToken + "\n"
 	case paramName == APIURLKey:
 		s = sdctx.APIURL + "\n"
 	case paramName == SDJWTKey:
 		s = sdctx.SDJWT + "\n"
+	case paramName == UIURLKey:
+		s = sdctx.UIURL + "\n"
 	case paramName == CurrentContextKey:
 		s = sc.CurrentContext + "\n"
 	case paramName == ContextsKey:
@@ -121,8 +234,14 @@ func (sc *SdctlConfig) SetParam(paramName, param string, w io.Writer) {
 	sdctx := sc.SdctlContexts[sc.CurrentContext]
 
 	switch {
+	// the JWT is of the identity of the old token, so it is fetched again with the new one
 	case paramName == UserTokenKey:
 		sdctx.UserToken = param
+		sdctx.SDJWT = ""
+		sc.SdctlContexts[sc.CurrentContext] = sdctx
+	case paramName == PipelineTokenKey:
+		sdctx.PipelineToken = param
+		sdctx.SDJWT = ""
 		sc.SdctlContexts[sc.CurrentContext] = sdctx
 	case paramName == APIURLKey:
 		sdctx.APIURL = param
@@ -130,6 +249,9 @@ func (sc *SdctlConfig) SetParam(paramName, param string, w io.Writer) {
 	case paramName == SDJWTKey:
 		sdctx.SDJWT = param
 		sc.SdctlContexts[sc.CurrentContext] = sdctx
+	case paramName == UIURLKey:
+		sdctx.UIURL = param
+		sc.SdctlContexts[sc.CurrentContext] = sdctx
 	case paramName == CurrentContextKey:
 		if _, ok := sc.SdctlContexts[param]; !ok {
 			sc.SdctlContexts[param] = SdctlContext{
@@ -143,3 +265,116 @@ func (sc *SdctlConfig) SetParam(paramName, param string, w io.Writer) {
 
 	fmt.Fprintf(w, "'%v' is set\n", paramName)
 }
+
+// SetRetry sets the retry config of the current context
+func (sc *SdctlConfig) SetRetry(retry RetryConfig) {
+	sdctx := sc.SdctlContexts[sc.CurrentContext]
+	sdctx.Retry = &retry
+	sc.SdctlContexts[sc.CurrentContext] = sdctx
+}
+
+// SetNetwork sets the network config of the current context. an empty config removes it.
+func (sc *SdctlConfig) SetNetwork(network NetworkConfig) {
+	sdctx := sc.SdctlContexts[sc.CurrentContext]
+	sdctx.Network = &network
+	if network == (NetworkConfig{}) {
+		sdctx.Network = nil
+	}
+	sc.SdctlContexts[sc.CurrentContext] = sdctx
+}
+
+// SetDefaults sets the defaults of the current context. empty defaults remove them.
+func (sc *SdctlConfig) SetDefaults(defaults DefaultsConfig) {
+	sdctx := sc.SdctlContexts[sc.CurrentContext]
+	sdctx.Defaults = &defaults
+	if defaults == (DefaultsConfig{}) {
+		sdctx.Defaults = nil
+	}
+	sc.SdctlContexts[sc.CurrentContext] = sdctx
+}
+
+// SetBannerExpiration records the expiration time of a banner in the current context
+func (sc *SdctlConfig) SetBannerExpiration(id int, expiresAt time.Time) {
+	sdctx := sc.SdctlContexts[sc.CurrentContext]
+	if sdctx.BannerExpirations == nil {
+		sdctx.BannerExpirations = make(map[int]time.Time)
+	}
+	sdctx.BannerExpirations[id] = expiresAt
+	sc.SdctlContexts[sc.CurrentContext] = sdctx
+}
+
+// RemoveBannerExpiration forgets the expiration time of a banner in the current context, and returns whether it was recorded
+func (sc *SdctlConfig) RemoveBannerExpiration(id int) bool {
+	sdctx := sc.SdctlContexts[sc.CurrentContext]
+	if _, ok := sdctx.BannerExpirations[id]; !ok {
+		return false
+	}
+	delete(sdctx.BannerExpirations, id)
+	if len(sdctx.BannerExpirations) == 0 {
+		sdctx.BannerExpirations = nil
+	}
+	sc.SdctlContexts[sc.CurrentContext] = sdctx
+	return true
+}
+
+// ExpiredBanners returns IDs of banners expired at now in the current context
+func (sc *SdctlConfig) ExpiredBanners(now time.Time) []int {
+	var ids []int
+	for id, expiresAt := range sc.SdctlContexts[sc.CurrentContext].BannerExpirations {
+		if !expiresAt.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// SetBannerSchedule records the window of a banner in the current context
+func (sc *SdctlConfig) SetBannerSchedule(id int, schedule BannerSchedule) {
+	sdctx := sc.SdctlContexts[sc.CurrentContext]
+	if sdctx.BannerSchedules == nil {
+		sdctx.BannerSchedules = make(map[int]BannerSchedule)
+	}
+	sdctx.BannerSchedules[id] = schedule
+	sc.SdctlContexts[sc.CurrentContext] = sdctx
+}
+
+// RemoveBannerSchedule forgets the window of a banner in the current context
+func (sc *SdctlConfig) RemoveBannerSchedule(id int) {
+	sdctx := sc.SdctlContexts[sc.CurrentContext]
+	delete(sdctx.BannerSchedules, id)
+	if len(sdctx.BannerSchedules) == 0 {
+		sdctx.BannerSchedules = nil
+	}
+	sc.SdctlContexts[sc.CurrentContext] = sdctx
+}
+
+// SetBannerTemplate stores a banner template. an empty text removes the template.
+func (sc *SdctlConfig) SetBannerTemplate(name, text string) {
+	if text == "" {
+		delete(sc.BannerTemplates, name)
+		if len(sc.BannerTemplates) == 0 {
+			sc.BannerTemplates = nil
+		}
+		return
+	}
+	if sc.BannerTemplates == nil {
+		sc.BannerTemplates = make(map[string]string)
+	}
+	sc.BannerTemplates[name] = text
+}
+
+// SetAlias stores a command alias. an empty command line removes the alias.
+func (sc *SdctlConfig) SetAlias(name, commandLine string) {
+	if commandLine == "" {
+		delete(sc.Aliases, name)
+		if len(sc.Aliases) == 0 {
+			sc.Aliases = nil
+		}
+		return
+	}
+	if sc.Aliases == nil {
+		sc.Aliases = make(map[string]string)
+	}
+	sc.Aliases[name] = commandLine
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -17,6 +18,7 @@ var (
 	testSDJWT   = "test_jwt1"
 	testContext = "test_context"
 	newToken    = "new_token1"
+	newPipeline = "new_pipeline_token1"
 	newAPIURL   = "new_url1"
 	newSDJWT    = "new_jwt1"
 	newContext  = "new_context"
@@ -94,6 +96,7 @@ func testLoadConfig(t *testing.T, hasFile, force bool) {
 		SDJWT:     "",
 	}
 	expectConfig := SdctlConfig{
+		Version:        CurrentVersion,
 		CurrentContext: "default",
 		SdctlContexts:  make(map[string]SdctlContext),
 	}
@@ -115,6 +118,8 @@ func testLoadConfig(t *testing.T, hasFile, force bool) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		// the unversioned file is migrated
+		mockConfig.Version = CurrentVersion
 		if !cmp.Equal(mockConfig, config, nil) {
 			t.Errorf("expected='%v', actual='%v'", mockConfig, config)
 		}
@@ -142,6 +147,10 @@ func TestSdctlConfig_PrintParam(t *testing.T) {
 			UserTokenKey,
 			testToken + "\n",
 		},
+		"print empty pipeline token for default context": {
+			PipelineTokenKey,
+			"\n",
+		},
 		"print api url for default context": {
 			APIURLKey,
 			testAPIURL + "\n",
@@ -150,6 +159,10 @@ func TestSdctlConfig_PrintParam(t *testing.T) {
 			SDJWTKey,
 			testSDJWT + "\n",
 		},
+		"print empty ui url for default context": {
+			UIURLKey,
+			"\n",
+		},
 		"print current context": {
 			CurrentContextKey,
 			"default" + "\n",
@@ -186,6 +199,11 @@ func TestSdctlConfig_SetParam(t *testing.T) {
 			newToken,
 			fmt.Sprintf("'%v' is set\n", UserTokenKey),
 		},
+		"set PipelineToken": {
+			PipelineTokenKey,
+			newPipeline,
+			fmt.Sprintf("'%v' is set\n", PipelineTokenKey),
+		},
 		"set APIURL": {
 			APIURLKey,
 			newAPIURL,
@@ -196,6 +214,11 @@ func TestSdctlConfig_SetParam(t *testing.T) {
 			newSDJWT,
 			fmt.Sprintf("'%v' is set\n", SDJWTKey),
 		},
+		"set UIURL": {
+			UIURLKey,
+			"https://cd.screwdriver.cd",
+			fmt.Sprintf("'%v' is set\n", UIURLKey),
+		},
 		"set CurrentContext and it's new context": {
 			CurrentContextKey,
 			newContext,
@@ -239,6 +262,17 @@ func testSetParam(t *testing.T, paramName, param, expectedMessage string) {
 		if config.SdctlContexts[config.CurrentContext].UserToken != param {
 			t.Errorf("expect='%v', actual='%v'", param, config.SdctlContexts[config.CurrentContext].UserToken)
 		}
+		if config.SdctlContexts[config.CurrentContext].SDJWT != "" {
+			t.Errorf("JWT of the old token should be cleared")
+		}
+	case paramName == PipelineTokenKey:
+		sdctx := config.SdctlContexts[config.CurrentContext]
+		if sdctx.PipelineToken != param || sdctx.APIToken() != param {
+			t.Errorf("expect='%v', actual='%v'", param, sdctx.PipelineToken)
+		}
+		if sdctx.SDJWT != "" {
+			t.Errorf("JWT of the old token should be cleared")
+		}
 	case paramName == APIURLKey:
 		if config.SdctlContexts[config.CurrentContext].APIURL != param {
 			t.Errorf("expect='%v', actual='%v'", param, config.SdctlContexts[config.CurrentContext].APIURL)
@@ -247,6 +281,10 @@ func testSetParam(t *testing.T, paramName, param, expectedMessage string) {
 		if config.SdctlContexts[config.CurrentContext].SDJWT != param {
 			t.Errorf("expect='%v', actual='%v'", param, config.SdctlContexts[config.CurrentContext].SDJWT)
 		}
+	case paramName == UIURLKey:
+		if config.SdctlContexts[config.CurrentContext].UIURL != param {
+			t.Errorf("expect='%v', actual='%v'", param, config.SdctlContexts[config.CurrentContext].UIURL)
+		}
 	case paramName == CurrentContextKey:
 		if config.CurrentContext != param {
 			t.Errorf("expect='%v', actual='%v'", param, config.CurrentContext)
@@ -264,3 +302,142 @@ func testSetParam(t *testing.T, paramName, param, expectedMessage string) {
 		t.Fatal("should not come here")
 	}
 }
+
+func TestSdctlConfig_BannerExpiration(t *testing.T) {
+	config := createMockSdctlConfig()
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	config.SetBannerExpiration(1, now.Add(-time.Hour))
+	config.SetBannerExpiration(2, now)
+	config.SetBannerExpiration(3, now.Add(time.Hour))
+
+	if diff := cmp.Diff([]int{1, 2}, config.ExpiredBanners(now)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if len(config.SdctlContexts[testContext].BannerExpirations) != 0 {
+		t.Errorf("other contexts should not be changed")
+	}
+
+	if !config.RemoveBannerExpiration(1) || !config.RemoveBannerExpiration(2) {
+		t.Errorf("recorded expirations should be removed")
+	}
+	if config.RemoveBannerExpiration(2) || config.RemoveBannerExpiration(4) {
+		t.Errorf("expirations not recorded should not be removed")
+	}
+	if diff := cmp.Diff([]int(nil), config.ExpiredBanners(now)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	config.RemoveBannerExpiration(3)
+	if config.SdctlContexts[config.CurrentContext].BannerExpirations != nil {
+		t.Errorf("expirations should be nil when empty")
+	}
+}
+
+func TestBannerSchedule_ActiveAt(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := BannerSchedule{Start: start, End: start.Add(time.Hour)}
+
+	cases := map[string]struct {
+		at       time.Time
+		expected bool
+	}{
+		"before start":  {start.Add(-time.Second), false},
+		"at start":      {start, true},
+		"in the window": {start.Add(30 * time.Minute), true},
+		"at end":        {start.Add(time.Hour), false},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			if actual := schedule.ActiveAt(v.at); actual != v.expected {
+				t.Errorf("expect='%v', actual='%v'", v.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSdctlConfig_BannerScheduleAndTemplate(t *testing.T) {
+	config := createMockSdctlConfig()
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := BannerSchedule{Start: start, End: start.Add(time.Hour)}
+
+	config.SetBannerSchedule(1, schedule)
+	if diff := cmp.Diff(map[int]BannerSchedule{1: schedule}, config.SdctlContexts[config.CurrentContext].BannerSchedules); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	config.RemoveBannerSchedule(1)
+	if config.SdctlContexts[config.CurrentContext].BannerSchedules != nil {
+		t.Errorf("schedules should be nil when empty")
+	}
+
+	config.SetBannerTemplate("maintenance", "down from {{.Start}}")
+	if config.BannerTemplates["maintenance"] != "down from {{.Start}}" {
+		t.Errorf("template should be set: %v", config.BannerTemplates)
+	}
+	config.SetBannerTemplate("maintenance", "")
+	if config.BannerTemplates != nil {
+		t.Errorf("templates should be nil when empty")
+	}
+}
+
+func TestSdctlConfig_SetRetry(t *testing.T) {
+	config := createMockSdctlConfig()
+	retry := RetryConfig{MaxAttempts: 5, MaxDelay: "20s"}
+
+	config.SetRetry(retry)
+	if diff := cmp.Diff(&retry, config.SdctlContexts[config.CurrentContext].Retry); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if config.SdctlContexts[testContext].Retry != nil {
+		t.Errorf("retry of other contexts should not be set")
+	}
+}
+
+func TestSdctlConfig_SetNetwork(t *testing.T) {
+	config := createMockSdctlConfig()
+	network := NetworkConfig{Proxy: "http://proxy:8080", CAFile: "/etc/ca.pem"}
+
+	config.SetNetwork(network)
+	if diff := cmp.Diff(&network, config.SdctlContexts[config.CurrentContext].Network); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if config.SdctlContexts[testContext].Network != nil {
+		t.Errorf("network of other contexts should not be set")
+	}
+	config.SetNetwork(NetworkConfig{})
+	if config.SdctlContexts[config.CurrentContext].Network != nil {
+		t.Errorf("empty network should be removed")
+	}
+}
+
+func TestSdctlConfig_SetDefaults(t *testing.T) {
+	config := createMockSdctlConfig()
+	defaults := DefaultsConfig{Pipeline: "org/app", StartFrom: "~commit"}
+
+	config.SetDefaults(defaults)
+	if diff := cmp.Diff(defaults, config.SdctlContexts[config.CurrentContext].GetDefaults()); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if config.SdctlContexts[testContext].Defaults != nil {
+		t.Errorf("defaults of other contexts should not be set")
+	}
+	config.SetDefaults(DefaultsConfig{})
+	if config.SdctlContexts[config.CurrentContext].Defaults != nil {
+		t.Errorf("empty defaults should be removed")
+	}
+}
+
+func TestSdctlConfig_SetAlias(t *testing.T) {
+	config := createMockSdctlConfig()
+
+	config.SetAlias("deploy-prod", "build org/app deploy-prod")
+	if diff := cmp.Diff(map[string]string{"deploy-prod": "build org/app deploy-prod"}, config.Aliases); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	config.SetAlias("deploy-prod", "")
+	if config.Aliases != nil {
+		t.Errorf("empty aliases should be removed: %v", config.Aliases)
+	}
+}
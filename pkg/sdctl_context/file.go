@@ -0,0 +1,343 @@
+package sdctl_context
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// lockTimeout is how long to wait for another sdctl writing the config
+	lockTimeout = 10 * time.Second
+	// staleLockAge is the age of locks left by killed processes, which are taken over
+	staleLockAge = time.Minute
+)
+
+// loaded keeps configs as read by path, to merge changes of other sdctl processes on Update
+var loaded sync.Map
+
+// IsYAML returns whether the config file at path is YAML, such as ~/.config/sdctl/config.yaml. otherwise it is JSON.
+func IsYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// ReadConfig reads and migrates the config file at path without writing it.
+// it returns the version of the file before the migration.
+func ReadConfig(path string) (SdctlConfig, int, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return SdctlConfig{}, 0, err
+	}
+	config, version, err := ParseConfig(b, IsYAML(path))
+	if err != nil {
+		return SdctlConfig{}, 0, fmt.Errorf("config file %s is invalid: %v", path, err)
+	}
+	return config, version, nil
+}
+
+// ParseConfig parses and migrates a config in YAML or JSON. it returns the version of b before the migration.
+func ParseConfig(b []byte, isYAML bool) (SdctlConfig, int, error) {
+	var config SdctlConfig
+	if len(bytes.TrimSpace(b)) == 0 {
+		return config, 0, errors.New("config is empty")
+	}
+	raw := map[string]interface{}{}
+	if isYAML {
+		var v map[string]interface{}
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return config, 0, err
+		}
+		raw = jsonMap(v)
+	} else if err := json.Unmarshal(b, &raw); err != nil {
+		return config, 0, err
+	}
+
+	version, err := migrate(raw)
+	if err != nil {
+		return config, version, err
+	}
+	j, err := json.Marshal(raw)
+	if err != nil {
+		return config, version, err
+	}
+	if err := json.Unmarshal(j, &config); err != nil {
+		return config, version, err
+	}
+	if config.SdctlContexts == nil {
+		config.SdctlContexts = make(map[string]SdctlContext)
+	}
+	return config, version, nil
+}
+
+// jsonMap converts maps decoded from YAML, whose nested maps have keys of interface{}, to be encoded in JSON
+func jsonMap(m map[string]interface{}) map[string]interface{} {
+	converted := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		converted[k] = jsonValue(v)
+	}
+	return converted
+}
+
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case map[string]interface{}:
+		return jsonMap(v)
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = jsonValue(e)
+		}
+		return l
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+// MarshalConfig encodes a config in YAML or JSON with the current version
+func MarshalConfig(config SdctlConfig, isYAML bool) ([]byte, error) {
+	config.Version = CurrentVersion
+	j, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if !isYAML {
+		return append(j, '\n'), nil
+	}
+	// YAML follows keys of JSON
+	var v interface{}
+	if err := json.Unmarshal(j, &v); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
+
+// WriteConfig writes a config to path atomically while holding the lock of path
+func WriteConfig(path string, config SdctlConfig) error {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := writeConfig(path, config); err != nil {
+		return err
+	}
+	remember(path, config)
+	return nil
+}
+
+// ReplaceConfig replaces the config file at path with edited, which must be a valid config.
+// it fails when the file is no longer original, changed by another sdctl while editing. comments in YAML are kept.
+func ReplaceConfig(path string, original, edited []byte) error {
+	config, version, err := ParseConfig(edited, IsYAML(path))
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if !bytes.Equal(current, original) {
+		return fmt.Errorf("config file %s was changed by another sdctl", path)
+	}
+	if version < CurrentVersion {
+		err = writeConfig(path, config)
+	} else {
+		err = writeFileAtomic(path, edited)
+	}
+	if err != nil {
+		return err
+	}
+	remember(path, config)
+	return nil
+}
+
+func writeConfig(path string, config SdctlConfig) error {
+	b, err := MarshalConfig(config, IsYAML(path))
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b)
+}
+
+// writeFileAtomic writes b into a temporary file next to path and renames it to path,
+// so that readers never see a partially written file. the mode of the existing file is kept.
+func writeFileAtomic(path string, b []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	mode := os.FileMode(0600)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// lockFile takes the lock of path by creating path.lock exclusively, waiting for other sdctl processes up to lockTimeout.
+// locks older than staleLockAge are left by killed processes and taken over.
+func lockFile(path string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	lock := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if fi, err := os.Stat(lock); err == nil && time.Since(fi.ModTime()) > staleLockAge {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("config file %s is locked by another sdctl, remove %s if no sdctl is running", path, lock)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// remember keeps a copy of config as in the file at path
+func remember(path string, config SdctlConfig) {
+	if c, err := copyConfig(config); err == nil {
+		loaded.Store(path, c)
+	}
+}
+
+func copyConfig(config SdctlConfig) (SdctlConfig, error) {
+	var c SdctlConfig
+	b, err := json.Marshal(config)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// mergeConfig applies changes from base to mine onto theirs, the config written by another sdctl.
+// contexts, banner templates and aliases are merged by name, and mine wins on conflicts.
+func mergeConfig(base, mine, theirs SdctlConfig) SdctlConfig {
+	merged, err := copyConfig(theirs)
+	if err != nil {
+		return mine
+	}
+	if merged.SdctlContexts == nil {
+		merged.SdctlContexts = make(map[string]SdctlContext)
+	}
+	if mine.CurrentContext != base.CurrentContext {
+		merged.CurrentContext = mine.CurrentContext
+	}
+
+	for name := range unionKeys(base.SdctlContexts, mine.SdctlContexts) {
+		b, inBase := base.SdctlContexts[name]
+		m, inMine := mine.SdctlContexts[name]
+		if inBase == inMine && (!inMine || sameJSON(b, m)) {
+			continue
+		}
+		if inMine {
+			merged.SdctlContexts[name] = m
+		} else {
+			delete(merged.SdctlContexts, name)
+		}
+	}
+
+	merged.BannerTemplates = mergeStrings(base.BannerTemplates, mine.BannerTemplates, merged.BannerTemplates)
+	merged.Aliases = mergeStrings(base.Aliases, mine.Aliases, merged.Aliases)
+	return merged
+}
+
+// mergeStrings applies changes from base to mine onto merged by key
+func mergeStrings(base, mine, merged map[string]string) map[string]string {
+	for name := range unionKeys(base, mine) {
+		b, inBase := base[name]
+		m, inMine := mine[name]
+		if inBase == inMine && b == m {
+			continue
+		}
+		if inMine {
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			merged[name] = m
+		} else {
+			delete(merged, name)
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// unionKeys returns keys of maps of string keys
+func unionKeys(maps ...interface{}) map[string]bool {
+	keys := map[string]bool{}
+	for _, m := range maps {
+		switch m := m.(type) {
+		case map[string]SdctlContext:
+			for k := range m {
+				keys[k] = true
+			}
+		case map[string]string:
+			for k := range m {
+				keys[k] = true
+			}
+		}
+	}
+	return keys
+}
+
+func sameJSON(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
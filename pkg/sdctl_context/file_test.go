@@ -0,0 +1,202 @@
+package sdctl_context
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadConfig(t *testing.T) {
+	cases := map[string]struct {
+		file            string
+		content         string
+		expectedVersion int
+		expectedErr     string
+	}{
+		"unversioned JSON": {
+			"sdctl", `{"current_context":"default","contexts":{"default":{"token":"t","api":"https://api","jwt":"j"}}}`, 0, "",
+		},
+		"unversioned JSON without contexts": {
+			"sdctl", `{}`, 0, "",
+		},
+		"current JSON": {
+			"sdctl", `{"version":1,"current_context":"default","contexts":{"default":{"token":"t","api":"https://api","jwt":"j"}}}`, 1, "",
+		},
+		"YAML": {
+			"config.yaml", "version: 1\ncurrent_context: default\ncontexts:\n  default:\n    token: t\n    api: https://api\n    jwt: j\n", 1, "",
+		},
+		"newer version": {
+			"sdctl", `{"version":2}`, 0, "newer than 1",
+		},
+		"corrupt JSON": {
+			"sdctl", `{"current_context":`, 0, "is invalid",
+		},
+		"empty file": {
+			"config.yaml", "", 0, "is empty",
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), v.file)
+			ioutil.WriteFile(path, []byte(v.content), 0600)
+			config, version, err := ReadConfig(path)
+			if v.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), v.expectedErr) {
+					t.Errorf("error should contain '%v', but actual is '%v'", v.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != v.expectedVersion || config.Version != CurrentVersion || config.CurrentContext != "default" || config.SdctlContexts == nil {
+				t.Errorf("config should be migrated: %v, %+v", version, config)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMigratesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdctl")
+	ioutil.WriteFile(path, []byte(`{"current_context":"default","contexts":{"default":{"token":"t"}}}`), 0600)
+
+	if _, err := LoadConfig(path, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, version, err := ReadConfig(path); err != nil || version != CurrentVersion {
+		t.Errorf("migrated config should be written: %v, %v", version, err)
+	}
+}
+
+func TestMarshalConfigYAML(t *testing.T) {
+	config := createMockSdctlConfig()
+	config.SetRetry(RetryConfig{MaxAttempts: 5, MaxDelay: "20s"})
+	config.SetBannerExpiration(1, time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC))
+	config.Version = CurrentVersion
+
+	b, err := MarshalConfig(config, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "current_context: default\n") {
+		t.Errorf("YAML should have keys of JSON:\n%s", b)
+	}
+	actual, _, err := ParseConfig(b, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(config, actual); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpdateKeepsChangesOfOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := WriteConfig(path, createMockSdctlConfig()); err != nil {
+		t.Fatal(err)
+	}
+	mine, err := LoadConfig(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another sdctl changes the other context and adds one
+	theirs, _, _ := ReadConfig(path)
+	other := theirs.SdctlContexts[testContext]
+	other.SDJWT = "their_jwt"
+	theirs.SdctlContexts[testContext] = other
+	theirs.SdctlContexts[newContext] = emptySdctlContext()
+	theirs.SetAlias("deploy", "build org/app deploy")
+	b, _ := MarshalConfig(theirs, true)
+	ioutil.WriteFile(path, b, 0600)
+
+	mine.SetParam(UserTokenKey, newToken, ioutil.Discard)
+	if err := mine.Update(path); err != nil {
+		t.Fatal(err)
+	}
+	actual, _, err := ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.SdctlContexts["default"].UserToken != newToken {
+		t.Errorf("my change should be written: %+v", actual.SdctlContexts["default"])
+	}
+	if actual.SdctlContexts[testContext].SDJWT != "their_jwt" {
+		t.Errorf("their change should be kept: %+v", actual.SdctlContexts[testContext])
+	}
+	if _, ok := actual.SdctlContexts[newContext]; !ok {
+		t.Errorf("their context should be kept: %+v", actual.SdctlContexts)
+	}
+	if actual.Aliases["deploy"] == "" {
+		t.Errorf("their alias should be kept: %+v", actual.Aliases)
+	}
+	if _, ok := mine.SdctlContexts[newContext]; !ok {
+		t.Errorf("merged config should be reflected: %+v", mine.SdctlContexts)
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	defer func(timeout, age time.Duration) {
+		lockTimeout, staleLockAge = timeout, age
+	}(lockTimeout, staleLockAge)
+	lockTimeout, staleLockAge = 100*time.Millisecond, time.Hour
+	path := filepath.Join(t.TempDir(), "sdctl")
+
+	unlock, err := lockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockFile(path); err == nil {
+		t.Errorf("lock should be exclusive")
+	}
+	unlock()
+	unlock, err = lockFile(path)
+	if err != nil {
+		t.Fatalf("lock should be released: %v", err)
+	}
+	defer unlock()
+
+	staleLockAge = 0
+	if _, err := lockFile(path); err != nil {
+		t.Errorf("stale lock should be taken over: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("config file should not be created by the lock")
+	}
+}
+
+func TestSdctlConfig_Validate(t *testing.T) {
+	config := createMockSdctlConfig()
+	config.SdctlContexts["default"] = SdctlContext{APIURL: "https://api.screwdriver.cd"}
+	config.SdctlContexts[testContext] = SdctlContext{
+		APIURL:  "api.screwdriver.cd",
+		Retry:   &RetryConfig{MaxDelay: "10"},
+		Network: &NetworkConfig{Proxy: "proxy:8080", ClientCertFile: filepath.Join(t.TempDir(), "none.pem")},
+	}
+	if errs := config.Validate(); len(errs) != 5 {
+		t.Errorf("5 problems of %s should be found: %v", testContext, errs)
+	}
+
+	delete(config.SdctlContexts, testContext)
+	config.SdctlContexts["default"] = SdctlContext{UIURL: "cd.screwdriver.cd", Defaults: &DefaultsConfig{Output: "yaml"}}
+	config.Aliases = map[string]string{"deploy": `build "org/app`, "empty": " "}
+	if errs := config.Validate(); len(errs) != 4 {
+		t.Errorf("4 problems of aliases and defaults should be found: %v", errs)
+	}
+
+	config.CurrentContext = newContext
+	config.SdctlContexts["default"] = SdctlContext{}
+	config.Aliases = nil
+	errs := config.Validate()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "current context") {
+		t.Errorf("missing current context should be found: %v", errs)
+	}
+}
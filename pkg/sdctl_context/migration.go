@@ -0,0 +1,60 @@
+package sdctl_context
+
+import (
+	"fmt"
+)
+
+// VersionKey is the key of the version of the config file
+const VersionKey = "version"
+
+// CurrentVersion is the version of config files written by this sdctl
+const CurrentVersion = 1
+
+// migrations[i] migrates a raw config of version i to i+1.
+// append a migration and increment CurrentVersion to change the schema.
+var migrations = []func(raw map[string]interface{}) error{
+	// 0 is the unversioned config before versioning
+	func(raw map[string]interface{}) error {
+		if _, ok := raw[ContextsKey]; !ok {
+			raw[ContextsKey] = map[string]interface{}{}
+		}
+		if current, _ := raw[CurrentContextKey].(string); current == "" {
+			raw[CurrentContextKey] = "default"
+		}
+		return nil
+	},
+}
+
+// migrate migrates a raw config to CurrentVersion and returns the version before the migration
+func migrate(raw map[string]interface{}) (int, error) {
+	version, err := rawVersion(raw)
+	if err != nil {
+		return 0, err
+	}
+	if version > CurrentVersion {
+		return version, fmt.Errorf("version %d is newer than %d supported by this sdctl, upgrade sdctl", version, CurrentVersion)
+	}
+	for v := version; v < CurrentVersion; v++ {
+		if err := migrations[v](raw); err != nil {
+			return version, fmt.Errorf("failed to migrate from version %d to %d: %v", v, v+1, err)
+		}
+		raw[VersionKey] = v + 1
+	}
+	return version, nil
+}
+
+func rawVersion(raw map[string]interface{}) (int, error) {
+	switch v := raw[VersionKey].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		if v >= 0 {
+			return v, nil
+		}
+	case float64:
+		if v >= 0 && v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid version %v", raw[VersionKey])
+}
@@ -0,0 +1,63 @@
+package sdctl_context
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// BannerScheduleFile is a file of banner windows shared by "banner announce" and "banner sync",
+// so that sync runs wherever the file is, such as in a periodic Screwdriver.cd job of the repository with the file
+type BannerScheduleFile struct {
+	// API is the API URL of the banners, so that the IDs are not used against another Screwdriver.cd
+	API       string                 `json:"api"`
+	Schedules map[int]BannerSchedule `json:"schedules"`
+}
+
+// CheckAPI returns an error when the file is for banners of an API other than apiURL
+func (f BannerScheduleFile) CheckAPI(apiURL string) error {
+	if f.API != "" && strings.TrimSuffix(f.API, "/") != strings.TrimSuffix(apiURL, "/") {
+		return fmt.Errorf("banner schedules are for %s, not %s", f.API, apiURL)
+	}
+	return nil
+}
+
+// ReadBannerSchedules reads the schedule file at path. a missing file has no schedules.
+func ReadBannerSchedules(path string) (BannerScheduleFile, error) {
+	var f BannerScheduleFile
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return f, fmt.Errorf("banner schedule file %s is invalid: %v", path, err)
+	}
+	return f, nil
+}
+
+// UpdateBannerSchedules applies update to the schedule file at path while holding the lock of path, and writes it atomically
+func UpdateBannerSchedules(path string, update func(f *BannerScheduleFile) error) error {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := ReadBannerSchedules(path)
+	if err != nil {
+		return err
+	}
+	if err := update(&f); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(b, '\n'))
+}
@@ -0,0 +1,60 @@
+package sdctl_context
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBannerSchedules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "banners.json")
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := BannerSchedule{Start: start, End: start.Add(time.Hour)}
+
+	f, err := ReadBannerSchedules(path)
+	if err != nil || len(f.Schedules) != 0 {
+		t.Fatalf("missing file should have no schedules: %+v, %v", f, err)
+	}
+
+	err = UpdateBannerSchedules(path, func(f *BannerScheduleFile) error {
+		f.API = "https://api.screwdriver.cd"
+		f.Schedules = map[int]BannerSchedule{1: schedule, 2: schedule}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = UpdateBannerSchedules(path, func(f *BannerScheduleFile) error {
+		delete(f.Schedules, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err = ReadBannerSchedules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := BannerScheduleFile{API: "https://api.screwdriver.cd", Schedules: map[int]BannerSchedule{2: schedule}}
+	if diff := cmp.Diff(expected, f); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := f.CheckAPI("https://api.screwdriver.cd/"); err != nil {
+		t.Errorf("same API should be accepted: %v", err)
+	}
+	if err := f.CheckAPI("https://api.example.com"); err == nil {
+		t.Errorf("another API should be rejected")
+	}
+
+	if err := ioutil.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBannerSchedules(path); err == nil {
+		t.Errorf("invalid file should cause an error")
+	}
+}
@@ -0,0 +1,94 @@
+package sdctl_context
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Validate returns problems of the config, such as invalid URLs and missing files, in the order of aliases and contexts
+func (sc SdctlConfig) Validate() []error {
+	var errs []error
+	if _, ok := sc.SdctlContexts[sc.CurrentContext]; !ok {
+		errs = append(errs, fmt.Errorf("current context %q does not exist", sc.CurrentContext))
+	}
+
+	var aliases []string
+	for name := range sc.Aliases {
+		aliases = append(aliases, name)
+	}
+	sort.Strings(aliases)
+	for _, name := range aliases {
+		if words, err := SplitCommandLine(sc.Aliases[name]); err != nil {
+			errs = append(errs, fmt.Errorf("alias %q is invalid: %v", name, err))
+		} else if len(words) == 0 {
+			errs = append(errs, fmt.Errorf("alias %q is empty", name))
+		}
+	}
+
+	var names []string
+	for name := range sc.SdctlContexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, err := range sc.SdctlContexts[name].validate() {
+			errs = append(errs, fmt.Errorf("context %q: %v", name, err))
+		}
+	}
+	return errs
+}
+
+func (sdctx SdctlContext) validate() []error {
+	var errs []error
+	if sdctx.APIURL != "" {
+		if u, err := url.Parse(sdctx.APIURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("api %q should be a URL such as https://api.screwdriver.cd", sdctx.APIURL))
+		}
+	}
+	if sdctx.UIURL != "" {
+		if u, err := url.Parse(sdctx.UIURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("ui %q should be a URL such as https://cd.screwdriver.cd", sdctx.UIURL))
+		}
+	}
+	if d := sdctx.Defaults; d != nil && d.Output != "" && !contains(OutputFormats, d.Output) {
+		errs = append(errs, fmt.Errorf("defaults.output should be one of %s: %s", strings.Join(OutputFormats, ", "), d.Output))
+	}
+	if sdctx.Retry != nil {
+		if sdctx.Retry.MaxAttempts < 0 {
+			errs = append(errs, fmt.Errorf("retry.max_attempts should not be negative: %d", sdctx.Retry.MaxAttempts))
+		}
+		if sdctx.Retry.MaxDelay != "" {
+			if _, err := time.ParseDuration(sdctx.Retry.MaxDelay); err != nil {
+				errs = append(errs, fmt.Errorf("retry.max_delay is invalid: %v", err))
+			}
+		}
+	}
+	if n := sdctx.Network; n != nil {
+		if n.Proxy != "" {
+			if u, err := url.Parse(n.Proxy); err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("network.proxy %q should be a URL such as http://proxy:8080", n.Proxy))
+			}
+		}
+		if (n.ClientCertFile == "") != (n.ClientKeyFile == "") {
+			errs = append(errs, errors.New("network.client_cert_file and network.client_key_file should be set together"))
+		}
+		for _, f := range [][2]string{
+			{"network.ca_file", n.CAFile},
+			{"network.client_cert_file", n.ClientCertFile},
+			{"network.client_key_file", n.ClientKeyFile},
+		} {
+			if f[1] == "" {
+				continue
+			}
+			if _, err := os.Stat(f[1]); err != nil {
+				errs = append(errs, fmt.Errorf("%s is not readable: %v", f[0], err))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,294 @@
+package top
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"golang.org/x/term"
+)
+
+// Options configures the dashboard
+type Options struct {
+	// Context cancels requests in flight when the dashboard quits
+	Context     context.Context
+	API         sdapi.Interface
+	Pipelines   []sdapi.Pipeline
+	Interval    time.Duration
+	Concurrency int
+	// Open opens a URL in a browser
+	Open func(url string) error
+	In   *os.File
+	Out  *os.File
+}
+
+// update changes the model in the main loop
+type update func(m *Model)
+
+// Run shows the dashboard until q or Ctrl-C is pressed
+func Run(o Options) error {
+	if o.Context == nil {
+		o.Context = context.Background()
+	}
+	ctx, cancel := context.WithCancel(o.Context)
+	defer cancel()
+	if o.In == nil {
+		o.In = os.Stdin
+	}
+	if o.Out == nil {
+		o.Out = os.Stdout
+	}
+	if !term.IsTerminal(int(o.In.Fd())) || !term.IsTerminal(int(o.Out.Fd())) {
+		return errors.New("top requires a terminal")
+	}
+	state, err := term.MakeRaw(int(o.In.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(o.In.Fd()), state)
+	// use the alternate screen and hide the cursor
+	fmt.Fprint(o.Out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(o.Out, "\x1b[?25h\x1b[?1049l")
+
+	// closing the reader stops reading keys on quit
+	in, err := newKeyReader(o.In)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	keys := make(chan string)
+	go readKeys(ctx, in, keys)
+	updates := make(chan update)
+	ticker := time.NewTicker(o.Interval)
+	defer ticker.Stop()
+
+	m := &Model{Interval: o.Interval, Message: "loading..."}
+	refreshing := false
+	refresh := func() {
+		if refreshing {
+			return
+		}
+		refreshing = true
+		go func() {
+			rows := Fetch(ctx, o.API, o.Pipelines, o.Concurrency)
+			sendUpdate(ctx, updates, func(m *Model) {
+				refreshing = false
+				m.SetRows(rows)
+				m.Updated = time.Now()
+				if m.Message == "loading..." {
+					m.Message = ""
+				}
+			})
+		}()
+	}
+	// run runs an action in background and shows the result in the footer
+	run := func(action func() string) {
+		go func() {
+			msg := action()
+			sendUpdate(ctx, updates, func(m *Model) { m.Message = msg })
+		}()
+	}
+
+	refresh()
+	for {
+		width, height, err := term.GetSize(int(o.Out.Fd()))
+		if err != nil {
+			width, height = 80, 24
+		}
+		m.Render(o.Out, width, height, time.Now())
+
+		select {
+		case <-ticker.C:
+			refresh()
+		case u := <-updates:
+			u(m)
+		case key, ok := <-keys:
+			if !ok || key == "ctrl+c" {
+				return nil
+			}
+			if m.LogTitle != "" {
+				m.LogTitle, m.Logs = "", nil
+				continue
+			}
+			if m.Confirm != nil {
+				if key == "y" {
+					m.Message = m.Confirm.Prompt + " ..."
+					run(m.Confirm.Action)
+				} else {
+					m.Message = ""
+				}
+				m.Confirm = nil
+				continue
+			}
+			m.Message = ""
+			switch key {
+			case "q":
+				return nil
+			case "j", "down":
+				m.Move(1)
+			case "k", "up":
+				m.Move(-1)
+			case "R":
+				refresh()
+			default:
+				o.handleAction(ctx, m, key, run, updates)
+			}
+		}
+	}
+}
+
+// handleAction handles keys on the selected row
+func (o Options) handleAction(ctx context.Context, m *Model, key string, run func(func() string), updates chan<- update) {
+	row, ok := m.Current()
+	if !ok || row.Err != nil {
+		return
+	}
+	api := o.API
+
+	switch key {
+	case "l":
+		if row.Build == nil {
+			m.Message = "no build to show logs"
+			return
+		}
+		m.Message = "loading logs..."
+		build := *row.Build
+		go func() {
+			title, logs, err := buildLogs(ctx, api, row.Pipeline, row.Job, build)
+			sendUpdate(ctx, updates, func(m *Model) {
+				if err != nil {
+					m.Message = err.Error()
+					return
+				}
+				m.Message = ""
+				m.LogTitle, m.Logs = title, logs
+			})
+		}()
+	case "r":
+		m.Confirm = &Confirmation{
+			Prompt: fmt.Sprintf("restart %s of %s?", row.Job.Name, row.Pipeline.Name),
+			Action: func() string {
+				if _, err := api.PostEvent(ctx, row.Pipeline.ID, row.Job.Name); err != nil {
+					return err.Error()
+				}
+				return fmt.Sprintf("restarted %s of %s", row.Job.Name, row.Pipeline.Name)
+			},
+		}
+	case "s":
+		if row.Build == nil || !running(row.Build.Status) {
+			m.Message = "no running build to stop"
+			return
+		}
+		buildID := row.Build.ID
+		m.Confirm = &Confirmation{
+			Prompt: fmt.Sprintf("stop build %d of %s?", buildID, row.Job.Name),
+			Action: func() string {
+				if err := api.StopBuild(ctx, buildID); err != nil {
+					return err.Error()
+				}
+				return fmt.Sprintf("stopped build %d", buildID)
+			},
+		}
+	case "o":
+		if row.Build == nil {
+			m.Message = "no build to open"
+			return
+		}
+		url := api.BuildPageURL(row.Pipeline.ID, row.Build.ID)
+		if o.Open == nil {
+			m.Message = url
+			return
+		}
+		run(func() string {
+			if err := o.Open(url); err != nil {
+				return err.Error()
+			}
+			return "opened " + url
+		})
+	}
+}
+
+func running(status string) bool {
+	switch status {
+	case sdapi.StatusRunning, sdapi.StatusQueued, sdapi.StatusBlocked, sdapi.StatusCreated:
+		return true
+	}
+	return false
+}
+
+// buildLogs returns the title and the last lines of logs of a step picked by pickStep
+func buildLogs(ctx context.Context, api sdapi.Interface, pipeline sdapi.Pipeline, job sdapi.Job, build sdapi.Build) (string, []string, error) {
+	steps, err := api.GetBuildSteps(ctx, build.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	step, ok := pickStep(steps)
+	if !ok {
+		return "", nil, fmt.Errorf("build %d has no started steps", build.ID)
+	}
+	lines, err := api.GetStepLogs(ctx, build.ID, step, true)
+	if err != nil {
+		return "", nil, err
+	}
+	logs := make([]string, len(lines))
+	for i, l := range lines {
+		logs[i] = l.Message
+	}
+	title := fmt.Sprintf("%s %s build %d (%s) step %s", pipeline.Name, job.Name, build.ID, build.Status, step)
+	return title, logs, nil
+}
+
+// sendUpdate sends u to the main loop unless the dashboard has quit
+func sendUpdate(ctx context.Context, updates chan<- update, u update) {
+	select {
+	case updates <- u:
+	case <-ctx.Done():
+	}
+}
+
+// readKeys sends pressed keys until r is closed or ctx is done
+func readKeys(ctx context.Context, r io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			select {
+			case keys <- k:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// parseKeys converts input in raw mode to key names
+func parseKeys(b []byte) []string {
+	var keys []string
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == 3:
+			keys = append(keys, "ctrl+c")
+		case b[i] == 0x1b && i+2 < len(b) && b[i+1] == '[':
+			switch b[i+2] {
+			case 'A':
+				keys = append(keys, "up")
+			case 'B':
+				keys = append(keys, "down")
+			}
+			i += 2
+		case b[i] == 0x1b:
+			keys = append(keys, "esc")
+		default:
+			keys = append(keys, string(b[i]))
+		}
+	}
+	return keys
+}
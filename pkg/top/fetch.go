@@ -0,0 +1,85 @@
+package top
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+// Row is a job of a pipeline with its build in the last event of the pipeline
+type Row struct {
+	Pipeline sdapi.Pipeline
+	Job      sdapi.Job
+	// Build is nil when the job did not run in the last event
+	Build *sdapi.Build
+	// Err is set instead of Job when failed to get the pipeline status
+	Err error
+}
+
+// Fetch gets jobs of pipelines and their builds in the last events, polling at most concurrency pipelines at once.
+// rows are in the order of pipelines.
+func Fetch(ctx context.Context, api sdapi.Interface, pipelines []sdapi.Pipeline, concurrency int) []Row {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([][]Row, len(pipelines))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = fetchPipeline(ctx, api, pipelines[i])
+			}
+		}()
+	}
+	for i := range pipelines {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var rows []Row
+	for _, r := range results {
+		rows = append(rows, r...)
+	}
+	return rows
+}
+
+func fetchPipeline(ctx context.Context, api sdapi.Interface, pipeline sdapi.Pipeline) []Row {
+	jobs, err := api.GetPipelineJobs(ctx, pipeline.ID)
+	if err != nil {
+		return []Row{{Pipeline: pipeline, Err: err}}
+	}
+	builds := make(map[int]sdapi.Build)
+	events, err := api.GetPipelineEvents(ctx, pipeline.ID, 1)
+	if err != nil {
+		return []Row{{Pipeline: pipeline, Err: err}}
+	}
+	if len(events) > 0 {
+		bs, err := api.GetEventBuilds(ctx, events[0].ID)
+		if err != nil {
+			return []Row{{Pipeline: pipeline, Err: err}}
+		}
+		for _, b := range bs {
+			builds[b.JobID] = b
+		}
+	}
+
+	var rows []Row
+	for _, j := range jobs {
+		// jobs of pull requests come and go, the dashboard shows jobs of the pipeline only
+		if strings.HasPrefix(j.Name, "PR-") {
+			continue
+		}
+		r := Row{Pipeline: pipeline, Job: j}
+		if b, ok := builds[j.ID]; ok {
+			r.Build = &b
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
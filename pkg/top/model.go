@@ -0,0 +1,180 @@
+package top
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+const help = "j/k:move  l:logs  r:restart  s:stop  o:open  R:refresh  q:quit"
+
+// Model is the state of the dashboard
+type Model struct {
+	Rows     []Row
+	Selected int
+	Updated  time.Time
+	Interval time.Duration
+	// Message is shown in the footer instead of the help
+	Message string
+	// Confirm is asked in the footer and run when y is pressed
+	Confirm *Confirmation
+	// Logs are shown instead of rows when LogTitle is set
+	LogTitle string
+	Logs     []string
+}
+
+// Confirmation is an action waiting for the answer of the user
+type Confirmation struct {
+	Prompt string
+	Action func() string
+}
+
+// SetRows replaces rows keeping the selected job
+func (m *Model) SetRows(rows []Row) {
+	var selected Row
+	if m.Selected < len(m.Rows) {
+		selected = m.Rows[m.Selected]
+	}
+	m.Rows = rows
+	m.Selected = 0
+	for i, r := range rows {
+		if r.Pipeline.ID == selected.Pipeline.ID && r.Job.ID == selected.Job.ID {
+			m.Selected = i
+			break
+		}
+	}
+}
+
+// Move moves the cursor by delta rows within the rows
+func (m *Model) Move(delta int) {
+	m.Selected += delta
+	if m.Selected >= len(m.Rows) {
+		m.Selected = len(m.Rows) - 1
+	}
+	if m.Selected < 0 {
+		m.Selected = 0
+	}
+}
+
+// Current returns the selected row
+func (m *Model) Current() (Row, bool) {
+	if m.Selected < 0 || m.Selected >= len(m.Rows) {
+		return Row{}, false
+	}
+	return m.Rows[m.Selected], true
+}
+
+// Render writes the screen of width x height to w. lines end with \r\n for terminals in raw mode.
+func (m *Model) Render(w io.Writer, width, height int, now time.Time) {
+	var lines []string
+	if m.LogTitle != "" {
+		lines = append(lines, "\x1b[1m"+fit(m.LogTitle, width)+"\x1b[0m")
+		logs := m.Logs
+		if max := height - 2; len(logs) > max && max >= 0 {
+			logs = logs[len(logs)-max:]
+		}
+		for _, l := range logs {
+			lines = append(lines, fit(l, width))
+		}
+		lines = pad(lines, height-1)
+		lines = append(lines, fit("press any key to go back", width))
+	} else {
+		header := fmt.Sprintf("sdctl top - %d jobs - every %v", len(m.Rows), m.Interval)
+		if !m.Updated.IsZero() {
+			header += " - updated " + m.Updated.Format("15:04:05")
+		}
+		lines = append(lines, "\x1b[1m"+fit(header, width)+"\x1b[0m")
+		lines = append(lines, fit(column("Pipeline", "Job", "Build", "Status", "Duration"), width))
+
+		// scroll to show the selected row
+		max := height - 3
+		if max < 1 {
+			max = 1
+		}
+		offset := 0
+		if m.Selected >= max {
+			offset = m.Selected - max + 1
+		}
+		for i := offset; i < len(m.Rows) && i < offset+max; i++ {
+			line := fit(m.Rows[i].columns(now), width)
+			if i == m.Selected {
+				line = "\x1b[7m" + line + "\x1b[0m"
+			}
+			lines = append(lines, line)
+		}
+		lines = pad(lines, height-1)
+
+		footer := help
+		switch {
+		case m.Confirm != nil:
+			footer = m.Confirm.Prompt + " (y/n)"
+		case m.Message != "":
+			footer = m.Message
+		}
+		lines = append(lines, fit(footer, width))
+	}
+
+	fmt.Fprint(w, "\x1b[H\x1b[2J"+strings.Join(lines, "\r\n"))
+}
+
+func (r Row) columns(now time.Time) string {
+	name := r.Pipeline.Name
+	if r.Err != nil {
+		return column(name, "-", "-", sdapi.StatusUnknown, r.Err)
+	}
+	if r.Build == nil {
+		return column(name, r.Job.Name, "-", "-", "-")
+	}
+	return column(name, r.Job.Name, r.Build.ID, r.Build.Status, duration(r.Build, now))
+}
+
+func column(pipeline, job, build, status, duration interface{}) string {
+	return fmt.Sprintf("%-32v%-24v%-12v%-12v%v", pipeline, job, build, status, duration)
+}
+
+// duration returns the elapsed time of a build, until now if it is running
+func duration(b *sdapi.Build, now time.Time) string {
+	start, err := time.Parse(time.RFC3339, b.StartTime)
+	if err != nil {
+		return "-"
+	}
+	end, err := time.Parse(time.RFC3339, b.EndTime)
+	if err != nil {
+		end = now
+	}
+	return end.Sub(start).Truncate(time.Second).String()
+}
+
+// fit truncates s to width runes
+func fit(s string, width int) string {
+	r := []rune(s)
+	if width > 0 && len(r) > width {
+		return string(r[:width])
+	}
+	return s
+}
+
+func pad(lines []string, n int) []string {
+	for len(lines) < n {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// pickStep returns the step to show logs of: the failed step, the running step or the last started step
+func pickStep(steps []sdapi.Step) (string, bool) {
+	last := ""
+	for _, s := range steps {
+		if s.StartTime == "" {
+			continue
+		}
+		if s.Code == nil || *s.Code != 0 {
+			return s.Name, true
+		}
+		last = s.Name
+	}
+	return last, last != ""
+}
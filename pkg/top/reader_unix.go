@@ -0,0 +1,37 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris zos
+
+package top
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// keyReader reads a duplicate of the terminal in non-blocking mode, so that Close stops a Read in progress
+type keyReader struct {
+	*os.File
+	origFd int
+}
+
+// newKeyReader returns a reader of f which Close stops, also while a Read is waiting for keys
+func newKeyReader(f *os.File) (io.ReadCloser, error) {
+	origFd := int(f.Fd())
+	fd, err := syscall.Dup(origFd)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return &keyReader{File: os.NewFile(uintptr(fd), f.Name()), origFd: origFd}, nil
+}
+
+func (r *keyReader) Close() error {
+	err := r.File.Close()
+	// the mode is shared with f, which the shell reads after sdctl exits
+	syscall.SetNonblock(r.origFd, false)
+	return err
+}
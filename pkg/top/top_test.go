@@ -0,0 +1,202 @@
+package top
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+	"github.com/tk3fftk/sdctl/pkg/sdctl_context"
+)
+
+func TestFetch(t *testing.T) {
+	muxAPI := http.NewServeMux()
+	testAPIServer := httptest.NewServer(muxAPI)
+	defer testAPIServer.Close()
+
+	muxAPI.HandleFunc("/v4/pipelines/1/jobs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":11,"pipelineId":1,"name":"main"},{"id":12,"pipelineId":1,"name":"publish"},{"id":13,"pipelineId":1,"name":"PR-1:main"}]`)
+	})
+	muxAPI.HandleFunc("/v4/pipelines/1/events", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":100,"pipelineId":1}]`)
+	})
+	muxAPI.HandleFunc("/v4/events/100/builds", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":1000,"jobId":11,"eventId":100,"status":"RUNNING"}]`)
+	})
+	muxAPI.HandleFunc("/v4/pipelines/2/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	api, err := sdapi.New(sdctl_context.SdctlContext{APIURL: testAPIServer.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipelines := []sdapi.Pipeline{{ID: 1, Name: "org/a"}, {ID: 2, Name: "org/b"}}
+	rows := Fetch(context.Background(), api, pipelines, 2)
+
+	if len(rows) != 3 {
+		t.Fatalf("rows should be 3, but actual is %d: %#v", len(rows), rows)
+	}
+	expected := []Row{
+		{Pipeline: pipelines[0], Job: sdapi.Job{ID: 11, PipelineID: 1, Name: "main"}, Build: &sdapi.Build{ID: 1000, JobID: 11, EventID: 100, Status: sdapi.StatusRunning}},
+		{Pipeline: pipelines[0], Job: sdapi.Job{ID: 12, PipelineID: 1, Name: "publish"}},
+	}
+	if diff := cmp.Diff(expected, rows[:2]); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if rows[2].Pipeline.ID != 2 || rows[2].Err == nil {
+		t.Errorf("the last row should be an error of pipeline 2: %#v", rows[2])
+	}
+}
+
+func TestModel(t *testing.T) {
+	rows := []Row{
+		{Pipeline: sdapi.Pipeline{ID: 1}, Job: sdapi.Job{ID: 11}},
+		{Pipeline: sdapi.Pipeline{ID: 1}, Job: sdapi.Job{ID: 12}},
+		{Pipeline: sdapi.Pipeline{ID: 2}, Job: sdapi.Job{ID: 21}},
+	}
+	m := &Model{}
+	m.SetRows(rows)
+	m.Move(-1)
+	if m.Selected != 0 {
+		t.Errorf("selected should stay 0: %d", m.Selected)
+	}
+	m.Move(5)
+	if m.Selected != 2 {
+		t.Errorf("selected should stop at the last row: %d", m.Selected)
+	}
+
+	// the selected job is kept after refreshing
+	m.SetRows([]Row{rows[2], rows[0]})
+	if r, _ := m.Current(); r.Job.ID != 21 || m.Selected != 0 {
+		t.Errorf("job 21 should be selected: %d", m.Selected)
+	}
+}
+
+func TestRender(t *testing.T) {
+	now := time.Date(2021, 7, 18, 10, 0, 0, 0, time.UTC)
+	m := &Model{
+		Interval: 10 * time.Second,
+		Rows: []Row{
+			{Pipeline: sdapi.Pipeline{Name: "org/a"}, Job: sdapi.Job{Name: "main"}, Build: &sdapi.Build{ID: 1000, Status: sdapi.StatusRunning, StartTime: "2021-07-18T09:58:30Z"}},
+			{Pipeline: sdapi.Pipeline{Name: "org/a"}, Job: sdapi.Job{Name: "publish"}},
+			{Pipeline: sdapi.Pipeline{Name: "org/b"}, Err: fmt.Errorf("forbidden")},
+		},
+		Selected: 1,
+	}
+
+	var b bytes.Buffer
+	m.Render(&b, 120, 10, now)
+	out := b.String()
+	for _, s := range []string{"3 jobs", "1000", "RUNNING", "1m30s", "\x1b[7morg/a", "forbidden", help} {
+		if !strings.Contains(out, s) {
+			t.Errorf("screen should contain %q: %q", s, out)
+		}
+	}
+	if lines := strings.Count(out, "\r\n") + 1; lines != 10 {
+		t.Errorf("screen should have 10 lines, but actual is %d", lines)
+	}
+
+	m.Confirm = &Confirmation{Prompt: "restart main of org/a?"}
+	b.Reset()
+	m.Render(&b, 120, 10, now)
+	if !strings.Contains(b.String(), "restart main of org/a? (y/n)") {
+		t.Errorf("screen should ask confirmation: %q", b.String())
+	}
+
+	m.LogTitle = "org/a main build 1000"
+	m.Logs = []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}
+	b.Reset()
+	m.Render(&b, 120, 5, now)
+	if out := b.String(); !strings.Contains(out, "8\r\n9\r\n10") || strings.Contains(out, "\r\n7\r\n") {
+		t.Errorf("screen should show the last lines of logs: %q", out)
+	}
+}
+
+func TestPickStep(t *testing.T) {
+	zero, one := 0, 1
+	cases := map[string]struct {
+		steps    []sdapi.Step
+		expected string
+	}{
+		"failed step":  {[]sdapi.Step{{Name: "install", Code: &zero, StartTime: "t"}, {Name: "test", Code: &one, StartTime: "t"}, {Name: "teardown", Code: &zero, StartTime: "t"}}, "test"},
+		"running step": {[]sdapi.Step{{Name: "install", Code: &zero, StartTime: "t"}, {Name: "test", StartTime: "t"}, {Name: "publish"}}, "test"},
+		"succeeded":    {[]sdapi.Step{{Name: "install", Code: &zero, StartTime: "t"}, {Name: "test", Code: &zero, StartTime: "t"}}, "test"},
+		"not started":  {[]sdapi.Step{{Name: "install"}}, ""},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			actual, ok := pickStep(v.steps)
+			if actual != v.expected || ok != (v.expected != "") {
+				t.Errorf("step should be %q, but actual is %q", v.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	actual := parseKeys([]byte("jk\x1b[A\x1b[Bq\x03\x1b"))
+	expected := []string{"j", "k", "up", "down", "q", "ctrl+c", "esc"}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadKeysStops(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	in, err := newKeyReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keys := make(chan string)
+	go readKeys(ctx, in, keys)
+
+	w.Write([]byte("j"))
+	if k := <-keys; k != "j" {
+		t.Errorf("key should be j, but %s", k)
+	}
+	// nothing is typed after quitting
+	in.Close()
+	select {
+	case _, ok := <-keys:
+		if ok {
+			t.Error("no keys should be sent after closing")
+		}
+	case <-time.After(time.Second):
+		t.Error("reading keys should stop on close")
+	}
+}
+
+func TestReadKeysCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	keys := make(chan string)
+	done := make(chan struct{})
+	go func() {
+		readKeys(ctx, strings.NewReader("jjj"), keys)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("reading keys should stop when canceled without a receiver")
+	}
+}
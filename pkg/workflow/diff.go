@@ -0,0 +1,219 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+// status of a job in a diff
+const (
+	JobAdded   = "added"
+	JobRemoved = "removed"
+	JobChanged = "changed"
+)
+
+// JobDiff represents changes of a job between two expanded pipelines
+type JobDiff struct {
+	Name    string
+	Status  string
+	Changes []string
+}
+
+// Diff compares expanded jobs and requires edges of two validator responses
+func Diff(base, head *sdapi.ValidatorResponse) []JobDiff {
+	baseGraph := New(base.WorkflowGraph)
+	headGraph := New(head.WorkflowGraph)
+
+	var diffs []JobDiff
+	for _, name := range sortedKeys(base.Jobs, head.Jobs) {
+		b, inBase := base.Jobs[name]
+		h, inHead := head.Jobs[name]
+		switch {
+		case !inHead:
+			diffs = append(diffs, JobDiff{Name: name, Status: JobRemoved})
+		case !inBase:
+			diffs = append(diffs, JobDiff{Name: name, Status: JobAdded})
+		default:
+			var changes []string
+			changes = append(changes, diffList("requires", baseGraph.Parents(name), headGraph.Parents(name))...)
+			changes = append(changes, diffJobConfigs(b, h)...)
+			if len(changes) > 0 {
+				diffs = append(diffs, JobDiff{Name: name, Status: JobChanged, Changes: changes})
+			}
+		}
+	}
+	return diffs
+}
+
+// PrintDiff writes diffs in a human readable format
+func PrintDiff(w io.Writer, diffs []JobDiff) {
+	if len(diffs) == 0 {
+		fmt.Fprintln(w, "no differences in the effective configuration")
+		return
+	}
+	for _, d := range diffs {
+		switch d.Status {
+		case JobAdded:
+			fmt.Fprintf(w, "+ job %s\n", d.Name)
+		case JobRemoved:
+			fmt.Fprintf(w, "- job %s\n", d.Name)
+		default:
+			fmt.Fprintf(w, "~ job %s\n", d.Name)
+		}
+		for _, c := range d.Changes {
+			fmt.Fprintf(w, "    %s\n", c)
+		}
+	}
+}
+
+func diffJobConfigs(base, head []sdapi.JobConfig) []string {
+	if len(base) != len(head) {
+		return []string{fmt.Sprintf("configs: %d -> %d", len(base), len(head))}
+	}
+
+	var changes []string
+	for i := range base {
+		prefix := ""
+		// jobs with matrix have a config per permutation
+		if len(base) > 1 {
+			prefix = fmt.Sprintf("[%d] ", i)
+		}
+		for _, c := range diffJobConfig(base[i], head[i]) {
+			changes = append(changes, prefix+c)
+		}
+	}
+	return changes
+}
+
+func diffJobConfig(base, head sdapi.JobConfig) []string {
+	var changes []string
+	if base.Image != head.Image {
+		changes = append(changes, fmt.Sprintf("image: %s -> %s", base.Image, head.Image))
+	}
+	changes = append(changes, diffSteps(base.Commands, head.Commands)...)
+	changes = append(changes, diffMap("environment", toInterfaceMap(base.Environment), toInterfaceMap(head.Environment))...)
+	changes = append(changes, diffList("secrets", base.Secrets, head.Secrets)...)
+	changes = append(changes, diffMap("annotations", base.Annotations, head.Annotations)...)
+	changes = append(changes, diffMap("settings", base.Settings, head.Settings)...)
+	return changes
+}
+
+func diffSteps(base, head []sdapi.JobCommand) []string {
+	baseSteps := make(map[string]string)
+	var baseNames, headNames []string
+	for _, c := range base {
+		baseSteps[c.Name] = c.Command
+		baseNames = append(baseNames, c.Name)
+	}
+	headSteps := make(map[string]string)
+	for _, c := range head {
+		headSteps[c.Name] = c.Command
+		headNames = append(headNames, c.Name)
+	}
+
+	var changes []string
+	for _, c := range base {
+		if _, ok := headSteps[c.Name]; !ok {
+			changes = append(changes, fmt.Sprintf("steps: -%s", c.Name))
+		}
+	}
+	for _, c := range head {
+		command, ok := baseSteps[c.Name]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("steps: +%s: %s", c.Name, oneLine(c.Command)))
+		case command != c.Command:
+			changes = append(changes, fmt.Sprintf("steps: %s: %s -> %s", c.Name, oneLine(command), oneLine(c.Command)))
+		}
+	}
+	if len(changes) == 0 && strings.Join(baseNames, ",") != strings.Join(headNames, ",") {
+		changes = append(changes, fmt.Sprintf("steps order: %s -> %s", strings.Join(baseNames, ", "), strings.Join(headNames, ", ")))
+	}
+	return changes
+}
+
+func diffList(field string, base, head []string) []string {
+	baseSet := make(map[string]bool)
+	for _, v := range base {
+		baseSet[v] = true
+	}
+	headSet := make(map[string]bool)
+	for _, v := range head {
+		headSet[v] = true
+	}
+
+	var added, removed []string
+	for _, v := range head {
+		if !baseSet[v] {
+			added = append(added, "+"+v)
+		}
+	}
+	for _, v := range base {
+		if !headSet[v] {
+			removed = append(removed, "-"+v)
+		}
+	}
+	if len(added)+len(removed) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: %s", field, strings.Join(append(added, removed...), " "))}
+}
+
+func diffMap(field string, base, head map[string]interface{}) []string {
+	var changes []string
+	for _, k := range sortedKeys(base, head) {
+		b, inBase := base[k]
+		h, inHead := head[k]
+		switch {
+		case !inHead:
+			changes = append(changes, fmt.Sprintf("%s: -%s", field, k))
+		case !inBase:
+			changes = append(changes, fmt.Sprintf("%s: +%s=%v", field, k, h))
+		case fmt.Sprintf("%v", b) != fmt.Sprintf("%v", h):
+			changes = append(changes, fmt.Sprintf("%s: %s: %v -> %v", field, k, b, h))
+		}
+	}
+	return changes
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	r := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		r[k] = v
+	}
+	return r
+}
+
+func oneLine(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "; ")
+}
+
+// sortedKeys returns keys of maps in order
+func sortedKeys(maps ...interface{}) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(k string) {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for _, m := range maps {
+		switch m := m.(type) {
+		case map[string][]sdapi.JobConfig:
+			for k := range m {
+				add(k)
+			}
+		case map[string]interface{}:
+			for k := range m {
+				add(k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package workflow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+func TestDiff(t *testing.T) {
+	base := &sdapi.ValidatorResponse{
+		Jobs: map[string][]sdapi.JobConfig{
+			"main": {{
+				Image:       "node:10",
+				Commands:    []sdapi.JobCommand{{Name: "install", Command: "npm ci"}, {Name: "test", Command: "npm test"}},
+				Environment: map[string]string{"FOO": "foo", "BAR": "bar"},
+				Secrets:     []string{"NPM_TOKEN"},
+			}},
+			"old":  {{Image: "node:10"}},
+			"same": {{Image: "node:10"}},
+		},
+		WorkflowGraph: sdapi.WorkflowGraph{
+			Edges: []sdapi.WorkflowEdge{{Src: "~pr", Dest: "main"}, {Src: "~commit", Dest: "main"}},
+		},
+	}
+	head := &sdapi.ValidatorResponse{
+		Jobs: map[string][]sdapi.JobConfig{
+			"main": {{
+				Image:       "node:12",
+				Commands:    []sdapi.JobCommand{{Name: "install", Command: "npm install"}, {Name: "lint", Command: "npm run lint"}},
+				Environment: map[string]string{"FOO": "foo2", "BAZ": "baz"},
+				Secrets:     []string{"NPM_TOKEN", "GITHUB_TOKEN"},
+				Annotations: map[string]interface{}{"screwdriver.cd/cpu": "HIGH"},
+			}},
+			"new":  {{Image: "node:12"}},
+			"same": {{Image: "node:10"}},
+		},
+		WorkflowGraph: sdapi.WorkflowGraph{
+			Edges: []sdapi.WorkflowEdge{{Src: "~commit", Dest: "main"}, {Src: "~release", Dest: "main"}},
+		},
+	}
+
+	expected := []JobDiff{
+		{
+			Name:   "main",
+			Status: JobChanged,
+			Changes: []string{
+				"requires: +~release -~pr",
+				"image: node:10 -> node:12",
+				"steps: -test",
+				"steps: install: npm ci -> npm install",
+				"steps: +lint: npm run lint",
+				"environment: -BAR",
+				"environment: +BAZ=baz",
+				"environment: FOO: foo -> foo2",
+				"secrets: +GITHUB_TOKEN",
+				"annotations: +screwdriver.cd/cpu=HIGH",
+			},
+		},
+		{Name: "new", Status: JobAdded},
+		{Name: "old", Status: JobRemoved},
+	}
+
+	actual := Diff(base, head)
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	buf := new(bytes.Buffer)
+	PrintDiff(buf, Diff(base, base))
+	if buf.String() != "no differences in the effective configuration\n" {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
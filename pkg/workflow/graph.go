@@ -0,0 +1,214 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+// output formats of a workflow graph
+const (
+	FormatASCII   = "ascii"
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// Formats is the list of supported output formats
+var Formats = []string{FormatASCII, FormatDOT, FormatMermaid}
+
+// Graph is a workflow graph with lookup helpers
+type Graph struct {
+	nodes    []string
+	children map[string][]sdapi.WorkflowEdge
+	parents  map[string][]sdapi.WorkflowEdge
+}
+
+// New creates a Graph from a workflow graph of the validator response
+func New(wg sdapi.WorkflowGraph) *Graph {
+	g := &Graph{
+		children: make(map[string][]sdapi.WorkflowEdge),
+		parents:  make(map[string][]sdapi.WorkflowEdge),
+	}
+	known := make(map[string]bool)
+	add := func(name string) {
+		if !known[name] {
+			known[name] = true
+			g.nodes = append(g.nodes, name)
+		}
+	}
+
+	for _, n := range wg.Nodes {
+		add(n.Name)
+	}
+	for _, e := range wg.Edges {
+		add(e.Src)
+		add(e.Dest)
+		g.children[e.Src] = append(g.children[e.Src], e)
+		g.parents[e.Dest] = append(g.parents[e.Dest], e)
+	}
+	return g
+}
+
+// Nodes returns node names in the order of the workflow graph
+func (g *Graph) Nodes() []string {
+	return g.nodes
+}
+
+// Parents returns names of nodes that the node requires
+func (g *Graph) Parents(name string) []string {
+	var names []string
+	for _, e := range g.parents[name] {
+		names = append(names, e.Src)
+	}
+	return names
+}
+
+// Roots returns nodes that no other node triggers
+func (g *Graph) Roots() []string {
+	var roots []string
+	for _, n := range g.nodes {
+		if len(g.parents[n]) == 0 {
+			roots = append(roots, n)
+		}
+	}
+	return roots
+}
+
+// Reachable returns nodes that can be reached from the given nodes
+func (g *Graph) Reachable(from []string) map[string]bool {
+	reached := make(map[string]bool)
+	queue := append([]string{}, from...)
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if reached[n] {
+			continue
+		}
+		reached[n] = true
+		for _, e := range g.children[n] {
+			queue = append(queue, e.Dest)
+		}
+	}
+	return reached
+}
+
+// Render writes the graph to w in format
+func (g *Graph) Render(w io.Writer, format string) error {
+	switch format {
+	case FormatASCII:
+		g.renderASCII(w)
+	case FormatDOT:
+		g.renderDOT(w)
+	case FormatMermaid:
+		g.renderMermaid(w)
+	default:
+		return fmt.Errorf("unknown graph format %q, should be one of %s", format, strings.Join(Formats, ", "))
+	}
+	return nil
+}
+
+func (g *Graph) renderASCII(w io.Writer) {
+	expanded := make(map[string]bool)
+
+	var walk func(e sdapi.WorkflowEdge, prefix string, last bool, path map[string]bool)
+	walk = func(e sdapi.WorkflowEdge, prefix string, last bool, path map[string]bool) {
+		branch, indent := "├── ", "│   "
+		if last {
+			branch, indent = "└── ", "    "
+		}
+		label := e.Dest
+		if e.Join {
+			label += " [join]"
+		}
+		children := g.children[e.Dest]
+		switch {
+		case path[e.Dest]:
+			fmt.Fprintf(w, "%s%s%s (cycle)\n", prefix, branch, label)
+			return
+		case expanded[e.Dest] && len(children) > 0:
+			fmt.Fprintf(w, "%s%s%s (see above)\n", prefix, branch, label)
+			return
+		}
+		fmt.Fprintf(w, "%s%s%s\n", prefix, branch, label)
+		expanded[e.Dest] = true
+
+		path[e.Dest] = true
+		for i, c := range children {
+			walk(c, prefix+indent, i == len(children)-1, path)
+		}
+		delete(path, e.Dest)
+	}
+
+	printRoot := func(n string) {
+		fmt.Fprintln(w, n)
+		expanded[n] = true
+		path := map[string]bool{n: true}
+		children := g.children[n]
+		for i, c := range children {
+			walk(c, "", i == len(children)-1, path)
+		}
+	}
+
+	for _, n := range g.Roots() {
+		printRoot(n)
+	}
+	// nodes only in cycles have no roots
+	for _, n := range g.nodes {
+		if !expanded[n] {
+			printRoot(n)
+		}
+	}
+}
+
+func (g *Graph) renderDOT(w io.Writer) {
+	fmt.Fprintln(w, "digraph workflow {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	for _, n := range g.nodes {
+		if strings.HasPrefix(n, "~") {
+			fmt.Fprintf(w, "  %q [shape=plaintext];\n", n)
+			continue
+		}
+		fmt.Fprintf(w, "  %q [shape=box];\n", n)
+	}
+	for _, n := range g.nodes {
+		for _, e := range g.children[n] {
+			if e.Join {
+				fmt.Fprintf(w, "  %q -> %q [label=\"join\"];\n", e.Src, e.Dest)
+				continue
+			}
+			fmt.Fprintf(w, "  %q -> %q;\n", e.Src, e.Dest)
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func (g *Graph) renderMermaid(w io.Writer) {
+	// mermaid does not allow characters like "~" in node IDs
+	ids := make(map[string]string)
+	for i, n := range g.nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+	}
+	label := func(n string) string {
+		return strings.ReplaceAll(n, `"`, "#quot;")
+	}
+
+	fmt.Fprintln(w, "graph LR")
+	for _, n := range g.nodes {
+		if strings.HasPrefix(n, "~") {
+			fmt.Fprintf(w, "  %s([\"%s\"])\n", ids[n], label(n))
+			continue
+		}
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", ids[n], label(n))
+	}
+	for _, n := range g.nodes {
+		for _, e := range g.children[n] {
+			if e.Join {
+				fmt.Fprintf(w, "  %s -->|join| %s\n", ids[e.Src], ids[e.Dest])
+				continue
+			}
+			fmt.Fprintf(w, "  %s --> %s\n", ids[e.Src], ids[e.Dest])
+		}
+	}
+}
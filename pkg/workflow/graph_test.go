@@ -0,0 +1,113 @@
+package workflow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tk3fftk/sdctl/pkg/sdapi"
+)
+
+var mockWorkflowGraph = sdapi.WorkflowGraph{
+	Nodes: []sdapi.WorkflowNode{
+		{Name: "~pr"},
+		{Name: "~commit"},
+		{Name: "main"},
+		{Name: "lint"},
+		{Name: "publish"},
+		{Name: "orphan"},
+	},
+	Edges: []sdapi.WorkflowEdge{
+		{Src: "~pr", Dest: "main"},
+		{Src: "~commit", Dest: "main"},
+		{Src: "~commit", Dest: "lint"},
+		{Src: "main", Dest: "publish", Join: true},
+		{Src: "lint", Dest: "publish", Join: true},
+	},
+}
+
+func TestGraph_Render(t *testing.T) {
+	cases := map[string]struct {
+		format    string
+		expected  string
+		expectErr bool
+	}{
+		"ascii": {
+			format: FormatASCII,
+			expected: `~pr
+└── main
+    └── publish [join]
+~commit
+├── main (see above)
+└── lint
+    └── publish [join]
+orphan
+`,
+		},
+		"dot": {
+			format: FormatDOT,
+			expected: `digraph workflow {
+  rankdir=LR;
+  "~pr" [shape=plaintext];
+  "~commit" [shape=plaintext];
+  "main" [shape=box];
+  "lint" [shape=box];
+  "publish" [shape=box];
+  "orphan" [shape=box];
+  "~pr" -> "main";
+  "~commit" -> "main";
+  "~commit" -> "lint";
+  "main" -> "publish" [label="join"];
+  "lint" -> "publish" [label="join"];
+}
+`,
+		},
+		"mermaid": {
+			format: FormatMermaid,
+			expected: `graph LR
+  n0(["~pr"])
+  n1(["~commit"])
+  n2["main"]
+  n3["lint"]
+  n4["publish"]
+  n5["orphan"]
+  n0 --> n2
+  n1 --> n2
+  n1 --> n3
+  n2 -->|join| n4
+  n3 -->|join| n4
+`,
+		},
+		"unknown format": {
+			format:    "svg",
+			expectErr: true,
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := New(mockWorkflowGraph).Render(buf, v.format)
+			if (err != nil) != v.expectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(v.expected, buf.String()); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGraph_Reachable(t *testing.T) {
+	g := New(mockWorkflowGraph)
+	actual := g.Reachable([]string{"~commit"})
+	expected := map[string]bool{"~commit": true, "main": true, "lint": true, "publish": true}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"main", "lint"}, g.Parents("publish")); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
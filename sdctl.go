@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"github.com/tk3fftk/sdctl/command"
 	"github.com/tk3fftk/sdctl/pkg/sdapi"
@@ -25,16 +27,43 @@ func main() {
 	}
 	config, err := sdctl_context.LoadConfig(configPATH, false)
 	if err != nil {
-		failureExit(err)
+		// config commands fix the broken config file
+		if !configCommand(os.Args[1:]) {
+			failureExit(err)
+		}
+		config = sdctl_context.SdctlConfig{SdctlContexts: map[string]sdctl_context.SdctlContext{}}
 	}
 	sdctx := config.SdctlContexts[config.CurrentContext]
-	api, err := sdapi.New(sdctx, nil)
+	api, err := sdapi.New(sdctx)
 	if err != nil {
 		failureExit(err)
 	}
 
+	// Ctrl-C cancels requests in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	cmd := command.NewCmd(config, api)
-	if err := cmd.Execute(); err != nil {
+	args, err := command.ExpandAlias(cmd, config, os.Args[1:])
+	if err != nil {
+		failureExit(err)
+	}
+	cmd.SetArgs(args)
+	if err := cmd.ExecuteContext(ctx); err != nil {
 		failureExit(err)
 	}
 }
+
+// configCommand returns whether args run one of config commands, which work without a valid config file
+func configCommand(args []string) bool {
+	cmd, _, err := command.NewCmd(sdctl_context.SdctlConfig{}, nil).Find(args)
+	if err != nil {
+		return false
+	}
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd.Name() == "config" && cmd.Parent() != nil && !cmd.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBrowserCommand(t *testing.T) {
+	url := "https://cd.screwdriver.cd/pipelines/1/builds/2"
+	cases := map[string]struct {
+		goos         string
+		expectedName string
+		expectedArgs []string
+	}{
+		"macOS":   {"darwin", "open", []string{url}},
+		"Windows": {"windows", "rundll32", []string{"url.dll,FileProtocolHandler", url}},
+		"Linux":   {"linux", "xdg-open", []string{url}},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			name, args := browserCommand(v.goos, url)
+			if name != v.expectedName {
+				t.Errorf("name should be %s, but actual is %s", v.expectedName, name)
+			}
+			if diff := cmp.Diff(v.expectedArgs, args); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ReadYamlAtRevision reads yaml file at the git revision
+func ReadYamlAtRevision(revision, yamlPath string) (string, error) {
+	dir, file := filepath.Split(yamlPath)
+	if dir == "" {
+		dir = "."
+	}
+
+	var stderr bytes.Buffer
+	// "./" makes git resolve the path from the working directory
+	cmd := exec.Command("git", "-C", dir, "show", revision+":./"+file)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s at %s: %v: %s", yamlPath, revision, err, strings.TrimSpace(stderr.String()))
+	}
+	return fmt.Sprintf("%q", string(out)), nil
+}
@@ -0,0 +1,64 @@
+package util_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/tk3fftk/sdctl/util"
+)
+
+func TestReadYamlAtRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "screwdriver.yaml")
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	if err := os.WriteFile(yamlPath, []byte("jobs: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	if err := os.WriteFile(yamlPath, []byte("jobs: {main: {}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		revision  string
+		expect    string
+		expectErr bool
+	}{
+		"file at HEAD": {
+			revision: "HEAD",
+			expect:   fmt.Sprintf("%q", "jobs: {}\n"),
+		},
+		"unknown revision": {
+			revision:  "unknown",
+			expectErr: true,
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			actual, err := util.ReadYamlAtRevision(v.revision, yamlPath)
+			if (err != nil) != v.expectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != v.expect {
+				t.Errorf("actual should be %s, but this is %s", v.expect, actual)
+			}
+		})
+	}
+}
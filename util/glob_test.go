@@ -0,0 +1,81 @@
+package util_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tk3fftk/sdctl/util"
+)
+
+func TestExpandGlobs(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{
+		"screwdriver.yaml",
+		"a/screwdriver.yaml",
+		"a/b/screwdriver.yaml",
+		"a/sd-template.yaml",
+		"c/screwdriver.yml",
+	} {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("jobs: {}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := map[string]struct {
+		patterns  []string
+		expect    []string
+		expectErr bool
+	}{
+		"plain path is kept as is": {
+			patterns: []string{"missing.yaml"},
+			expect:   []string{"missing.yaml"},
+		},
+		"single star": {
+			patterns: []string{filepath.Join(dir, "*", "screwdriver.yaml")},
+			expect:   []string{filepath.Join(dir, "a/screwdriver.yaml")},
+		},
+		"double star matches any depth": {
+			patterns: []string{filepath.Join(dir, "**", "screwdriver.yaml")},
+			expect: []string{
+				filepath.Join(dir, "a/b/screwdriver.yaml"),
+				filepath.Join(dir, "a/screwdriver.yaml"),
+				filepath.Join(dir, "screwdriver.yaml"),
+			},
+		},
+		"duplicated files are removed": {
+			patterns: []string{
+				filepath.Join(dir, "a/screwdriver.yaml"),
+				filepath.Join(dir, "**", "screwdriver.yaml"),
+			},
+			expect: []string{
+				filepath.Join(dir, "a/screwdriver.yaml"),
+				filepath.Join(dir, "a/b/screwdriver.yaml"),
+				filepath.Join(dir, "screwdriver.yaml"),
+			},
+		},
+		"no match": {
+			patterns:  []string{filepath.Join(dir, "**", "nothing.yaml")},
+			expectErr: true,
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			actual, err := util.ExpandGlobs(v.patterns)
+			if (err != nil) != v.expectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(v.expect, actual); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
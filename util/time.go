@@ -0,0 +1,23 @@
+package util
+
+import (
+	"fmt"
+	"time"
+)
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseTime parses RFC3339 or "2006-01-02 15:04" in local time
+func ParseTime(s string) (time.Time, error) {
+	for _, l := range timeLayouts {
+		if t, err := time.ParseInLocation(l, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse %s as time, use RFC3339 or \"2006-01-02 15:04\"", s)
+}
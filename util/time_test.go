@@ -0,0 +1,47 @@
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tk3fftk/sdctl/util"
+)
+
+func TestParseTime(t *testing.T) {
+	cases := map[string]struct {
+		input     string
+		expect    time.Time
+		expectErr bool
+	}{
+		"RFC3339": {
+			input:  "2021-07-18T14:31:00Z",
+			expect: time.Date(2021, 7, 18, 14, 31, 0, 0, time.UTC),
+		},
+		"local time": {
+			input:  "2021-07-18 14:31",
+			expect: time.Date(2021, 7, 18, 14, 31, 0, 0, time.Local),
+		},
+		"date": {
+			input:  "2021-07-18",
+			expect: time.Date(2021, 7, 18, 0, 0, 0, 0, time.Local),
+		},
+		"invalid": {
+			input:     "tomorrow",
+			expectErr: true,
+		},
+	}
+
+	for k, v := range cases {
+		k := k
+		v := v
+		t.Run(k, func(t *testing.T) {
+			actual, err := util.ParseTime(v.input)
+			if (err != nil) != v.expectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !actual.Equal(v.expect) {
+				t.Errorf("actual should be %v, but this is %v", v.expect, actual)
+			}
+		})
+	}
+}
@@ -3,13 +3,19 @@ package util
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"os/user"
 	"path/filepath"
 )
 
+// LoadYaml loads yaml file as is
+func LoadYaml(yamlPath string) ([]byte, error) {
+	return ioutil.ReadFile(yamlPath)
+}
+
 // ReadYaml reads yaml file
 func ReadYaml(yamlPath string) (yaml string, err error) {
-	yamlFile, err := ioutil.ReadFile(yamlPath)
+	yamlFile, err := LoadYaml(yamlPath)
 	if err != nil {
 		return
 	}
@@ -17,13 +23,23 @@ func ReadYaml(yamlPath string) (yaml string, err error) {
 	return
 }
 
-// ConfigPATH gets config file path for sdctl
+// ConfigPATH gets config file path for sdctl.
+// it is $XDG_CONFIG_HOME/sdctl/config.yaml (default to ~/.config/sdctl/config.yaml) if it exists, otherwise ~/.sdctl
 func ConfigPATH() (string, error) {
 	usr, err := user.Current()
 	if err != nil {
 		return "", err
 	}
-	configPATH := filepath.Join(usr.HomeDir, "/.sdctl")
+	return configPATH(usr.HomeDir, os.Getenv("XDG_CONFIG_HOME")), nil
+}
 
-	return configPATH, nil
+func configPATH(home, xdgConfigHome string) string {
+	if xdgConfigHome == "" {
+		xdgConfigHome = filepath.Join(home, ".config")
+	}
+	xdgPATH := filepath.Join(xdgConfigHome, "sdctl", "config.yaml")
+	if _, err := os.Stat(xdgPATH); err == nil {
+		return xdgPATH
+	}
+	return filepath.Join(home, ".sdctl")
 }
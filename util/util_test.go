@@ -3,6 +3,8 @@ package util_test
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/tk3fftk/sdctl/util"
@@ -39,3 +41,27 @@ func readFile(path string) string {
 	b, _ := ioutil.ReadFile(path)
 	return fmt.Sprintf("%q", string(b[:]))
 }
+
+func TestConfigPATH(t *testing.T) {
+	xdgConfigHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdgConfigHome)
+
+	legacy, err := util.ConfigPATH()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(legacy) != ".sdctl" {
+		t.Errorf("~/.sdctl should be used without the XDG config, but actual is %s", legacy)
+	}
+
+	xdgPATH := filepath.Join(xdgConfigHome, "sdctl", "config.yaml")
+	os.MkdirAll(filepath.Dir(xdgPATH), 0700)
+	ioutil.WriteFile(xdgPATH, []byte("version: 1\n"), 0600)
+	actual, err := util.ConfigPATH()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != xdgPATH {
+		t.Errorf("expected='%v', actual='%v'", xdgPATH, actual)
+	}
+}